@@ -0,0 +1,214 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ErrRootMismatch is returned when the root computed for a row or column does
+// not match its expected root.
+var ErrRootMismatch = errors.New("computed root does not match the expected root")
+
+// AxisHalf is either the original or the parity half of a row or column of an
+// extended data square. Either half is sufficient to recompute the whole
+// axis.
+type AxisHalf struct {
+	// Shares are the shares of the half, in order.
+	Shares [][]byte
+	// IsParity is true if Shares are the parity half of the axis, i.e. its
+	// second half.
+	IsParity bool
+}
+
+// Extend returns all shares of the axis, i.e. the original shares followed by
+// the parity shares, by encoding or decoding the half with codec. The shares
+// of the half are reused in the returned slice.
+func (h AxisHalf) Extend(codec Codec) ([][]byte, error) {
+	if !h.IsParity {
+		parity, err := codec.Encode(h.Shares)
+		if err != nil {
+			return nil, err
+		}
+		return append(append(make([][]byte, 0, 2*len(h.Shares)), h.Shares...), parity...), nil
+	}
+
+	shares := make([][]byte, 2*len(h.Shares))
+	copy(shares[len(h.Shares):], h.Shares)
+	return codec.Decode(shares)
+}
+
+// Root extends the half with codec and returns the root of tree after all
+// shares of the axis have been pushed to it. tree must be empty.
+func (h AxisHalf) Root(codec Codec, tree Tree) ([]byte, error) {
+	shares, err := h.Extend(codec)
+	if err != nil {
+		return nil, err
+	}
+	return axisRoot(tree, shares)
+}
+
+// axisRoot pushes shares to tree and returns its root. tree is released
+// afterwards.
+func axisRoot(tree Tree, shares [][]byte) ([]byte, error) {
+	defer releaseTree(tree)
+	for _, share := range shares {
+		if err := tree.Push(share); err != nil {
+			return nil, err
+		}
+	}
+	return consumeRoot(tree)
+}
+
+// Verify returns an error wrapping ErrRootMismatch if the root of the axis,
+// as computed by Root, does not match root.
+func (h AxisHalf) Verify(codec Codec, tree Tree, root []byte) error {
+	computed, err := h.Root(codec, tree)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(computed, root) {
+		return fmt.Errorf("%w: got %x, want %x", ErrRootMismatch, computed, root)
+	}
+	return nil
+}
+
+// RowHalf returns the original half of row rowIdx, or its parity half if
+// parity is true. The shares are copies.
+func (eds *ExtendedDataSquare) RowHalf(rowIdx uint, parity bool) AxisHalf {
+	return newAxisHalf(eds.Row(rowIdx), parity)
+}
+
+// ColHalf returns the original half of column colIdx, or its parity half if
+// parity is true. The shares are copies.
+func (eds *ExtendedDataSquare) ColHalf(colIdx uint, parity bool) AxisHalf {
+	return newAxisHalf(eds.Col(colIdx), parity)
+}
+
+// newAxisHalf returns the original or parity half of the shares of an axis.
+func newAxisHalf(shares [][]byte, parity bool) AxisHalf {
+	half := len(shares) / 2
+	if parity {
+		return AxisHalf{Shares: shares[half:], IsParity: true}
+	}
+	return AxisHalf{Shares: shares[:half]}
+}
+
+// NewEDSFromAxisHalves reconstructs an extended data square from one half of
+// each of its rows. halves[i] is a half of row i, so the width of the square
+// is len(halves). Every row is extended with codec and verified against
+// rowRoots[i]; if a row does not match its root, an ErrByzantineData
+// containing the shares of the given half is returned. The verified row roots
+// are cached by the returned square.
+func NewEDSFromAxisHalves(
+	halves []AxisHalf,
+	rowRoots [][]byte,
+	codec Codec,
+	treeCreatorFn TreeConstructorFn,
+) (*ExtendedDataSquare, error) {
+	width := uint(len(halves))
+	if err := validateEdsWidth(width); err != nil {
+		return nil, err
+	}
+	if len(rowRoots) != len(halves) {
+		return nil, fmt.Errorf("got %d row roots for %d rows", len(rowRoots), len(halves))
+	}
+	for i, half := range halves {
+		if uint(len(half.Shares)) != width/2 {
+			return nil, fmt.Errorf("half of row %d has %d shares, expected %d", i, len(half.Shares), width/2)
+		}
+	}
+
+	rows := make([][][]byte, width)
+	errs, _ := errgroup.WithContext(context.Background())
+	for i := range halves {
+		i := i
+		errs.Go(func() error {
+			shares, err := halves[i].Extend(codec)
+			if err != nil {
+				return err
+			}
+			info := SquareInfo{Width: width, ShareSize: uint(getShareSize(shares))}
+			root, err := axisRoot(newSquareTree(treeCreatorFn, Row, uint(i), info), shares)
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(root, rowRoots[i]) {
+				byzShares := make([][]byte, width)
+				if halves[i].IsParity {
+					copy(byzShares[width/2:], halves[i].Shares)
+				} else {
+					copy(byzShares, halves[i].Shares)
+				}
+				return &ErrByzantineData{Row, uint(i), byzShares, nil}
+			}
+			rows[i] = shares
+			return nil
+		})
+	}
+	if err := errs.Wait(); err != nil {
+		return nil, err
+	}
+
+	flattened := make([][]byte, 0, width*width)
+	for _, row := range rows {
+		flattened = append(flattened, row...)
+	}
+	eds, err := ImportExtendedDataSquareNoCopy(flattened, codec, treeCreatorFn)
+	if err != nil {
+		return nil, err
+	}
+	eds.rowRoots = deepCopy(rowRoots)
+	return eds, nil
+}
+
+// RepairRow returns all shares of row rowIdx of an extended data square from
+// shares, the row with nil for its missing shares, without constructing the
+// square. The missing shares are decoded with codec and the row is verified
+// against root with a tree created by treeCreatorFn. The tree is created for
+// rowIdx because trees like the NMT wrapper depend on the position of the
+// shares in the square. shares is not modified. Returns an error wrapping
+// ErrTooFewShares if fewer than half of the shares are present, or
+// ErrRootMismatch if the repaired row does not match root.
+func RepairRow(shares [][]byte, rowIdx uint, root []byte, codec Codec, treeCreatorFn TreeConstructorFn) ([][]byte, error) {
+	return repairAxis(shares, Row, rowIdx, root, codec, treeCreatorFn)
+}
+
+// RepairCol is like RepairRow, but for column colIdx.
+func RepairCol(shares [][]byte, colIdx uint, root []byte, codec Codec, treeCreatorFn TreeConstructorFn) ([][]byte, error) {
+	return repairAxis(shares, Col, colIdx, root, codec, treeCreatorFn)
+}
+
+func repairAxis(shares [][]byte, axis Axis, idx uint, root []byte, codec Codec, treeCreatorFn TreeConstructorFn) ([][]byte, error) {
+	width := uint(len(shares))
+	if width == 0 {
+		return nil, fmt.Errorf("%w: %s %d has no shares", ErrUnsupportedWidth, axis, idx)
+	}
+	if err := validateEdsWidth(width); err != nil {
+		return nil, err
+	}
+	present := uint(0)
+	for _, share := range shares {
+		if share != nil {
+			present++
+		}
+	}
+	if present < width/2 {
+		return nil, fmt.Errorf("%w: %s %d has %d of %d shares", ErrTooFewShares, axis, idx, present, width)
+	}
+
+	repaired := append(make([][]byte, 0, width), shares...)
+	if present < width {
+		var err error
+		if repaired, err = codec.Decode(repaired); err != nil {
+			return nil, err
+		}
+	}
+	if err := VerifyAxisAgainstRoot(repaired, root, treeCreatorFn, axis, idx); err != nil {
+		return nil, err
+	}
+	return repaired, nil
+}
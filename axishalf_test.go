@@ -0,0 +1,141 @@
+package rsmt2d
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAxisHalf(t *testing.T) {
+	codec := NewLeoRSCodec()
+	eds, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), codec, NewDefaultTree)
+	require.NoError(t, err)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+
+	for i := uint(0); i < eds.Width(); i++ {
+		for _, parity := range []bool{false, true} {
+			row := eds.RowHalf(i, parity)
+			assert.Equal(t, parity, row.IsParity)
+			assert.Len(t, row.Shares, int(eds.Width()/2))
+
+			shares, err := row.Extend(codec)
+			require.NoError(t, err)
+			assert.Equal(t, eds.Row(i), shares)
+			assert.NoError(t, row.Verify(codec, NewDefaultTree(Row, i), rowRoots[i]))
+			assert.ErrorIs(t, row.Verify(codec, NewDefaultTree(Row, i), colRoots[i]), ErrRootMismatch)
+
+			col := eds.ColHalf(i, parity)
+			root, err := col.Root(codec, NewDefaultTree(Col, i))
+			require.NoError(t, err)
+			assert.Equal(t, colRoots[i], root)
+		}
+	}
+
+	t.Run("halves are copies", func(t *testing.T) {
+		half := eds.RowHalf(0, false)
+		half.Shares[0][0]++
+		assert.NotEqual(t, half.Shares[0], eds.GetCell(0, 0))
+	})
+}
+
+func TestNewEDSFromAxisHalves(t *testing.T) {
+	codec := NewLeoRSCodec()
+	want, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), codec, NewDefaultTree)
+	require.NoError(t, err)
+	rowRoots, err := want.RowRoots()
+	require.NoError(t, err)
+
+	halves := make([]AxisHalf, want.Width())
+	for i := range halves {
+		// mix original and parity halves
+		halves[i] = want.RowHalf(uint(i), i%2 == 1)
+	}
+
+	eds, err := NewEDSFromAxisHalves(halves, rowRoots, codec, NewDefaultTree)
+	require.NoError(t, err)
+	assert.True(t, want.Equals(eds))
+	colRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+	wantColRoots, err := want.ColRoots()
+	require.NoError(t, err)
+	assert.Equal(t, wantColRoots, colRoots)
+
+	t.Run("byzantine row", func(t *testing.T) {
+		bad := append([]AxisHalf(nil), halves...)
+		bad[3] = AxisHalf{Shares: deepCopy(halves[3].Shares), IsParity: true}
+		bad[3].Shares[0][0]++
+		_, err := NewEDSFromAxisHalves(bad, rowRoots, codec, NewDefaultTree)
+		var byzErr *ErrByzantineData
+		require.ErrorAs(t, err, &byzErr)
+		assert.Equal(t, Row, byzErr.Axis)
+		assert.Equal(t, uint(3), byzErr.Index)
+		assert.Nil(t, byzErr.Shares[0])
+		assert.Equal(t, bad[3].Shares[0], byzErr.Shares[want.Width()/2])
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := NewEDSFromAxisHalves(halves, rowRoots[1:], codec, NewDefaultTree)
+		assert.Error(t, err)
+		_, err = NewEDSFromAxisHalves(halves[1:], rowRoots[1:], codec, NewDefaultTree)
+		assert.ErrorIs(t, err, ErrUnsupportedWidth)
+		short := append([]AxisHalf(nil), halves...)
+		short[0] = AxisHalf{Shares: halves[0].Shares[1:]}
+		_, err = NewEDSFromAxisHalves(short, rowRoots, codec, NewDefaultTree)
+		assert.Error(t, err)
+	})
+}
+
+func TestRepairAxis(t *testing.T) {
+	codec := NewLeoRSCodec()
+	eds, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), codec, NewDefaultTree)
+	require.NoError(t, err)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+
+	// withMissing returns shares with the first n shares missing
+	withMissing := func(shares [][]byte, n int) [][]byte {
+		for i := 0; i < n; i++ {
+			shares[i] = nil
+		}
+		return shares
+	}
+
+	for i := uint(0); i < eds.Width(); i++ {
+		shares := withMissing(eds.Row(i), int(eds.Width()/2))
+		got, err := RepairRow(shares, i, rowRoots[i], codec, NewDefaultTree)
+		require.NoError(t, err)
+		assert.Equal(t, eds.Row(i), got)
+		assert.Nil(t, shares[0], "the input must not be modified")
+
+		got, err = RepairCol(withMissing(eds.Col(i), 1), i, colRoots[i], codec, NewDefaultTree)
+		require.NoError(t, err)
+		assert.Equal(t, eds.Col(i), got)
+	}
+
+	t.Run("complete axis", func(t *testing.T) {
+		got, err := RepairRow(eds.Row(0), 0, rowRoots[0], codec, NewDefaultTree)
+		require.NoError(t, err)
+		assert.Equal(t, eds.Row(0), got)
+	})
+
+	t.Run("too few shares", func(t *testing.T) {
+		_, err := RepairRow(withMissing(eds.Row(0), int(eds.Width()/2)+1), 0, rowRoots[0], codec, NewDefaultTree)
+		assert.ErrorIs(t, err, ErrTooFewShares)
+	})
+
+	t.Run("root mismatch", func(t *testing.T) {
+		_, err := RepairCol(withMissing(eds.Col(0), 1), 0, colRoots[1], codec, NewDefaultTree)
+		assert.ErrorIs(t, err, ErrRootMismatch)
+	})
+
+	t.Run("odd width", func(t *testing.T) {
+		_, err := RepairRow(eds.Row(0)[1:], 0, rowRoots[0], codec, NewDefaultTree)
+		assert.ErrorIs(t, err, ErrUnsupportedWidth)
+	})
+}
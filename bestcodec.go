@@ -0,0 +1,19 @@
+package rsmt2d
+
+// BestCodec returns a registered codec that supports squares with the given
+// ODS width and share size, or nil if neither Leopard nor RSGF8 support them.
+//
+// The choice only depends on its arguments, so that every node picks the same
+// codec for the same square: the codec determines the parity shares and thus
+// the roots of the square. Leopard is preferred, which itself uses 8-bit
+// Leopard for ODS widths up to 128 and 16-bit Leopard above. RSGF8 is used for
+// shares that are not a multiple of 64 bytes, which Leopard does not support.
+func BestCodec(odsWidth, shareSize int) Codec {
+	for _, name := range []string{Leopard, RSGF8} {
+		codec, ok := GetCodec(name)
+		if ok && ValidateWidth(codec, uint(odsWidth)) == nil && codec.ValidateChunkSize(shareSize) == nil {
+			return codec
+		}
+	}
+	return nil
+}
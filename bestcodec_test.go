@@ -0,0 +1,49 @@
+package rsmt2d
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBestCodec(t *testing.T) {
+	tests := []struct {
+		name      string
+		odsWidth  int
+		shareSize int
+		want      string
+	}{
+		{"small square", 4, 512, Leopard},
+		{"large square", 256, 512, Leopard},
+		{"share size not supported by Leopard", 64, 100, RSGF8},
+		{"unsupported square", 256, 100, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codec := BestCodec(tt.odsWidth, tt.shareSize)
+			if tt.want == "" {
+				assert.Nil(t, codec)
+				return
+			}
+			assert.Equal(t, tt.want, codec.Name())
+		})
+	}
+}
+
+// BenchmarkBestCodecCandidates compares the extension of small squares with
+// the codecs BestCodec chooses from.
+func BenchmarkBestCodecCandidates(b *testing.B) {
+	for _, codec := range []Codec{NewLeoRSCodec(), NewRSGF8Codec()} {
+		for _, odsWidth := range []int{4, 8, 16, 32, 64, 128} {
+			data := generateRandData(odsWidth*odsWidth, shareSize)
+			b.Run(fmt.Sprintf("%s/odsWidth=%d", codec.Name(), odsWidth), func(b *testing.B) {
+				for n := 0; n < b.N; n++ {
+					_, err := ComputeExtendedDataSquare(data, codec, NewDefaultTree)
+					require.NoError(b, err)
+				}
+			})
+		}
+	}
+}
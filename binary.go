@@ -0,0 +1,255 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = &ExtendedDataSquare{}
+	_ encoding.BinaryUnmarshaler = &ExtendedDataSquare{}
+)
+
+var (
+	// ErrCorruptInput is returned by UnmarshalBinary if the input is
+	// truncated, does not start with the expected magic bytes or does not
+	// match its checksum, and by UnmarshalCBOR if the input is malformed.
+	ErrCorruptInput = errors.New("corrupt input")
+	// ErrUnsupportedFormatVersion is returned by UnmarshalBinary if the input
+	// was encoded with an unknown version of the binary format.
+	ErrUnsupportedFormatVersion = errors.New("unsupported binary format version")
+)
+
+// binaryMagic identifies the binary format of an extended data square.
+var binaryMagic = [4]byte{'R', 'S', 'M', 'T'}
+
+// binaryFormatVersion is the version of the binary format written by
+// MarshalBinary. Version 2 added the compression byte and version 3 the
+// roots, the previous versions are still supported by UnmarshalBinary.
+const binaryFormatVersion = 3
+
+// Compression is a compression algorithm applied to the shares of a
+// serialized square, see WithCompression.
+type Compression uint8
+
+const (
+	// NoCompression stores the shares as is.
+	NoCompression Compression = iota
+	// Deflate compresses the shares with DEFLATE (RFC 1951).
+	Deflate
+)
+
+// MarshalOption configures the serialization of a square by
+// MarshalBinaryWith and MarshalJSONWith.
+type MarshalOption func(*marshalConfig)
+
+type marshalConfig struct {
+	compression Compression
+	// treeName and treeParams are the tree recorded by WithTree.
+	treeName   string
+	treeParams any
+}
+
+func newMarshalConfig(opts ...MarshalOption) marshalConfig {
+	var cfg marshalConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithCompression compresses the shares of the serialized square with c.
+// Squares of mostly zero padding shrink dramatically. The compression is
+// recorded in the output and transparently reverted by UnmarshalBinary and
+// UnmarshalJSON.
+func WithCompression(c Compression) MarshalOption {
+	return func(cfg *marshalConfig) {
+		cfg.compression = c
+	}
+}
+
+// crcTable is the CRC-32 table used for the checksum of the binary format.
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// MarshalBinary fulfills the encoding.BinaryMarshaler interface. The binary
+// format consists of the magic bytes "RSMT", the format version and the
+// Compression as single bytes, the roots, the square in the format of WriteTo
+// compressed with the Compression, and a big-endian CRC-32C checksum of
+// everything before it. The roots are the number of rows as an unsigned
+// varint, followed by the roots of all rows and then all columns, each
+// prefixed with its length as an unsigned varint. The number of rows is zero
+// if the roots have not all been computed yet, in which case they are
+// omitted. MarshalBinary does not compress the square. MarshalBinary and
+// UnmarshalBinary are also used to encode squares with encoding/gob.
+func (eds *ExtendedDataSquare) MarshalBinary() ([]byte, error) {
+	return eds.MarshalBinaryWith()
+}
+
+// MarshalBinaryWith is like MarshalBinary, but configured by opts.
+func (eds *ExtendedDataSquare) MarshalBinaryWith(opts ...MarshalOption) ([]byte, error) {
+	cfg := newMarshalConfig(opts...)
+	var buf bytes.Buffer
+	buf.Write(binaryMagic[:])
+	buf.WriteByte(binaryFormatVersion)
+	buf.WriteByte(byte(cfg.compression))
+	buf.Write(eds.appendCachedRoots(nil))
+	if err := eds.writeCompressed(&buf, cfg.compression); err != nil {
+		return nil, err
+	}
+	return binary.BigEndian.AppendUint32(buf.Bytes(), crc32.Checksum(buf.Bytes(), crcTable)), nil
+}
+
+// UnmarshalBinary fulfills the encoding.BinaryUnmarshaler interface by
+// unmarshalling a square encoded by MarshalBinary. Returns an error wrapping
+// ErrCorruptInput if b is truncated or otherwise corrupted, or ErrRootMismatch
+// if the roots do not match the shares. Like ReadFrom, the codec must be
+// registered and the square uses the default tree.
+func (eds *ExtendedDataSquare) UnmarshalBinary(b []byte) error {
+	if len(b) < len(binaryMagic)+1+crc32.Size {
+		return fmt.Errorf("%w: got %d bytes", ErrCorruptInput, len(b))
+	}
+	if !bytes.Equal(b[:len(binaryMagic)], binaryMagic[:]) {
+		return fmt.Errorf("%w: unknown magic bytes %x", ErrCorruptInput, b[:len(binaryMagic)])
+	}
+	body, checksum := b[:len(b)-crc32.Size], binary.BigEndian.Uint32(b[len(b)-crc32.Size:])
+	if crc32.Checksum(body, crcTable) != checksum {
+		return fmt.Errorf("%w: checksum mismatch", ErrCorruptInput)
+	}
+	payload := body[len(binaryMagic)+1:]
+	version := body[len(binaryMagic)]
+	if version < 1 || version > binaryFormatVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedFormatVersion, version)
+	}
+	compression := NoCompression
+	if version >= 2 {
+		if len(payload) == 0 {
+			return fmt.Errorf("%w: missing compression", ErrCorruptInput)
+		}
+		compression, payload = Compression(payload[0]), payload[1:]
+	}
+
+	r := bytes.NewReader(payload)
+	var rowRoots, colRoots [][]byte
+	if version >= 3 {
+		var err error
+		if rowRoots, colRoots, err = readRoots(r); err != nil {
+			return err
+		}
+	}
+	var decoded ExtendedDataSquare
+	if err := decoded.readCompressed(r, compression, defaultDecoder()); err != nil {
+		var corrupt flate.CorruptInputError
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &corrupt) {
+			return fmt.Errorf("%w: %v", ErrCorruptInput, err)
+		}
+		return err
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("%w: %d trailing bytes", ErrCorruptInput, r.Len())
+	}
+	if rowRoots != nil {
+		if err := decoded.restoreRoots(rowRoots, colRoots, false); err != nil {
+			return err
+		}
+	}
+	*eds = decoded
+	return nil
+}
+
+// appendCachedRoots appends the roots of the square to b in the binary
+// format, or zero if they have not all been computed.
+func (eds *ExtendedDataSquare) appendCachedRoots(b []byte) []byte {
+	rowRoots, colRoots, ok := eds.cachedRoots()
+	if !ok {
+		return binary.AppendUvarint(b, 0)
+	}
+	b = binary.AppendUvarint(b, uint64(len(rowRoots)))
+	for _, root := range append(rowRoots[:len(rowRoots):len(rowRoots)], colRoots...) {
+		b = binary.AppendUvarint(b, uint64(len(root)))
+		b = append(b, root...)
+	}
+	return b
+}
+
+// readRoots reads the roots written by appendCachedRoots from r. Returns nil
+// roots if there are none.
+func readRoots(r *bytes.Reader) (rowRoots [][]byte, colRoots [][]byte, err error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrCorruptInput, err)
+	}
+	if n == 0 {
+		return nil, nil, nil
+	}
+	if n > uint64(r.Len()) {
+		return nil, nil, fmt.Errorf("%w: %d roots exceed the remaining %d bytes", ErrCorruptInput, n, r.Len())
+	}
+	roots := make([][]byte, 2*n)
+	for i := range roots {
+		size, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrCorruptInput, err)
+		}
+		if size > uint64(r.Len()) {
+			return nil, nil, fmt.Errorf("%w: root of %d bytes exceeds the remaining %d bytes", ErrCorruptInput, size, r.Len())
+		}
+		roots[i] = make([]byte, size)
+		if _, err := io.ReadFull(r, roots[i]); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrCorruptInput, err)
+		}
+	}
+	return roots[:n:n], roots[n:], nil
+}
+
+// writeCompressed writes the square to w in the format of WriteTo,
+// compressed with c.
+func (eds *ExtendedDataSquare) writeCompressed(w io.Writer, c Compression) error {
+	switch c {
+	case NoCompression:
+		_, err := eds.WriteTo(w)
+		return err
+	case Deflate:
+		fw, err := flate.NewWriter(w, flate.DefaultCompression)
+		if err != nil {
+			return err
+		}
+		if _, err := eds.WriteTo(fw); err != nil {
+			return err
+		}
+		return fw.Close()
+	default:
+		return fmt.Errorf("unsupported compression %d", c)
+	}
+}
+
+// readCompressed reads a square written by writeCompressed with c from r into
+// eds, resolving its codec and tree with dec. All of r is consumed if it holds
+// a valid compressed square.
+func (eds *ExtendedDataSquare) readCompressed(r io.Reader, c Compression, dec squareDecoder) error {
+	switch c {
+	case NoCompression:
+		_, err := eds.readFrom(r, dec)
+		return err
+	case Deflate:
+		fr := flate.NewReader(r)
+		if _, err := eds.readFrom(fr, dec); err != nil {
+			return err
+		}
+		// consume the end of the compressed stream
+		if n, err := fr.Read(make([]byte, 1)); n != 0 || !errors.Is(err, io.EOF) {
+			if err == nil || errors.Is(err, io.EOF) {
+				return fmt.Errorf("%w: trailing bytes after the square", ErrCorruptInput)
+			}
+			return err
+		}
+		return fr.Close()
+	default:
+		return fmt.Errorf("unsupported compression %d", c)
+	}
+}
@@ -0,0 +1,192 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	want, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	want.clearCell(3, 4)
+
+	b, err := want.MarshalBinary()
+	require.NoError(t, err)
+
+	var eds ExtendedDataSquare
+	require.NoError(t, eds.UnmarshalBinary(b))
+	assert.True(t, want.Equals(&eds))
+	assert.Equal(t, want.MissingCells(), eds.MissingCells())
+
+	// withChecksum replaces the checksum of a modified input
+	withChecksum := func(b []byte) []byte {
+		body := b[:len(b)-crc32.Size]
+		return binary.BigEndian.AppendUint32(append([]byte(nil), body...), crc32.Checksum(body, crcTable))
+	}
+
+	corrupted := map[string][]byte{
+		"empty":           nil,
+		"truncated":       b[:len(b)-1],
+		"flipped bit":     append(append([]byte(nil), b[:20]...), append([]byte{b[20] ^ 1}, b[21:]...)...),
+		"wrong magic":     withChecksum(append([]byte("RSMX"), b[4:]...)),
+		"truncated body":  withChecksum(append(append([]byte(nil), b[:len(b)-crc32.Size-1]...), 0, 0, 0, 0)),
+		"trailing bytes":  withChecksum(append(append([]byte(nil), b[:len(b)-crc32.Size]...), 0, 0, 0, 0, 0)),
+		"truncated share": withChecksum(append(append([]byte(nil), b[:len(b)-crc32.Size-shareSize/2]...), 0, 0, 0, 0)),
+	}
+	for name, input := range corrupted {
+		t.Run(name, func(t *testing.T) {
+			var eds ExtendedDataSquare
+			assert.ErrorIs(t, eds.UnmarshalBinary(input), ErrCorruptInput)
+		})
+	}
+
+	t.Run("unsupported version", func(t *testing.T) {
+		input := append([]byte(nil), b...)
+		input[len(binaryMagic)] = binaryFormatVersion + 1
+		var eds ExtendedDataSquare
+		assert.ErrorIs(t, eds.UnmarshalBinary(withChecksum(input)), ErrUnsupportedFormatVersion)
+	})
+}
+
+func TestCompression(t *testing.T) {
+	// a square of mostly zero padding
+	ods := make([][]byte, 16)
+	for i := range ods {
+		ods[i] = make([]byte, shareSize)
+	}
+	ods[0] = bytes.Repeat([]byte{1}, shareSize)
+	want, err := ComputeExtendedDataSquare(ods, NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+
+	t.Run("binary", func(t *testing.T) {
+		plain, err := want.MarshalBinary()
+		require.NoError(t, err)
+		compressed, err := want.MarshalBinaryWith(WithCompression(Deflate))
+		require.NoError(t, err)
+		assert.Less(t, 4*len(compressed), len(plain))
+
+		var eds ExtendedDataSquare
+		require.NoError(t, eds.UnmarshalBinary(compressed))
+		assert.True(t, want.Equals(&eds))
+
+		_, err = want.MarshalBinaryWith(WithCompression(Deflate + 1))
+		assert.Error(t, err)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		plain, err := json.Marshal(want)
+		require.NoError(t, err)
+		compressed, err := want.MarshalJSONWith(WithCompression(Deflate))
+		require.NoError(t, err)
+		assert.Less(t, 4*len(compressed), len(plain))
+
+		var eds ExtendedDataSquare
+		require.NoError(t, json.Unmarshal(compressed, &eds))
+		assert.True(t, want.Equals(&eds))
+
+		DefaultMaxImportWidth = want.Width() - 1
+		defer func() { DefaultMaxImportWidth = 0 }()
+		assert.ErrorIs(t, json.Unmarshal(compressed, &eds), ErrSquareTooLarge)
+	})
+
+	t.Run("version 1", func(t *testing.T) {
+		var buf bytes.Buffer
+		buf.Write(binaryMagic[:])
+		buf.WriteByte(1)
+		_, err := want.WriteTo(&buf)
+		require.NoError(t, err)
+		v1 := binary.BigEndian.AppendUint32(buf.Bytes(), crc32.Checksum(buf.Bytes(), crcTable))
+
+		var eds ExtendedDataSquare
+		require.NoError(t, eds.UnmarshalBinary(v1))
+		assert.True(t, want.Equals(&eds))
+	})
+}
+
+func TestSerializedRoots(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+
+	t.Run("roots are omitted until computed", func(t *testing.T) {
+		b, err := json.Marshal(eds)
+		require.NoError(t, err)
+		assert.NotContains(t, string(b), "row_roots")
+
+		b, err = eds.MarshalBinary()
+		require.NoError(t, err)
+		assert.Equal(t, byte(0), b[len(binaryMagic)+2])
+	})
+
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+	// tamper flips a bit of the first row root in b
+	tamper := func(b []byte) []byte {
+		b = append([]byte(nil), b...)
+		b[bytes.Index(b, rowRoots[0])] ^= 1
+		return b
+	}
+
+	t.Run("json", func(t *testing.T) {
+		b, err := json.Marshal(eds)
+		require.NoError(t, err)
+		var decoded ExtendedDataSquare
+		require.NoError(t, json.Unmarshal(b, &decoded))
+		cachedRowRoots, cachedColRoots, ok := decoded.cachedRoots()
+		require.True(t, ok)
+		assert.Equal(t, rowRoots, cachedRowRoots)
+		assert.Equal(t, colRoots, cachedColRoots)
+
+		// the roots are base64 encoded in JSON
+		var aux map[string]any
+		require.NoError(t, json.Unmarshal(b, &aux))
+		aux["row_roots"].([]any)[0] = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, len(rowRoots[0])))
+		tampered, err := json.Marshal(aux)
+		require.NoError(t, err)
+		assert.ErrorIs(t, json.Unmarshal(tampered, &decoded), ErrRootMismatch)
+
+		trusted, err := UnmarshalJSONWith(tampered, NewLeoRSCodec(), NewDefaultTree, WithTrustedRoots())
+		require.NoError(t, err)
+		trustedRowRoots, err := trusted.RowRoots()
+		require.NoError(t, err)
+		assert.Equal(t, bytes.Repeat([]byte{1}, len(rowRoots[0])), trustedRowRoots[0])
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		b, err := eds.MarshalBinary()
+		require.NoError(t, err)
+		var decoded ExtendedDataSquare
+		require.NoError(t, decoded.UnmarshalBinary(b))
+		cachedRowRoots, cachedColRoots, ok := decoded.cachedRoots()
+		require.True(t, ok)
+		assert.Equal(t, rowRoots, cachedRowRoots)
+		assert.Equal(t, colRoots, cachedColRoots)
+
+		tampered := tamper(b)
+		body := tampered[:len(tampered)-crc32.Size]
+		tampered = binary.BigEndian.AppendUint32(body, crc32.Checksum(body, crcTable))
+		assert.ErrorIs(t, decoded.UnmarshalBinary(tampered), ErrRootMismatch)
+	})
+
+	t.Run("version 2", func(t *testing.T) {
+		var buf bytes.Buffer
+		buf.Write(binaryMagic[:])
+		buf.WriteByte(2)
+		buf.WriteByte(byte(NoCompression))
+		_, err := eds.WriteTo(&buf)
+		require.NoError(t, err)
+		v2 := binary.BigEndian.AppendUint32(buf.Bytes(), crc32.Checksum(buf.Bytes(), crcTable))
+
+		var decoded ExtendedDataSquare
+		require.NoError(t, decoded.UnmarshalBinary(v2))
+		assert.True(t, eds.Equals(&decoded))
+	})
+}
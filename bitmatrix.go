@@ -0,0 +1,70 @@
+package rsmt2d
+
+import "math/bits"
+
+// bitMatrix is a square matrix of bits stored in row-major order.
+type bitMatrix struct {
+	width uint
+	words []uint64
+}
+
+func newBitMatrix(width uint) bitMatrix {
+	return bitMatrix{
+		width: width,
+		words: make([]uint64, (width*width+63)/64),
+	}
+}
+
+// set sets the bit at (rowIdx, colIdx).
+func (bm bitMatrix) set(rowIdx uint, colIdx uint) {
+	i := rowIdx*bm.width + colIdx
+	bm.words[i/64] |= 1 << (i % 64)
+}
+
+// get returns true if the bit at (rowIdx, colIdx) is set.
+func (bm bitMatrix) get(rowIdx uint, colIdx uint) bool {
+	i := rowIdx*bm.width + colIdx
+	return bm.words[i/64]&(1<<(i%64)) != 0
+}
+
+// rowCount returns the number of bits set in row rowIdx.
+func (bm bitMatrix) rowCount(rowIdx uint) uint {
+	count := uint(0)
+	start, end := rowIdx*bm.width, (rowIdx+1)*bm.width
+	for i := start; i < end; {
+		word := bm.words[i/64] >> (i % 64)
+		n := 64 - i%64
+		if end-i < n {
+			n = end - i
+			word &= 1<<n - 1
+		}
+		count += uint(bits.OnesCount64(word))
+		i += n
+	}
+	return count
+}
+
+// colCount returns the number of bits set in column colIdx.
+func (bm bitMatrix) colCount(colIdx uint) uint {
+	count := uint(0)
+	for rowIdx := uint(0); rowIdx < bm.width; rowIdx++ {
+		if bm.get(rowIdx, colIdx) {
+			count++
+		}
+	}
+	return count
+}
+
+// setRow sets every bit in row rowIdx.
+func (bm bitMatrix) setRow(rowIdx uint) {
+	for colIdx := uint(0); colIdx < bm.width; colIdx++ {
+		bm.set(rowIdx, colIdx)
+	}
+}
+
+// setCol sets every bit in column colIdx.
+func (bm bitMatrix) setCol(colIdx uint) {
+	for rowIdx := uint(0); rowIdx < bm.width; rowIdx++ {
+		bm.set(rowIdx, colIdx)
+	}
+}
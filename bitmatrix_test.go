@@ -0,0 +1,31 @@
+package rsmt2d
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitMatrix(t *testing.T) {
+	for _, width := range []uint{4, 8, 10, 70} {
+		bm := newBitMatrix(width)
+		bm.set(0, 0)
+		bm.set(1, width-1)
+		bm.set(width-1, width-1)
+
+		assert.True(t, bm.get(0, 0))
+		assert.True(t, bm.get(1, width-1))
+		assert.False(t, bm.get(0, 1))
+
+		assert.Equal(t, uint(1), bm.rowCount(0))
+		assert.Equal(t, uint(1), bm.rowCount(1))
+		assert.Equal(t, uint(1), bm.colCount(0))
+		assert.Equal(t, uint(2), bm.colCount(width-1))
+
+		bm.setRow(0)
+		assert.Equal(t, width, bm.rowCount(0))
+		bm.setCol(1)
+		assert.Equal(t, width, bm.colCount(1))
+		assert.Equal(t, uint(2), bm.rowCount(1))
+	}
+}
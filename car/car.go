@@ -0,0 +1,215 @@
+// Package car exports extended data squares to and imports them from CARv1
+// (content addressable archive) files, so that squares can be exchanged with
+// IPFS-based tooling.
+//
+// Every present share is stored as a raw block addressed by a CIDv1 with the
+// sha2-256 hash of the share. The single root of the archive is a raw block
+// indexing the square: the width of the square as a big-endian uint32,
+// followed by the CID of every cell in row-major order, with missing shares
+// marked by an all-zero CID. Identical shares, such as padding, are stored
+// once.
+package car
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/celestiaorg/rsmt2d"
+)
+
+// ErrInvalidCAR is returned by Import if the archive is malformed or was not
+// written by Export.
+var ErrInvalidCAR = errors.New("invalid CAR file")
+
+const (
+	// rawCodec is the multicodec of raw binary blocks.
+	rawCodec = 0x55
+	// sha256Code is the multihash code of sha2-256.
+	sha256Code = 0x12
+	// cidSize is the size of a CIDv1 of a raw block with a sha2-256 hash.
+	cidSize = 4 + sha256.Size
+	// rootOffset is the offset of the root CID in the header.
+	rootOffset = 13
+	// maxSectionSize bounds the size of a single section read by Import.
+	maxSectionSize = 1 << 30
+)
+
+// cid is a CIDv1 of a raw block with a sha2-256 hash.
+type cid [cidSize]byte
+
+// newCID returns the CID of the raw block data.
+func newCID(data []byte) cid {
+	var c cid
+	copy(c[:], []byte{1, rawCodec, sha256Code, sha256.Size})
+	digest := sha256.Sum256(data)
+	copy(c[4:], digest[:])
+	return c
+}
+
+// header returns the DAG-CBOR encoded CARv1 header {"roots": [root],
+// "version": 1}.
+func header(root cid) []byte {
+	h := []byte{0xa2, 0x65}
+	h = append(h, "roots"...)
+	// an array of a single tag 42 byte string holding the CID prefixed with
+	// the identity multibase
+	h = append(h, 0x81, 0xd8, 0x2a, 0x58, cidSize+1, 0x00)
+	h = append(h, root[:]...)
+	h = append(h, 0x67)
+	h = append(h, "version"...)
+	return append(h, 0x01)
+}
+
+// Export writes eds to w as a CARv1 archive.
+func Export(w io.Writer, eds *rsmt2d.ExtendedDataSquare) error {
+	width := eds.Width()
+	if width > math.MaxUint32 {
+		return fmt.Errorf("width %d is too large to be exported", width)
+	}
+
+	view := eds.ReadOnly()
+	index := make([]byte, 4, 4+width*width*cidSize)
+	binary.BigEndian.PutUint32(index, uint32(width))
+	for rowIdx := uint(0); rowIdx < width; rowIdx++ {
+		for _, share := range view.Row(rowIdx) {
+			var c cid
+			if share != nil {
+				c = newCID(share)
+			}
+			index = append(index, c[:]...)
+		}
+	}
+	root := newCID(index)
+
+	bw := bufio.NewWriter(w)
+	if err := writeSection(bw, header(root)); err != nil {
+		return err
+	}
+	if err := writeSection(bw, root[:], index); err != nil {
+		return err
+	}
+	written := make(map[cid]bool)
+	for rowIdx := uint(0); rowIdx < width; rowIdx++ {
+		for _, share := range view.Row(rowIdx) {
+			if share == nil {
+				continue
+			}
+			c := newCID(share)
+			if written[c] {
+				continue
+			}
+			written[c] = true
+			if err := writeSection(bw, c[:], share); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// writeSection writes the concatenation of parts prefixed with its length as
+// an unsigned varint.
+func writeSection(w io.Writer, parts ...[]byte) error {
+	size := 0
+	for _, part := range parts {
+		size += len(part)
+	}
+	if _, err := w.Write(binary.AppendUvarint(nil, uint64(size))); err != nil {
+		return err
+	}
+	for _, part := range parts {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Import reads a CARv1 archive written by Export from r and imports the
+// square it contains with rsmt2d.ImportExtendedDataSquare. The hash of every
+// block is verified against its CID. Returns an error wrapping ErrInvalidCAR
+// if the archive is malformed or a block does not match its CID.
+func Import(
+	r io.Reader,
+	codec rsmt2d.Codec,
+	treeCreatorFn rsmt2d.TreeConstructorFn,
+	opts ...rsmt2d.ImportOption,
+) (*rsmt2d.ExtendedDataSquare, error) {
+	br := bufio.NewReader(r)
+	h, err := readSection(br)
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: missing header", ErrInvalidCAR)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(h) != len(header(cid{})) || !bytes.Equal(h, header(cid(h[rootOffset:rootOffset+cidSize]))) {
+		return nil, fmt.Errorf("%w: unsupported header", ErrInvalidCAR)
+	}
+	root := cid(h[rootOffset : rootOffset+cidSize])
+
+	blocks := make(map[cid][]byte)
+	for {
+		section, err := readSection(br)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(section) < cidSize {
+			return nil, fmt.Errorf("%w: section of %d bytes is too short", ErrInvalidCAR, len(section))
+		}
+		c, data := cid(section[:cidSize]), section[cidSize:]
+		if newCID(data) != c {
+			return nil, fmt.Errorf("%w: block %x does not match its CID", ErrInvalidCAR, c)
+		}
+		blocks[c] = data
+	}
+
+	index, ok := blocks[root]
+	if !ok || len(index) < 4 {
+		return nil, fmt.Errorf("%w: missing root block", ErrInvalidCAR)
+	}
+	width := uint64(binary.BigEndian.Uint32(index))
+	if uint64(len(index)-4) != width*width*cidSize {
+		return nil, fmt.Errorf("%w: root block of %d bytes does not index a square of width %d", ErrInvalidCAR, len(index), width)
+	}
+	data := make([][]byte, width*width)
+	for i := range data {
+		c := cid(index[4+i*cidSize : 4+(i+1)*cidSize])
+		if c == (cid{}) {
+			continue
+		}
+		if data[i], ok = blocks[c]; !ok {
+			return nil, fmt.Errorf("%w: missing block %x", ErrInvalidCAR, c)
+		}
+	}
+	return rsmt2d.ImportExtendedDataSquareNoCopy(data, codec, treeCreatorFn, opts...)
+}
+
+// readSection reads a section prefixed with its length as an unsigned varint.
+// Returns io.EOF if there are no more sections.
+func readSection(r *bufio.Reader) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCAR, err)
+	}
+	if size > maxSectionSize {
+		return nil, fmt.Errorf("%w: section of %d bytes exceeds the max of %d bytes", ErrInvalidCAR, size, maxSectionSize)
+	}
+	section := make([]byte, size)
+	if _, err := io.ReadFull(r, section); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCAR, err)
+	}
+	return section, nil
+}
@@ -0,0 +1,75 @@
+package car
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/celestiaorg/rsmt2d"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImport(t *testing.T) {
+	const shareSize = 64
+	ods := make([][]byte, 16)
+	for i := range ods {
+		ods[i] = bytes.Repeat([]byte{byte(i % 8)}, shareSize)
+	}
+	want, err := rsmt2d.ComputeExtendedDataSquare(ods, rsmt2d.NewLeoRSCodec(), rsmt2d.NewDefaultTree)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, Export(&buf, want))
+
+	got, err := Import(bytes.NewReader(buf.Bytes()), rsmt2d.NewLeoRSCodec(), rsmt2d.NewDefaultTree)
+	require.NoError(t, err)
+	assert.True(t, want.Equals(got))
+
+	t.Run("duplicate shares are stored once", func(t *testing.T) {
+		sections := 0
+		br := bufio.NewReader(bytes.NewReader(buf.Bytes()))
+		for {
+			if _, err := readSection(br); err != nil {
+				require.ErrorIs(t, err, io.EOF)
+				break
+			}
+			sections++
+		}
+		// the header, the root block and the unique shares
+		unique := make(map[string]bool)
+		for _, share := range want.Flattened() {
+			unique[string(share)] = true
+		}
+		assert.Less(t, len(unique), int(want.Width()*want.Width()))
+		assert.Equal(t, 2+len(unique), sections)
+	})
+
+	t.Run("missing shares", func(t *testing.T) {
+		flattened := want.Flattened()
+		flattened[5] = nil
+		incomplete, err := rsmt2d.ImportExtendedDataSquare(flattened, rsmt2d.NewLeoRSCodec(), rsmt2d.NewDefaultTree)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, Export(&buf, incomplete))
+		got, err := Import(&buf, rsmt2d.NewLeoRSCodec(), rsmt2d.NewDefaultTree)
+		require.NoError(t, err)
+		assert.Equal(t, incomplete.MissingCells(), got.MissingCells())
+	})
+
+	t.Run("corrupted block", func(t *testing.T) {
+		corrupted := append([]byte(nil), buf.Bytes()...)
+		corrupted[len(corrupted)-1]++
+		_, err := Import(bytes.NewReader(corrupted), rsmt2d.NewLeoRSCodec(), rsmt2d.NewDefaultTree)
+		assert.ErrorIs(t, err, ErrInvalidCAR)
+	})
+
+	t.Run("truncated archive", func(t *testing.T) {
+		for _, size := range []int{0, 10, buf.Len() - 1} {
+			_, err := Import(bytes.NewReader(buf.Bytes()[:size]), rsmt2d.NewLeoRSCodec(), rsmt2d.NewDefaultTree)
+			assert.Error(t, err, "size %d", size)
+		}
+	})
+}
@@ -0,0 +1,277 @@
+package rsmt2d
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// CBOR major types (RFC 8949, section 3.1).
+const (
+	cborUint   = 0
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborSimple = 7
+)
+
+// cborNull is the encoding of the CBOR null value.
+const cborNull = 0xf6
+
+// MarshalCBOR serializes the square as a CBOR (RFC 8949) map with the same
+// layout as MarshalJSON: "data_square" holds the shares in row-major order as
+// byte strings, with missing shares encoded as null, "share_size" holds the
+// share size, and "codec" holds a map of the "name" and "version" of the
+// codec. The shares are written as is, without the base64 encoding of JSON.
+func (eds *ExtendedDataSquare) MarshalCBOR() ([]byte, error) {
+	name := eds.codec.Name()
+	shares := eds.dataSquare.Flattened()
+	size := len(name) + 64
+	for _, share := range shares {
+		size += len(share) + 9
+	}
+
+	b := make([]byte, 0, size)
+	b = appendCBORHead(b, cborMap, 3)
+	b = appendCBORText(b, "data_square")
+	b = appendCBORHead(b, cborArray, uint64(len(shares)))
+	for _, share := range shares {
+		if share == nil {
+			b = append(b, cborNull)
+			continue
+		}
+		b = appendCBORHead(b, cborBytes, uint64(len(share)))
+		b = append(b, share...)
+	}
+	b = appendCBORText(b, "share_size")
+	b = appendCBORHead(b, cborUint, uint64(eds.shareSize))
+	b = appendCBORText(b, "codec")
+	b = appendCBORHead(b, cborMap, 2)
+	b = appendCBORText(b, "name")
+	b = appendCBORText(b, name)
+	b = appendCBORText(b, "version")
+	return appendCBORHead(b, cborUint, uint64(codecVersion(eds.codec))), nil
+}
+
+// UnmarshalCBOR unmarshals a square serialized by MarshalCBOR. Only the
+// definite-length encodings written by MarshalCBOR are supported. Like
+// UnmarshalBinary, the codec must be registered and the square uses the
+// default tree. Returns an error wrapping ErrCorruptInput if b is malformed.
+func (eds *ExtendedDataSquare) UnmarshalCBOR(b []byte) error {
+	r := cborReader{b: b}
+	data, shareSize, id, err := r.readSquare()
+	if err != nil {
+		return err
+	}
+	if len(r.b) != 0 {
+		return fmt.Errorf("%w: %d trailing bytes", ErrCorruptInput, len(r.b))
+	}
+	codec, err := id.codec()
+	if err != nil {
+		return err
+	}
+	if size := getShareSize(data); size != 0 {
+		shareSize = size
+	}
+	decoded, err := importExtendedDataSquare(data, shareSize, codec, NewDefaultTree)
+	if err != nil {
+		return err
+	}
+	*eds = *decoded
+	return nil
+}
+
+// appendCBORHead appends the head of a data item of major type major with
+// argument arg to b, using the shortest encoding.
+func appendCBORHead(b []byte, major byte, arg uint64) []byte {
+	major <<= 5
+	switch {
+	case arg < 24:
+		return append(b, major|byte(arg))
+	case arg <= math.MaxUint8:
+		return append(b, major|24, byte(arg))
+	case arg <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(arg))
+	case arg <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(arg))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, major|27), arg)
+	}
+}
+
+func appendCBORText(b []byte, s string) []byte {
+	return append(appendCBORHead(b, cborText, uint64(len(s))), s...)
+}
+
+// cborReader decodes the subset of CBOR written by MarshalCBOR from b.
+type cborReader struct {
+	b []byte
+}
+
+// readHead reads the head of the next data item and returns its major type
+// and argument.
+func (r *cborReader) readHead() (major byte, arg uint64, err error) {
+	if len(r.b) == 0 {
+		return 0, 0, fmt.Errorf("%w: unexpected end of input", ErrCorruptInput)
+	}
+	major, info := r.b[0]>>5, r.b[0]&0x1f
+	r.b = r.b[1:]
+	if info < 24 {
+		return major, uint64(info), nil
+	}
+	if info > 27 {
+		return 0, 0, fmt.Errorf("%w: unsupported additional information %d", ErrCorruptInput, info)
+	}
+	n := 1 << (info - 24)
+	if len(r.b) < n {
+		return 0, 0, fmt.Errorf("%w: unexpected end of input", ErrCorruptInput)
+	}
+	for _, c := range r.b[:n] {
+		arg = arg<<8 | uint64(c)
+	}
+	r.b = r.b[n:]
+	return major, arg, nil
+}
+
+// readLength reads the head of a data item of major type want and returns
+// its length. The length is checked against the remaining input, so that it
+// can be used to allocate.
+func (r *cborReader) readLength(want byte) (int, error) {
+	major, arg, err := r.readHead()
+	if err != nil {
+		return 0, err
+	}
+	if major != want {
+		return 0, fmt.Errorf("%w: got major type %d, expected %d", ErrCorruptInput, major, want)
+	}
+	if arg > uint64(len(r.b)) {
+		return 0, fmt.Errorf("%w: length %d exceeds the remaining %d bytes", ErrCorruptInput, arg, len(r.b))
+	}
+	return int(arg), nil
+}
+
+// readBytes reads a byte or text string of major type major without copying
+// it.
+func (r *cborReader) readBytes(major byte) ([]byte, error) {
+	n, err := r.readLength(major)
+	if err != nil {
+		return nil, err
+	}
+	s := r.b[:n:n]
+	r.b = r.b[n:]
+	return s, nil
+}
+
+func (r *cborReader) readText() (string, error) {
+	s, err := r.readBytes(cborText)
+	return string(s), err
+}
+
+// readSquare reads the map written by MarshalCBOR and returns the shares,
+// the share size and the codec of the square. The shares are copied into a
+// single allocation.
+func (r *cborReader) readSquare() ([][]byte, int, codecID, error) {
+	var (
+		shares    [][]byte
+		shareSize int
+		id        codecID
+	)
+	n, err := r.readLength(cborMap)
+	if err != nil {
+		return nil, 0, id, err
+	}
+	for i := 0; i < n; i++ {
+		key, err := r.readText()
+		if err != nil {
+			return nil, 0, id, err
+		}
+		switch key {
+		case "data_square":
+			if shares, err = r.readShares(); err != nil {
+				return nil, 0, id, err
+			}
+		case "share_size":
+			if shareSize, err = r.readInt(); err != nil {
+				return nil, 0, id, err
+			}
+		case "codec":
+			if id, err = r.readCodecID(); err != nil {
+				return nil, 0, id, err
+			}
+		default:
+			return nil, 0, id, fmt.Errorf("%w: unknown key %q", ErrCorruptInput, key)
+		}
+	}
+
+	size := 0
+	for _, share := range shares {
+		size += len(share)
+	}
+	buf := make([]byte, size)
+	for i, share := range shares {
+		if share != nil {
+			shares[i] = buf[:len(share):len(share)]
+			buf = buf[copy(buf, share):]
+		}
+	}
+	return shares, shareSize, id, nil
+}
+
+// readShares reads the array of shares, which alias the input.
+func (r *cborReader) readShares() ([][]byte, error) {
+	n, err := r.readLength(cborArray)
+	if err != nil {
+		return nil, err
+	}
+	shares := make([][]byte, n)
+	for i := range shares {
+		if len(r.b) != 0 && r.b[0] == cborNull {
+			r.b = r.b[1:]
+			continue
+		}
+		if shares[i], err = r.readBytes(cborBytes); err != nil {
+			return nil, err
+		}
+	}
+	return shares, nil
+}
+
+// readInt reads an unsigned integer that fits into an int32.
+func (r *cborReader) readInt() (int, error) {
+	major, arg, err := r.readHead()
+	if err != nil {
+		return 0, err
+	}
+	if major != cborUint || arg > math.MaxInt32 {
+		return 0, fmt.Errorf("%w: invalid integer", ErrCorruptInput)
+	}
+	return int(arg), nil
+}
+
+func (r *cborReader) readCodecID() (codecID, error) {
+	var id codecID
+	n, err := r.readLength(cborMap)
+	if err != nil {
+		return id, err
+	}
+	for i := 0; i < n; i++ {
+		key, err := r.readText()
+		if err != nil {
+			return id, err
+		}
+		switch key {
+		case "name":
+			if id.Name, err = r.readText(); err != nil {
+				return id, err
+			}
+		case "version":
+			if id.Version, err = r.readInt(); err != nil {
+				return id, err
+			}
+		default:
+			return id, fmt.Errorf("%w: unknown codec key %q", ErrCorruptInput, key)
+		}
+	}
+	return id, nil
+}
@@ -0,0 +1,59 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalCBOR(t *testing.T) {
+	want, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	want.clearCell(3, 4)
+
+	b, err := want.MarshalCBOR()
+	require.NoError(t, err)
+	// a map of three entries, starting with the 11 byte key "data_square"
+	assert.Equal(t, []byte{0xa3, 0x6b}, b[:2])
+	// the shares are not base64 encoded as in JSON
+	assert.Less(t, len(b), 64*shareSize+64*3+100)
+
+	var eds ExtendedDataSquare
+	require.NoError(t, eds.UnmarshalCBOR(b))
+	assert.True(t, want.Equals(&eds))
+	assert.Equal(t, want.MissingCells(), eds.MissingCells())
+
+	// the shares do not alias the input
+	b[bytes.Index(b, want.GetCell(0, 0))] ^= 1
+	assert.True(t, want.Equals(&eds))
+
+	corrupted := map[string][]byte{
+		"empty":          nil,
+		"truncated":      b[:len(b)-1],
+		"trailing bytes": append(append([]byte(nil), b...), 0),
+		"not a map":      {0x80},
+		"unknown key":    {0xa1, 0x63, 'f', 'o', 'o', 0x00},
+		"huge array":     {0xa1, 0x6b, 'd', 'a', 't', 'a', '_', 's', 'q', 'u', 'a', 'r', 'e', 0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		"indefinite map": {0xbf, 0xff},
+	}
+	for name, b := range corrupted {
+		t.Run(name, func(t *testing.T) {
+			var eds ExtendedDataSquare
+			assert.ErrorIs(t, eds.UnmarshalCBOR(b), ErrCorruptInput)
+		})
+	}
+}
+
+func TestGob(t *testing.T) {
+	want, err := ComputeExtendedDataSquare(generateRandData(4, shareSize), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(want))
+	var eds ExtendedDataSquare
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&eds))
+	assert.True(t, want.Equals(&eds))
+}
@@ -4,7 +4,12 @@ import (
 	cryptorand "crypto/rand"
 	"fmt"
 	"math/rand"
+	"runtime"
+	"sync/atomic"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -34,6 +39,380 @@ func BenchmarkEncoding(b *testing.B) {
 	}
 }
 
+func TestEncodeBatch(t *testing.T) {
+	for _, codec := range []BatchEncoder{NewLeoRSCodec(), NewRSGF8Codec()} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			axes := [][][]byte{generateRandData(4, 64), generateRandData(4, 64), generateRandData(4, 64)}
+			parity, err := codec.EncodeBatch(axes)
+			require.NoError(t, err)
+			require.Len(t, parity, len(axes))
+			for i, axis := range axes {
+				want, err := codec.Encode(axis)
+				require.NoError(t, err)
+				assert.Equal(t, want, parity[i])
+			}
+
+			_, err = codec.EncodeBatch([][][]byte{axes[0], axes[1][:2]})
+			assert.ErrorIs(t, err, ErrShareCountMismatch)
+			assert.ErrorIs(t, codec.EncodeBatchInto(axes, parity[:2]), ErrShareCountMismatch)
+			parity, err = codec.EncodeBatch(nil)
+			require.NoError(t, err)
+			assert.Empty(t, parity)
+		})
+	}
+}
+
+// batchCountingCodec counts the calls to EncodeInto and EncodeBatchInto of
+// the wrapped LeoRSCodec.
+type batchCountingCodec struct {
+	*LeoRSCodec
+	encodeInto, encodeBatchInto atomic.Int32
+}
+
+func (c *batchCountingCodec) EncodeInto(data [][]byte, parityOut [][]byte) error {
+	c.encodeInto.Add(1)
+	return c.LeoRSCodec.EncodeInto(data, parityOut)
+}
+
+func (c *batchCountingCodec) EncodeBatchInto(axes [][][]byte, parityOut [][][]byte) error {
+	c.encodeBatchInto.Add(1)
+	return c.LeoRSCodec.EncodeBatchInto(axes, parityOut)
+}
+
+func TestExtensionEncodesInBatches(t *testing.T) {
+	data := generateRandData(16*16, shareSize)
+	want, err := ComputeExtendedDataSquare(data, NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+
+	codec := &batchCountingCodec{LeoRSCodec: NewLeoRSCodec()}
+	got, err := ComputeExtendedDataSquare(data, codec, NewDefaultTree)
+	require.NoError(t, err)
+	assert.Equal(t, want.Flattened(), got.Flattened())
+	assert.Zero(t, codec.encodeInto.Load())
+	// one batch per CPU for Q1 and Q2 and for Q3, at most
+	assert.LessOrEqual(t, int(codec.encodeBatchInto.Load()), 2*runtime.GOMAXPROCS(0))
+
+	// codecs without EncodeBatchInto still encode each axis, so wrappers
+	// observe all of them
+	metrics := &countingMetrics{}
+	got, err = ComputeExtendedDataSquare(data, InstrumentedCodec(NewLeoRSCodec(), metrics), NewDefaultTree)
+	require.NoError(t, err)
+	assert.Equal(t, want.Flattened(), got.Flattened())
+	assert.Equal(t, 3*16, metrics.encodes)
+}
+
+func TestLeoRSCodecEncodeInto(t *testing.T) {
+	codec := NewLeoRSCodec()
+	data := generateRandData(8, shareSize)
+
+	want, err := codec.Encode(data)
+	require.NoError(t, err)
+
+	parity := allocShares(len(data), shareSize)
+	require.NoError(t, codec.EncodeInto(data, parity))
+	assert.Equal(t, want, parity)
+
+	t.Run("returns an error if parityOut has the wrong length", func(t *testing.T) {
+		err := codec.EncodeInto(data, allocShares(len(data)-1, shareSize))
+		assert.Error(t, err)
+	})
+	t.Run("returns an error if parityOut has the wrong share size", func(t *testing.T) {
+		err := codec.EncodeInto(data, allocShares(len(data), shareSize/2))
+		assert.Error(t, err)
+	})
+}
+
+func TestRSGF8Codec(t *testing.T) {
+	codec := NewRSGF8Codec()
+
+	t.Run("supports small shares", func(t *testing.T) {
+		assert.NoError(t, codec.ValidateChunkSize(1))
+		assert.NoError(t, codec.ValidateChunkSize(33))
+		assert.ErrorIs(t, codec.ValidateChunkSize(0), ErrInvalidShareSize)
+	})
+
+	t.Run("is registered", func(t *testing.T) {
+		codec, ok := GetCodec(RSGF8)
+		require.True(t, ok)
+		assert.Equal(t, RSGF8, codec.Name())
+	})
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, codec := range []Codec{NewLeoRSCodec(), NewRSGF8Codec()} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			data := generateRandData(4, shareSize)
+			parity, err := codec.Encode(data)
+			require.NoError(t, err)
+
+			t.Run("too few shares", func(t *testing.T) {
+				shares := append(deepCopy(data), deepCopy(parity)...)
+				for i := 0; i < 5; i++ {
+					shares[i] = nil
+				}
+				_, err := codec.Decode(shares)
+				assert.ErrorIs(t, err, ErrTooFewShares)
+			})
+
+			t.Run("shard size mismatch", func(t *testing.T) {
+				shares := append(deepCopy(data), deepCopy(parity)...)
+				shares[0] = nil
+				shares[1] = shares[1][:shareSize/2]
+				_, err := codec.Decode(shares)
+				assert.ErrorIs(t, err, ErrShardSizeMismatch)
+			})
+		})
+	}
+}
+
+func TestLeoRSCodecWarmUp(t *testing.T) {
+	codec := NewLeoRSCodec()
+	require.NoError(t, codec.WarmUp(2, 4, 8))
+	for _, dataLen := range []int{2, 4, 8} {
+		assert.True(t, codec.encCache.contains(dataLen))
+	}
+	assert.False(t, codec.encCache.contains(16))
+
+	assert.Error(t, codec.WarmUp(0))
+}
+
+func TestLeoRSCodecWithGoroutines(t *testing.T) {
+	shareSize := 8192
+	data := generateRandData(8, shareSize)
+
+	want, err := NewLeoRSCodec().Encode(data)
+	require.NoError(t, err)
+
+	codecs := map[string]*LeoRSCodec{
+		"auto goroutines": NewLeoRSCodec(WithAutoGoroutines(shareSize)),
+		"max goroutines":  NewLeoRSCodec(WithMaxGoroutines(4)),
+	}
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			parity, err := codec.Encode(data)
+			require.NoError(t, err)
+			assert.Equal(t, want, parity)
+
+			shares := append(make([][]byte, len(data)), parity...)
+			decoded, err := codec.Decode(shares)
+			require.NoError(t, err)
+			assert.Equal(t, data, decoded[:len(data)])
+		})
+	}
+}
+
+func TestZeroValueCodecs(t *testing.T) {
+	data := generateRandData(4, 64)
+	for _, codec := range []Codec{&LeoRSCodec{}, &RSGF8Codec{}} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			parity, err := codec.Encode(data)
+			require.NoError(t, err)
+
+			shares := append(make([][]byte, len(data)), parity...)
+			decoded, err := codec.Decode(shares)
+			require.NoError(t, err)
+			assert.Equal(t, data, decoded[:len(data)])
+		})
+	}
+
+	var codec LeoRSCodec
+	require.NoError(t, codec.WarmUp(8))
+	codec.ClearCache()
+	assert.Equal(t, 0, codec.CacheStats().Entries)
+}
+
+func TestLeoRSCodecWithCache(t *testing.T) {
+	codec := NewLeoRSCodecWithCache(2)
+
+	_, err := codec.Encode(generateRandData(2, shareSize))
+	require.NoError(t, err)
+	_, err = codec.Encode(generateRandData(4, shareSize))
+	require.NoError(t, err)
+	_, err = codec.Encode(generateRandData(2, shareSize))
+	require.NoError(t, err)
+	assert.Equal(t, EncoderCacheStats{Hits: 1, Misses: 2, Entries: 2}, codec.CacheStats())
+
+	// evicts the least recently used encoder for data length 4
+	_, err = codec.Encode(generateRandData(8, shareSize))
+	require.NoError(t, err)
+	assert.Equal(t, EncoderCacheStats{Hits: 1, Misses: 3, Evictions: 1, Entries: 2}, codec.CacheStats())
+	assert.True(t, codec.encCache.contains(2))
+	assert.False(t, codec.encCache.contains(4))
+	assert.True(t, codec.encCache.contains(8))
+
+	codec.ClearCache()
+	assert.Equal(t, 0, codec.CacheStats().Entries)
+	assert.False(t, codec.encCache.contains(2))
+}
+
+func TestDuplicationCodec(t *testing.T) {
+	codec := NewDuplicationCodec()
+	data := generateRandData(4, 1)
+
+	parity, err := codec.Encode(data)
+	require.NoError(t, err)
+	assert.Equal(t, data, parity)
+
+	t.Run("decodes if a share or its copy is present", func(t *testing.T) {
+		shares := append(deepCopy(data), deepCopy(parity)...)
+		shares[0], shares[5], shares[6] = nil, nil, nil
+		decoded, err := codec.Decode(shares)
+		require.NoError(t, err)
+		assert.Equal(t, append(deepCopy(data), deepCopy(data)...), decoded)
+	})
+
+	t.Run("returns ErrTooFewShares if a share and its copy are missing", func(t *testing.T) {
+		shares := append(deepCopy(data), deepCopy(parity)...)
+		shares[1], shares[5] = nil, nil
+		_, err := codec.Decode(shares)
+		assert.ErrorIs(t, err, ErrTooFewShares)
+	})
+}
+
+func TestFountainCodec(t *testing.T) {
+	codec := NewFountainCodec()
+	data := generateRandData(8, 3)
+
+	parity, err := codec.Encode(data)
+	require.NoError(t, err)
+
+	t.Run("decodes from the repair shares only", func(t *testing.T) {
+		shares := append(make([][]byte, len(data)), deepCopy(parity)...)
+		decoded, err := codec.Decode(shares)
+		require.NoError(t, err)
+		assert.Equal(t, append(deepCopy(data), parity...), decoded)
+	})
+
+	t.Run("returns ErrTooFewShares with less than half of the shares", func(t *testing.T) {
+		shares := append(deepCopy(data), deepCopy(parity)...)
+		for i := 0; i < 9; i++ {
+			shares[i] = nil
+		}
+		_, err := codec.Decode(shares)
+		assert.ErrorIs(t, err, ErrTooFewShares)
+	})
+
+	t.Run("is rateless", func(t *testing.T) {
+		// The repair shares of a shorter code are a prefix of the repair
+		// shares of a longer code over the same original shares.
+		ext := allocShares(2*len(data), 3)
+		for j := range ext {
+			for i, share := range data {
+				gfMulAdd(ext[j], share, fountainCoefficient(j, i))
+			}
+		}
+		assert.Equal(t, parity, ext[:len(data)])
+	})
+}
+
+func TestCodecsRepair(t *testing.T) {
+	// odsWidth is the width of the original data square of the tests
+	const odsWidth = 4
+
+	tests := []struct {
+		codec     Codec
+		shareSize int
+		// keep returns true if the share at (rowIdx, colIdx) is kept before
+		// repairing
+		keep func(rowIdx, colIdx int) bool
+	}{
+		{NewLeoRSCodec(), 64, func(rowIdx, colIdx int) bool { return (rowIdx+colIdx)%2 == 0 }},
+		{NewRSGF8Codec(), 3, func(rowIdx, colIdx int) bool { return (rowIdx+colIdx)%2 == 0 }},
+		// a share can only be recovered from its copies, so keep the original
+		// data square
+		{NewDuplicationCodec(), 1, func(rowIdx, colIdx int) bool { return rowIdx < odsWidth && colIdx < odsWidth }},
+		// keep only the parity shares of the parity shares
+		{NewFountainCodec(), 3, func(rowIdx, colIdx int) bool { return rowIdx >= odsWidth && colIdx >= odsWidth }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.codec.Name(), func(t *testing.T) {
+			eds, err := ComputeExtendedDataSquare(generateRandData(odsWidth*odsWidth, tt.shareSize), tt.codec, NewDefaultTree)
+			require.NoError(t, err)
+			rowRoots, err := eds.RowRoots()
+			require.NoError(t, err)
+			colRoots, err := eds.ColRoots()
+			require.NoError(t, err)
+
+			flattened := eds.Flattened()
+			for i := range flattened {
+				if !tt.keep(i/(2*odsWidth), i%(2*odsWidth)) {
+					flattened[i] = nil
+				}
+			}
+			imported, err := ImportExtendedDataSquare(flattened, tt.codec, NewDefaultTree)
+			require.NoError(t, err)
+			require.NoError(t, imported.Repair(rowRoots, colRoots))
+			assert.True(t, imported.Equals(eds))
+		})
+	}
+}
+
+func TestSupportedChunkSizes(t *testing.T) {
+	for _, codec := range []Codec{
+		NewLeoRSCodec(), NewRSGF8Codec(), NewDuplicationCodec(), NewFountainCodec(),
+		InstrumentedCodec(NewLeoRSCodec(), &countingMetrics{}),
+	} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			minSize, maxSize, multiple, ok := SupportedChunkSizes(codec)
+			require.True(t, ok)
+			assert.Equal(t, 0, maxSize)
+			assert.NoError(t, codec.ValidateChunkSize(minSize))
+			assert.NoError(t, codec.ValidateChunkSize(minSize+multiple))
+			assert.NoError(t, codec.ValidateChunkSize(minSize+1000*multiple))
+			if multiple > 1 {
+				assert.ErrorIs(t, codec.ValidateChunkSize(minSize+1), ErrInvalidShareSize)
+			}
+		})
+	}
+
+	_, _, _, ok := SupportedChunkSizes(newTestCodec())
+	assert.False(t, ok)
+}
+
+func TestMaxAxisShares(t *testing.T) {
+	tests := []struct {
+		codec Codec
+		want  int
+	}{
+		{NewLeoRSCodec(), 32768},
+		{NewRSGF8Codec(), 128},
+		{InstrumentedCodec(NewRSGF8Codec(), &countingMetrics{}), 128},
+		// derived from MaxChunks
+		{newTestCodec(), 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MaxAxisShares(tt.codec))
+		assert.Equal(t, tt.want*tt.want, tt.codec.MaxChunks())
+	}
+
+	err := ValidateWidth(NewRSGF8Codec(), 129)
+	assert.ErrorIs(t, err, ErrUnsupportedWidth)
+	assert.ErrorContains(t, err, "exceeds the max of 128 shares per axis")
+}
+
+func TestCodecRegistry(t *testing.T) {
+	assert.Equal(t, []string{Duplication, Fountain, Leopard, RSGF8}, Codecs())
+
+	codec, ok := GetCodec(Leopard)
+	require.True(t, ok)
+	assert.Equal(t, Leopard, codec.Name())
+
+	_, ok = GetCodec("testCodec")
+	assert.False(t, ok)
+
+	require.NoError(t, RegisterCodec("testCodec", newTestCodec()))
+	assert.ErrorIs(t, RegisterCodec("testCodec", newTestCodec()), ErrCodecAlreadyRegistered)
+	assert.Equal(t, []string{Duplication, Fountain, Leopard, RSGF8, "testCodec"}, Codecs())
+
+	DeregisterCodec("testCodec")
+	_, ok = GetCodec("testCodec")
+	assert.False(t, ok)
+	assert.Equal(t, []string{Duplication, Fountain, Leopard, RSGF8}, Codecs())
+
+	// deregistering an unknown codec is a no-op
+	DeregisterCodec("testCodec")
+}
+
 func generateRandData(count int, shareSize int) [][]byte {
 	out := make([][]byte, count)
 	for i := 0; i < count; i++ {
@@ -102,6 +481,11 @@ func (c *testCodec) Encode(share [][]byte) ([][]byte, error) {
 	return share, nil
 }
 
+func (c *testCodec) EncodeInto(share [][]byte, parityOut [][]byte) error {
+	copy(parityOut, share)
+	return nil
+}
+
 func (c *testCodec) Decode(share [][]byte) ([][]byte, error) {
 	return share, nil
 }
@@ -1,6 +1,12 @@
 package rsmt2d
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
 
 const (
 	// Leopard is a codec that was originally implemented in the C++ library
@@ -11,12 +17,53 @@ const (
 	Leopard = "Leopard"
 )
 
+var (
+	// ErrTooFewShares is returned by Decode when there are not enough shares
+	// present to reconstruct the missing shares.
+	ErrTooFewShares = errors.New("too few shares to decode")
+	// ErrShardSizeMismatch is returned by Decode when the present shares are
+	// not all of equal size.
+	ErrShardSizeMismatch = errors.New("shares are not all of equal size")
+	// ErrCodecInternal is returned by Decode when the codec fails for a reason
+	// other than the input shares.
+	ErrCodecInternal = errors.New("internal codec error")
+	// ErrUnsupportedWidth is returned when the width of a square is not
+	// supported by its codec.
+	ErrUnsupportedWidth = errors.New("unsupported square width")
+	// ErrIncompatibleCodecVersion is returned when unmarshalling a square that
+	// was encoded with a different version of its codec than the registered
+	// one.
+	ErrIncompatibleCodecVersion = errors.New("incompatible codec version")
+	// ErrInvalidShareSize is returned when the size of a share is not
+	// supported by the codec or does not match the share size of the square.
+	ErrInvalidShareSize = errors.New("invalid share size")
+	// ErrShareCountMismatch is returned when the number of parity shares does
+	// not match the number of original shares.
+	ErrShareCountMismatch = errors.New("parity and original share counts do not match")
+	// ErrCodecAlreadyRegistered is returned by RegisterCodec when a codec is
+	// already registered under the given name.
+	ErrCodecAlreadyRegistered = errors.New("codec already registered")
+	// ErrCodecNotRegistered is returned when unmarshalling a square that was
+	// encoded with a codec that is not registered.
+	ErrCodecNotRegistered = errors.New("codec not registered")
+)
+
+// defaultCodecVersion is the version of codecs that do not implement
+// VersionedCodec, and of squares serialized without a codec version.
+const defaultCodecVersion = 1
+
 type Codec interface {
 	// Encode encodes original data, automatically extracting share size.
 	// There must be no missing shares. Only returns parity shares.
 	Encode(data [][]byte) ([][]byte, error)
+	// EncodeInto encodes original data like Encode but writes the parity
+	// shares into parityOut instead of allocating them. parityOut must contain
+	// len(data) shares of the same size as the shares in data.
+	EncodeInto(data [][]byte, parityOut [][]byte) error
 	// Decode decodes sparse original + parity data, automatically extracting share size.
-	// Missing shares must be nil. Returns original + parity data.
+	// Missing shares must be nil. Returns original + parity data. Returned
+	// errors should wrap ErrTooFewShares, ErrShardSizeMismatch or
+	// ErrCodecInternal.
 	Decode(data [][]byte) ([][]byte, error)
 	// MaxChunks returns the max number of chunks this codec supports in a 2D
 	// original data square. Chunk is a synonym of share.
@@ -29,12 +76,230 @@ type Codec interface {
 	ValidateChunkSize(chunkSize int) error
 }
 
+// Verifier is an optional interface implemented by codecs that can verify
+// parity shares faster than re-encoding the original shares.
+type Verifier interface {
+	Codec
+	// Verify returns true if parity are the parity shares of original. There
+	// must be no missing shares.
+	Verify(original, parity [][]byte) (bool, error)
+}
+
+// BatchEncoder is an optional interface implemented by codecs that can encode
+// many rows or columns more cheaply at once than one at a time, e.g. by
+// looking up their encoder and allocating their work buffers once for the
+// whole batch. The extension of a square encodes its axes in batches with
+// EncodeBatchInto if the codec implements BatchEncoder.
+type BatchEncoder interface {
+	Codec
+	// EncodeBatch encodes the original shares of every axis in axes and
+	// returns the parity shares of each of them. All axes must have the same
+	// number of shares. There must be no missing shares.
+	EncodeBatch(axes [][][]byte) ([][][]byte, error)
+	// EncodeBatchInto is like EncodeBatch, but writes the parity shares of
+	// axes[i] into parityOut[i] instead of allocating them, like EncodeInto.
+	EncodeBatchInto(axes [][][]byte, parityOut [][][]byte) error
+}
+
+// encodeBatchInto encodes the original shares of every axis in axes into
+// parityOut with the EncodeBatchInto method of codec, or with EncodeInto for
+// each axis if codec does not implement BatchEncoder. Wrapped codecs are not
+// unwrapped, so that wrappers such as InstrumentedCodec still see every
+// axis.
+func encodeBatchInto(codec Codec, axes [][][]byte, parityOut [][][]byte) error {
+	if batchEncoder, ok := codec.(BatchEncoder); ok {
+		return batchEncoder.EncodeBatchInto(axes, parityOut)
+	}
+	if len(parityOut) != len(axes) {
+		return fmt.Errorf("%w: got parity for %d axes but %d axes", ErrShareCountMismatch, len(parityOut), len(axes))
+	}
+	for i, data := range axes {
+		if err := codec.EncodeInto(data, parityOut[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allocBatchParity returns the parity shares for every axis in axes, backed
+// by a single contiguous allocation.
+func allocBatchParity(axes [][][]byte) [][][]byte {
+	if len(axes) == 0 || len(axes[0]) == 0 {
+		return make([][][]byte, len(axes))
+	}
+	dataLen := len(axes[0])
+	shares := allocShares(len(axes)*dataLen, len(axes[0][0]))
+	parity := make([][][]byte, len(axes))
+	for i := range parity {
+		parity[i] = shares[i*dataLen : (i+1)*dataLen : (i+1)*dataLen]
+	}
+	return parity
+}
+
+// ChunkSizeReporter is an optional interface implemented by codecs that can
+// report the share sizes they support, so that callers can pick a valid share
+// size without trial and error against ValidateChunkSize.
+type ChunkSizeReporter interface {
+	Codec
+	// SupportedChunkSizes returns the min and max supported share size and the
+	// number of bytes supported share sizes must be a multiple of. A max of
+	// zero means there is no maximum.
+	SupportedChunkSizes() (minSize, maxSize, multiple int)
+}
+
+// SupportedChunkSizes returns the share sizes supported by codec, see
+// ChunkSizeReporter. ok is false if codec, or the codec it wraps, does not
+// implement ChunkSizeReporter.
+func SupportedChunkSizes(codec Codec) (minSize, maxSize, multiple int, ok bool) {
+	reporter, ok := asCodec[ChunkSizeReporter](codec)
+	if !ok {
+		return 0, 0, 0, false
+	}
+	minSize, maxSize, multiple = reporter.SupportedChunkSizes()
+	return minSize, maxSize, multiple, true
+}
+
+// AxisShareLimiter is an optional interface implemented by codecs that report
+// the max number of original shares per row or column, i.e. the max width of
+// an original data square.
+type AxisShareLimiter interface {
+	Codec
+	// MaxAxisShares returns the max number of original shares per row or
+	// column this codec supports.
+	MaxAxisShares() int
+}
+
+// MaxAxisShares returns the max number of original shares per row or column,
+// i.e. the max original data square width, supported by codec. If codec does
+// not implement AxisShareLimiter, it is derived from MaxChunks.
+func MaxAxisShares(codec Codec) int {
+	if limiter, ok := asCodec[AxisShareLimiter](codec); ok {
+		return limiter.MaxAxisShares()
+	}
+	return int(math.Sqrt(float64(codec.MaxChunks())))
+}
+
+// asCodec returns codec, or the first codec it wraps via an Unwrap method,
+// that implements T.
+func asCodec[T Codec](codec Codec) (T, bool) {
+	for codec != nil {
+		if t, ok := codec.(T); ok {
+			return t, true
+		}
+		wrapper, ok := codec.(interface{ Unwrap() Codec })
+		if !ok {
+			break
+		}
+		codec = wrapper.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
+// WidthValidator is an optional interface implemented by codecs that only
+// support some original data square widths below the width implied by
+// MaxChunks.
+type WidthValidator interface {
+	Codec
+	// ValidateWidth returns an error if this codec does not support original
+	// data squares of width odsWidth. Returns nil if odsWidth is supported.
+	ValidateWidth(odsWidth uint) error
+}
+
+// ValidateWidth returns an error wrapping ErrUnsupportedWidth if codec does not
+// support original data squares of width odsWidth, e.g. because the square
+// would exceed MaxChunks. Returns nil if odsWidth is supported.
+func ValidateWidth(codec Codec, odsWidth uint) error {
+	if maxWidth := MaxAxisShares(codec); odsWidth > uint(maxWidth) {
+		return fmt.Errorf("%w: original data square width %d exceeds the max of %d shares per axis for %s",
+			ErrUnsupportedWidth, odsWidth, maxWidth, codec.Name())
+	}
+	if odsWidth*odsWidth > uint(codec.MaxChunks()) {
+		return fmt.Errorf("%w: %d shares of an original data square of width %d exceed the maximum of %d for %s",
+			ErrUnsupportedWidth, odsWidth*odsWidth, odsWidth, codec.MaxChunks(), codec.Name())
+	}
+	if validator, ok := codec.(WidthValidator); ok {
+		if err := validator.ValidateWidth(odsWidth); err != nil {
+			return fmt.Errorf("%w: %v", ErrUnsupportedWidth, err)
+		}
+	}
+	return nil
+}
+
+// VersionedCodec is an optional interface implemented by codecs that version
+// their share layout. A codec must increment its version whenever a change
+// makes squares it encodes incompatible with previous versions, so that
+// incompatible serialized squares are rejected when unmarshalling. Codecs that
+// do not implement VersionedCodec have version 1.
+type VersionedCodec interface {
+	Codec
+	// Version returns the version of the codec.
+	Version() int
+}
+
+// codecVersion returns the version of codec.
+func codecVersion(codec Codec) int {
+	if versioned, ok := codec.(VersionedCodec); ok {
+		return versioned.Version()
+	}
+	return defaultCodecVersion
+}
+
 // codecs is a global map used for keeping track of registered codecs for testing and JSON unmarshalling
-var codecs = make(map[string]Codec)
+var (
+	codecs   = make(map[string]Codec)
+	codecsMu sync.RWMutex
+)
 
 func registerCodec(ct string, codec Codec) {
-	if codecs[ct] != nil {
-		panic(fmt.Sprintf("%v already registered", codec))
+	if err := RegisterCodec(ct, codec); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterCodec registers codec under name so that it can be looked up via
+// GetCodec, e.g. when unmarshalling an ExtendedDataSquare from JSON. Returns
+// an error if a codec is already registered under name. To replace a
+// registered codec, deregister it first via DeregisterCodec.
+func RegisterCodec(name string, codec Codec) error {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+
+	if codecs[name] != nil {
+		return fmt.Errorf("%w: %v", ErrCodecAlreadyRegistered, codecs[name])
+	}
+	codecs[name] = codec
+	return nil
+}
+
+// DeregisterCodec removes the codec registered under name. It is a no-op if no
+// codec is registered under name.
+func DeregisterCodec(name string) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+
+	delete(codecs, name)
+}
+
+// GetCodec returns the codec registered under name and true, or nil and false
+// if no codec is registered under name.
+func GetCodec(name string) (Codec, bool) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	codec, ok := codecs[name]
+	return codec, ok
+}
+
+// Codecs returns the sorted names of all registered codecs.
+func Codecs() []string {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	names := make([]string, 0, len(codecs))
+	for name := range codecs {
+		names = append(names, name)
 	}
-	codecs[ct] = codec
+	sort.Strings(names)
+	return names
 }
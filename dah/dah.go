@@ -0,0 +1,139 @@
+// Package dah implements the data availability header of an extended data
+// square: the roots of its rows and columns, which light clients download
+// instead of the square to verify samples of it.
+package dah
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/celestiaorg/merkletree"
+	"github.com/celestiaorg/rsmt2d"
+)
+
+// ErrInvalidHeader is returned when a DAHeader is malformed.
+var ErrInvalidHeader = errors.New("invalid data availability header")
+
+// DAHeader is the data availability header of an extended data square.
+type DAHeader struct {
+	// RowRoots are the roots of the rows of the square.
+	RowRoots [][]byte `json:"row_roots"`
+	// ColumnRoots are the roots of the columns of the square.
+	ColumnRoots [][]byte `json:"column_roots"`
+}
+
+// NewDAHeader returns the data availability header of eds. Returns an error
+// if the square is incomplete.
+func NewDAHeader(eds *rsmt2d.ExtendedDataSquare) (*DAHeader, error) {
+	rowRoots, err := eds.RowRoots()
+	if err != nil {
+		return nil, err
+	}
+	colRoots, err := eds.ColRoots()
+	if err != nil {
+		return nil, err
+	}
+	return &DAHeader{RowRoots: rowRoots, ColumnRoots: colRoots}, nil
+}
+
+// Validate returns an error wrapping ErrInvalidHeader if the header does not
+// have the same positive, even number of row and column roots, or if a root
+// is empty.
+func (h *DAHeader) Validate() error {
+	width := len(h.RowRoots)
+	if width == 0 || width%2 != 0 {
+		return fmt.Errorf("%w: got %d row roots, expected a positive even number", ErrInvalidHeader, width)
+	}
+	if len(h.ColumnRoots) != width {
+		return fmt.Errorf("%w: got %d column roots for %d row roots", ErrInvalidHeader, len(h.ColumnRoots), width)
+	}
+	for i := 0; i < width; i++ {
+		if len(h.RowRoots[i]) == 0 || len(h.ColumnRoots[i]) == 0 {
+			return fmt.Errorf("%w: empty root at index %d", ErrInvalidHeader, i)
+		}
+	}
+	return nil
+}
+
+// SquareWidth returns the width of the extended data square.
+func (h *DAHeader) SquareWidth() uint {
+	return uint(len(h.RowRoots))
+}
+
+// Hash returns the root of the Merkle tree, built with hasher, whose leaves
+// are the row roots followed by the column roots. It is the data root of the
+// square, see rsmt2d.ExtendedDataSquare.DataRoot.
+func (h *DAHeader) Hash(hasher hash.Hash) []byte {
+	tree := merkletree.New(hasher)
+	for _, root := range h.RowRoots {
+		tree.Push(root)
+	}
+	for _, root := range h.ColumnRoots {
+		tree.Push(root)
+	}
+	return tree.Root()
+}
+
+// Equals returns true if other has the same roots as h.
+func (h *DAHeader) Equals(other *DAHeader) bool {
+	return equalRoots(h.RowRoots, other.RowRoots) && equalRoots(h.ColumnRoots, other.ColumnRoots)
+}
+
+func equalRoots(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// UnmarshalJSON unmarshals a header and validates it, see Validate.
+func (h *DAHeader) UnmarshalJSON(b []byte) error {
+	type plain DAHeader
+	var decoded plain
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return err
+	}
+	if err := (*DAHeader)(&decoded).Validate(); err != nil {
+		return err
+	}
+	*h = DAHeader(decoded)
+	return nil
+}
+
+// VerifyShareInclusion returns nil if proof, as returned by
+// rsmt2d.ExtendedDataSquare.ProveShare or ProveColShare, proves that share is
+// the share at (rowIdx, colIdx) of the square with this header. The proof is
+// verified with a tree created by treeCreatorFn, see rsmt2d.VerifyShareProof.
+func (h *DAHeader) VerifyShareInclusion(
+	share []byte,
+	proof rsmt2d.ShareProof,
+	rowIdx, colIdx uint,
+	treeCreatorFn rsmt2d.TreeConstructorFn,
+) error {
+	width := h.SquareWidth()
+	if rowIdx >= width || colIdx >= width {
+		return fmt.Errorf("%w: cell (%d, %d) for width %d", rsmt2d.ErrOutOfBounds, rowIdx, colIdx, width)
+	}
+	if !bytes.Equal(proof.Share, share) {
+		return fmt.Errorf("%w: the proof is for a different share", rsmt2d.ErrInvalidShareProof)
+	}
+	if proof.NumLeaves != width {
+		return fmt.Errorf("%w: got %d leaves for width %d", rsmt2d.ErrInvalidShareProof, proof.NumLeaves, width)
+	}
+	root, axisIdx, index := h.RowRoots[rowIdx], rowIdx, colIdx
+	if proof.Axis == rsmt2d.Col {
+		root, axisIdx, index = h.ColumnRoots[colIdx], colIdx, rowIdx
+	}
+	if proof.Index != index {
+		return fmt.Errorf("%w: the proof is for index %d of %s %d, not %d", rsmt2d.ErrInvalidShareProof, proof.Index, proof.Axis, axisIdx, index)
+	}
+	return rsmt2d.VerifyShareProof(proof, root, axisIdx, treeCreatorFn)
+}
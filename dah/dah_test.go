@@ -0,0 +1,76 @@
+package dah
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"testing"
+
+	"github.com/celestiaorg/rsmt2d"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDAHeader(t *testing.T) {
+	const shareSize = 64
+	ods := make([][]byte, 16)
+	for i := range ods {
+		ods[i] = bytes.Repeat([]byte{byte(i)}, shareSize)
+	}
+	eds, err := rsmt2d.ComputeExtendedDataSquare(ods, rsmt2d.NewLeoRSCodec(), rsmt2d.NewDefaultTree)
+	require.NoError(t, err)
+
+	h, err := NewDAHeader(eds)
+	require.NoError(t, err)
+	require.NoError(t, h.Validate())
+	assert.Equal(t, eds.Width(), h.SquareWidth())
+
+	dataRoot, err := eds.DataRoot(sha256.New())
+	require.NoError(t, err)
+	assert.Equal(t, dataRoot, h.Hash(sha256.New()))
+
+	b, err := json.Marshal(h)
+	require.NoError(t, err)
+	var decoded DAHeader
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.True(t, h.Equals(&decoded))
+
+	for rowIdx := uint(0); rowIdx < eds.Width(); rowIdx++ {
+		for colIdx := uint(0); colIdx < eds.Width(); colIdx++ {
+			share := eds.GetCell(rowIdx, colIdx)
+			proof, err := eds.ProveShare(rowIdx, colIdx)
+			require.NoError(t, err)
+			assert.NoError(t, h.VerifyShareInclusion(share, proof, rowIdx, colIdx, rsmt2d.NewDefaultTree))
+			proof, err = eds.ProveColShare(rowIdx, colIdx)
+			require.NoError(t, err)
+			assert.NoError(t, h.VerifyShareInclusion(share, proof, rowIdx, colIdx, rsmt2d.NewDefaultTree))
+		}
+	}
+
+	t.Run("invalid inclusion", func(t *testing.T) {
+		proof, err := eds.ProveShare(1, 2)
+		require.NoError(t, err)
+		share := eds.GetCell(1, 2)
+		assert.ErrorIs(t, h.VerifyShareInclusion(eds.GetCell(1, 3), proof, 1, 2, rsmt2d.NewDefaultTree), rsmt2d.ErrInvalidShareProof)
+		assert.ErrorIs(t, h.VerifyShareInclusion(share, proof, 1, 3, rsmt2d.NewDefaultTree), rsmt2d.ErrInvalidShareProof)
+		assert.ErrorIs(t, h.VerifyShareInclusion(share, proof, 2, 2, rsmt2d.NewDefaultTree), rsmt2d.ErrInvalidShareProof)
+		assert.ErrorIs(t, h.VerifyShareInclusion(share, proof, 1, eds.Width(), rsmt2d.NewDefaultTree), rsmt2d.ErrOutOfBounds)
+	})
+
+	t.Run("invalid headers", func(t *testing.T) {
+		for name, invalid := range map[string]*DAHeader{
+			"empty":          {},
+			"odd width":      {RowRoots: h.RowRoots[:3], ColumnRoots: h.ColumnRoots[:3]},
+			"uneven roots":   {RowRoots: h.RowRoots, ColumnRoots: h.ColumnRoots[:2]},
+			"empty row root": {RowRoots: append([][]byte{nil}, h.RowRoots[1:]...), ColumnRoots: h.ColumnRoots},
+		} {
+			t.Run(name, func(t *testing.T) {
+				assert.ErrorIs(t, invalid.Validate(), ErrInvalidHeader)
+				b, err := json.Marshal(invalid)
+				require.NoError(t, err)
+				var decoded DAHeader
+				assert.ErrorIs(t, json.Unmarshal(b, &decoded), ErrInvalidHeader)
+			})
+		}
+	})
+}
@@ -5,26 +5,97 @@ import (
 	"fmt"
 	"math"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 )
 
-// ErrUnevenChunks is thrown when non-nil shares are not all of equal size.
-// Note: chunks is synonymous with shares.
-var ErrUnevenChunks = errors.New("non-nil shares not all of equal size")
+var (
+	// ErrUnevenChunks is thrown when non-nil shares are not all of equal size.
+	// Note: chunks is synonymous with shares.
+	ErrUnevenChunks = errors.New("non-nil shares not all of equal size")
+	// ErrNotSquare is returned when the number of shares of a data square is
+	// not a square number.
+	ErrNotSquare = errors.New("number of shares must be a square number")
+	// ErrCellAlreadySet is returned when setting a cell that already has a
+	// value.
+	ErrCellAlreadySet = errors.New("cell already has a value")
+	// ErrIncompleteAxis is returned when an operation requires a complete row
+	// or column but some of its shares are nil.
+	ErrIncompleteAxis = errors.New("row or column is incomplete")
+	// ErrOutOfBounds is returned when a row, column or cell index is outside
+	// of the square.
+	ErrOutOfBounds = errors.New("index out of bounds")
+)
 
 // dataSquare stores all data for an original data square (ODS) or extended
 // data square (EDS). Data is duplicated in both row-major and column-major
 // order in order to be able to provide zero-allocation column slices.
 type dataSquare struct {
-	squareRow    [][][]byte // row-major
-	squareCol    [][][]byte // col-major
-	dataMutex    sync.Mutex
+	squareRow [][][]byte // row-major
+	squareCol [][][]byte // col-major
+	// dataMutex guards the share references of squareRow and squareCol, so
+	// that cells can be set and read concurrently.
+	dataMutex    sync.RWMutex
 	width        uint
 	shareSize    uint
 	rowRoots     [][]byte
 	colRoots     [][]byte
 	createTreeFn TreeConstructorFn
+	// lazy, if set, is the deferred encoding of the parity quadrants of a
+	// square created by ComputeExtendedDataSquareLazy.
+	lazy *lazyExtension
+}
+
+// lazyExtension defers the encoding of the parity quadrants of an extended
+// data square until a share outside of the original data is first accessed.
+type lazyExtension struct {
+	mu   sync.Mutex
+	done atomic.Bool
+	// odsWidth is the width of the original data square, which can be
+	// accessed without encoding the parity quadrants.
+	odsWidth uint
+	// encode encodes the parity quadrants.
+	encode func() error
+	err    error
+}
+
+// materialize encodes the parity quadrants of a lazily extended square if they
+// have not been encoded yet, and returns the error of the encoding, if any.
+func (ds *dataSquare) materialize() error {
+	l := ds.lazy
+	if l == nil {
+		return nil
+	}
+	if !l.done.Load() {
+		l.mu.Lock()
+		if !l.done.Load() {
+			l.err = l.encode()
+			l.done.Store(true)
+		}
+		l.mu.Unlock()
+	}
+	return l.err
+}
+
+// mustMaterialize is like materialize, but panics if the encoding fails. It is
+// used by accessors that can not return an error. The encoding can only fail
+// if the codec fails internally, as the square has been validated before.
+func (ds *dataSquare) mustMaterialize() {
+	if err := ds.materialize(); err != nil {
+		panic(fmt.Sprintf("failed to encode the parity quadrants of a lazily extended square: %v", err))
+	}
+}
+
+// materializeFor materializes a lazily extended square if the cells up to
+// (lastRowIdx, lastColIdx) are not all in the original data square.
+func (ds *dataSquare) materializeFor(lastRowIdx uint, lastColIdx uint) {
+	l := ds.lazy
+	if l == nil || l.done.Load() || (lastRowIdx < l.odsWidth && lastColIdx < l.odsWidth) {
+		return
+	}
+	ds.mustMaterialize()
 }
 
 // newDataSquare populates the data square from the supplied data and treeCreator.
@@ -33,8 +104,7 @@ type dataSquare struct {
 func newDataSquare(data [][]byte, treeCreator TreeConstructorFn, shareSize uint) (*dataSquare, error) {
 	width := int(math.Ceil(math.Sqrt(float64(len(data)))))
 	if width*width != len(data) {
-		// TODO: export this error and modify chunks to shares
-		return nil, errors.New("number of chunks must be a square number")
+		return nil, fmt.Errorf("%w: got %d shares", ErrNotSquare, len(data))
 	}
 
 	for _, d := range data {
@@ -75,8 +145,7 @@ func newDataSquare(data [][]byte, treeCreator TreeConstructorFn, shareSize uint)
 // the extended quadrants with fillerShare.
 func (ds *dataSquare) extendSquare(extendedWidth uint, fillerShare []byte) error {
 	if uint(len(fillerShare)) != ds.shareSize {
-		// TODO: export this error and rename chunk to share
-		return errors.New("filler chunk size does not match data square chunk size")
+		return fmt.Errorf("%w: filler share size %d does not match data square share size %d", ErrInvalidShareSize, len(fillerShare), ds.shareSize)
 	}
 
 	newWidth := ds.width + extendedWidth
@@ -120,7 +189,33 @@ func (ds *dataSquare) extendSquare(extendedWidth uint, fillerShare []byte) error
 	return nil
 }
 
+// allocExtendedQuadrants replaces every share outside of the top left
+// originalWidth x originalWidth quadrant with a zeroed share, allocated in a
+// single contiguous buffer. The shares are shared by the row-major and
+// column-major storage, so that writing into a share in place updates both.
+func (ds *dataSquare) allocExtendedQuadrants(originalWidth uint) {
+	ds.dataMutex.Lock()
+	defer ds.dataMutex.Unlock()
+
+	shares := allocShares(int(ds.width*ds.width-originalWidth*originalWidth), int(ds.shareSize))
+	for rowIdx := uint(0); rowIdx < ds.width; rowIdx++ {
+		for colIdx := uint(0); colIdx < ds.width; colIdx++ {
+			if rowIdx < originalWidth && colIdx < originalWidth {
+				continue
+			}
+			ds.squareRow[rowIdx][colIdx] = shares[0]
+			ds.squareCol[colIdx][rowIdx] = shares[0]
+			shares = shares[1:]
+		}
+	}
+
+	ds.resetRoots()
+}
+
 func (ds *dataSquare) rowSlice(rowIdx uint, fromIdx uint, length uint) [][]byte {
+	if length > 0 {
+		ds.materializeFor(rowIdx, fromIdx+length-1)
+	}
 	return ds.squareRow[rowIdx][fromIdx : fromIdx+length]
 }
 
@@ -130,15 +225,26 @@ func (ds *dataSquare) row(rowIdx uint) [][]byte {
 	return ds.rowSlice(rowIdx, 0, ds.width)
 }
 
+// rowCopy returns a deep copy of a row. Unlike row, it can be called
+// concurrently with SetCell.
+func (ds *dataSquare) rowCopy(rowIdx uint) [][]byte {
+	row := ds.row(rowIdx)
+	ds.dataMutex.RLock()
+	defer ds.dataMutex.RUnlock()
+	return deepCopy(row)
+}
+
 func (ds *dataSquare) setRowSlice(rowIdx uint, fromIdx uint, newRow [][]byte) error {
 	for i := uint(0); i < uint(len(newRow)); i++ {
 		if len(newRow[i]) != int(ds.shareSize) {
-			// TODO: export this error and rename chunk to share
-			return errors.New("invalid chunk size")
+			return fmt.Errorf("%w: share %d of the row slice has size %d, expected %d", ErrInvalidShareSize, i, len(newRow[i]), ds.shareSize)
 		}
 	}
 	if fromIdx+uint(len(newRow)) > ds.width {
-		return fmt.Errorf("cannot set row slice at (%d, %d) of length %d: because it would exceed the data square width %d", rowIdx, fromIdx, len(newRow), ds.width)
+		return fmt.Errorf("%w: cannot set row slice at (%d, %d) of length %d: because it would exceed the data square width %d", ErrOutOfBounds, rowIdx, fromIdx, len(newRow), ds.width)
+	}
+	if err := ds.materialize(); err != nil {
+		return err
 	}
 
 	ds.dataMutex.Lock()
@@ -147,14 +253,16 @@ func (ds *dataSquare) setRowSlice(rowIdx uint, fromIdx uint, newRow [][]byte) er
 	for i := uint(0); i < uint(len(newRow)); i++ {
 		ds.squareRow[rowIdx][fromIdx+i] = newRow[i]
 		ds.squareCol[fromIdx+i][rowIdx] = newRow[i]
+		ds.invalidateRoots(rowIdx, fromIdx+i)
 	}
 
-	ds.resetRoots()
-
 	return nil
 }
 
 func (ds *dataSquare) colSlice(rowIdx uint, colIdx uint, length uint) [][]byte {
+	if length > 0 {
+		ds.materializeFor(rowIdx+length-1, colIdx)
+	}
 	return ds.squareCol[colIdx][rowIdx : rowIdx+length]
 }
 
@@ -164,15 +272,26 @@ func (ds *dataSquare) col(colIdx uint) [][]byte {
 	return ds.colSlice(0, colIdx, ds.width)
 }
 
+// colCopy returns a deep copy of a column. Unlike col, it can be called
+// concurrently with SetCell.
+func (ds *dataSquare) colCopy(colIdx uint) [][]byte {
+	col := ds.col(colIdx)
+	ds.dataMutex.RLock()
+	defer ds.dataMutex.RUnlock()
+	return deepCopy(col)
+}
+
 func (ds *dataSquare) setColSlice(colIdx uint, fromIdx uint, newCol [][]byte) error {
 	for i := uint(0); i < uint(len(newCol)); i++ {
 		if len(newCol[i]) != int(ds.shareSize) {
-			// TODO: export this error and rename chunk to share
-			return errors.New("invalid chunk size")
+			return fmt.Errorf("%w: share %d of the col slice has size %d, expected %d", ErrInvalidShareSize, i, len(newCol[i]), ds.shareSize)
 		}
 	}
 	if fromIdx+uint(len(newCol)) > ds.width {
-		return fmt.Errorf("cannot set col slice at (%d, %d) of length %d: because it would exceed the data square width %d", fromIdx, colIdx, len(newCol), ds.width)
+		return fmt.Errorf("%w: cannot set col slice at (%d, %d) of length %d: because it would exceed the data square width %d", ErrOutOfBounds, fromIdx, colIdx, len(newCol), ds.width)
+	}
+	if err := ds.materialize(); err != nil {
+		return err
 	}
 
 	ds.dataMutex.Lock()
@@ -181,13 +300,30 @@ func (ds *dataSquare) setColSlice(colIdx uint, fromIdx uint, newCol [][]byte) er
 	for i := uint(0); i < uint(len(newCol)); i++ {
 		ds.squareRow[fromIdx+i][colIdx] = newCol[i]
 		ds.squareCol[colIdx][fromIdx+i] = newCol[i]
+		ds.invalidateRoots(fromIdx+i, colIdx)
 	}
 
-	ds.resetRoots()
-
 	return nil
 }
 
+// reset sets every share in the data square to nil and clears the cached
+// roots. The row-major and column-major backing slices are kept so that they
+// can be reused.
+func (ds *dataSquare) reset() {
+	ds.dataMutex.Lock()
+	defer ds.dataMutex.Unlock()
+
+	// the parity quadrants are cleared, so they never need to be encoded
+	ds.lazy = nil
+
+	for i := uint(0); i < ds.width; i++ {
+		clear(ds.squareRow[i])
+		clear(ds.squareCol[i])
+	}
+
+	ds.resetRoots()
+}
+
 func (ds *dataSquare) resetRoots() {
 	// don't write nil if it's already nil
 	// this prevents rewriting nil into shared memory slot
@@ -200,50 +336,115 @@ func (ds *dataSquare) resetRoots() {
 	}
 }
 
+// maxRootWorkers holds the maximum number of roots computed concurrently by
+// computeRoots. Zero or less means no limit.
+var maxRootWorkers atomic.Int64
+
+// SetMaxRootWorkers limits the number of row and column roots that are
+// computed concurrently for a square, e.g. to runtime.GOMAXPROCS(0) on shared
+// hosts. By default, every root of a square is computed in its own goroutine.
+// Passing zero or a negative n removes the limit.
+func SetMaxRootWorkers(n int) {
+	maxRootWorkers.Store(int64(n))
+}
+
+// newTree creates a tree for the row or column at index, passing the
+// dimensions of the square to trees that implement SquareAwareTree.
+func (ds *dataSquare) newTree(axis Axis, index uint) Tree {
+	return newSquareTree(ds.createTreeFn, axis, index, SquareInfo{Width: ds.width, ShareSize: ds.shareSize})
+}
+
+// newSquareTree creates a tree with treeCreatorFn and passes info to it if it
+// implements SquareAwareTree.
+func newSquareTree(treeCreatorFn TreeConstructorFn, axis Axis, index uint, info SquareInfo) Tree {
+	tree := treeCreatorFn(axis, index)
+	if t, ok := tree.(SquareAwareTree); ok {
+		t.SetSquareInfo(info)
+	}
+	return tree
+}
+
+// invalidateRoots marks the cached roots of the row rowIdx and column colIdx
+// as stale, so that only they are recomputed by the next call to
+// computeRoots.
+func (ds *dataSquare) invalidateRoots(rowIdx uint, colIdx uint) {
+	// don't write nil if it's already nil, see resetRoots
+	if ds.rowRoots != nil && ds.rowRoots[rowIdx] != nil {
+		ds.rowRoots[rowIdx] = nil
+	}
+	if ds.colRoots != nil && ds.colRoots[colIdx] != nil {
+		ds.colRoots[colIdx] = nil
+	}
+}
+
+// cachedRoots returns the roots of all rows and columns if all of them are
+// cached, without computing any.
+func (ds *dataSquare) cachedRoots() (rowRoots [][]byte, colRoots [][]byte, ok bool) {
+	if ds.rowRoots == nil || ds.colRoots == nil {
+		return nil, nil, false
+	}
+	if !isComplete(ds.rowRoots) || !isComplete(ds.colRoots) {
+		return nil, nil, false
+	}
+	return ds.rowRoots, ds.colRoots, true
+}
+
+// computeRoots computes the roots of all rows and columns whose roots are
+// not cached, i.e. all of them after resetRoots and only the invalidated ones
+// after invalidateRoots.
 func (ds *dataSquare) computeRoots() error {
 	var g errgroup.Group
+	if n := maxRootWorkers.Load(); n > 0 {
+		g.SetLimit(int(n))
+	}
 
-	rowRoots := make([][]byte, ds.width)
-	colRoots := make([][]byte, ds.width)
+	if ds.rowRoots == nil {
+		ds.rowRoots = make([][]byte, ds.width)
+	}
+	if ds.colRoots == nil {
+		ds.colRoots = make([][]byte, ds.width)
+	}
+	rowRoots := ds.rowRoots
+	colRoots := ds.colRoots
 
 	for i := uint(0); i < ds.width; i++ {
 		i := i // https://go.dev/doc/faq#closures_and_goroutines
-		g.Go(func() error {
-			rowRoot, err := ds.getRowRoot(i)
-			if err != nil {
-				return err
-			}
-			rowRoots[i] = rowRoot
-			return nil
-		})
-
-		g.Go(func() error {
-			colRoot, err := ds.getColRoot(i)
-			if err != nil {
-				return err
-			}
-			colRoots[i] = colRoot
-			return nil
-		})
-	}
+		if rowRoots[i] == nil {
+			g.Go(func() error {
+				start := time.Now()
+				rowRoot, err := ds.computeRowRoot(i)
+				emitEvent(Event{Type: EventAxisRootComputed, Width: ds.width, Axis: Row, Index: i, Duration: time.Since(start), Err: err})
+				if err != nil {
+					return err
+				}
+				rowRoots[i] = rowRoot
+				return nil
+			})
+		}
 
-	err := g.Wait()
-	if err != nil {
-		return err
+		if colRoots[i] == nil {
+			g.Go(func() error {
+				start := time.Now()
+				colRoot, err := ds.computeColRoot(i)
+				emitEvent(Event{Type: EventAxisRootComputed, Width: ds.width, Axis: Col, Index: i, Duration: time.Since(start), Err: err})
+				if err != nil {
+					return err
+				}
+				colRoots[i] = colRoot
+				return nil
+			})
+		}
 	}
 
-	ds.rowRoots = rowRoots
-	ds.colRoots = colRoots
-	return nil
+	return g.Wait()
 }
 
-// getRowRoots returns the Merkle roots of all the rows in the square.
+// getRowRoots returns the Merkle roots of all the rows in the square,
+// recomputing the ones that are not cached.
 func (ds *dataSquare) getRowRoots() ([][]byte, error) {
-	if ds.rowRoots == nil {
-		err := ds.computeRoots()
-		if err != nil {
-			return nil, err
-		}
+	err := ds.computeRoots()
+	if err != nil {
+		return nil, err
 	}
 
 	return ds.rowRoots, nil
@@ -253,14 +454,19 @@ func (ds *dataSquare) getRowRoots() ([][]byte, error) {
 // the getRowRoots method, getRowRoot does not write to the built-in cache.
 // Returns an error if the row is incomplete (i.e. some shares are nil).
 func (ds *dataSquare) getRowRoot(rowIdx uint) ([]byte, error) {
-	if ds.rowRoots != nil {
+	if ds.rowRoots != nil && ds.rowRoots[rowIdx] != nil {
 		return ds.rowRoots[rowIdx], nil
 	}
+	return ds.computeRowRoot(rowIdx)
+}
 
-	tree := ds.createTreeFn(Row, rowIdx)
+// computeRowRoot calculates the root of the selected row.
+func (ds *dataSquare) computeRowRoot(rowIdx uint) ([]byte, error) {
+	tree := ds.newTree(Row, rowIdx)
+	defer releaseTree(tree)
 	row := ds.row(rowIdx)
 	if !isComplete(row) {
-		return nil, errors.New("can not compute root of incomplete row")
+		return nil, fmt.Errorf("%w: can not compute root of row %d", ErrIncompleteAxis, rowIdx)
 	}
 	for _, d := range row {
 		err := tree.Push(d)
@@ -269,16 +475,15 @@ func (ds *dataSquare) getRowRoot(rowIdx uint) ([]byte, error) {
 		}
 	}
 
-	return tree.Root()
+	return consumeRoot(tree)
 }
 
-// getColRoots returns the Merkle roots of all the columns in the square.
+// getColRoots returns the Merkle roots of all the columns in the square,
+// recomputing the ones that are not cached.
 func (ds *dataSquare) getColRoots() ([][]byte, error) {
-	if ds.colRoots == nil {
-		err := ds.computeRoots()
-		if err != nil {
-			return nil, err
-		}
+	err := ds.computeRoots()
+	if err != nil {
+		return nil, err
 	}
 
 	return ds.colRoots, nil
@@ -288,14 +493,19 @@ func (ds *dataSquare) getColRoots() ([][]byte, error) {
 // the getColRoots method, getColRoot does not write to the built-in cache.
 // Returns an error if the column is incomplete (i.e. some shares are nil).
 func (ds *dataSquare) getColRoot(colIdx uint) ([]byte, error) {
-	if ds.colRoots != nil {
+	if ds.colRoots != nil && ds.colRoots[colIdx] != nil {
 		return ds.colRoots[colIdx], nil
 	}
+	return ds.computeColRoot(colIdx)
+}
 
-	tree := ds.createTreeFn(Col, colIdx)
+// computeColRoot calculates the root of the selected column.
+func (ds *dataSquare) computeColRoot(colIdx uint) ([]byte, error) {
+	tree := ds.newTree(Col, colIdx)
+	defer releaseTree(tree)
 	col := ds.col(colIdx)
 	if !isComplete(col) {
-		return nil, errors.New("can not compute root of incomplete column")
+		return nil, fmt.Errorf("%w: can not compute root of column %d", ErrIncompleteAxis, colIdx)
 	}
 	for _, d := range col {
 		err := tree.Push(d)
@@ -304,11 +514,15 @@ func (ds *dataSquare) getColRoot(colIdx uint) ([]byte, error) {
 		}
 	}
 
-	return tree.Root()
+	return consumeRoot(tree)
 }
 
-// GetCell returns a copy of a specific cell.
+// GetCell returns a copy of a specific cell. It can be called concurrently
+// with SetCell.
 func (ds *dataSquare) GetCell(rowIdx uint, colIdx uint) []byte {
+	ds.materializeFor(rowIdx, colIdx)
+	ds.dataMutex.RLock()
+	defer ds.dataMutex.RUnlock()
 	if ds.squareRow[rowIdx][colIdx] == nil {
 		return nil
 	}
@@ -318,23 +532,81 @@ func (ds *dataSquare) GetCell(rowIdx uint, colIdx uint) []byte {
 }
 
 // SetCell sets a specific cell. The cell to set must be `nil`. Returns an error
-// if the cell to set is not `nil` or newShare is not the correct size.
+// if the cell to set is not `nil` or newShare is not the correct size. It can
+// be called concurrently with itself, SetCells and GetCell.
 func (ds *dataSquare) SetCell(rowIdx uint, colIdx uint, newShare []byte) error {
+	if err := ds.materialize(); err != nil {
+		return err
+	}
+	ds.dataMutex.Lock()
+	defer ds.dataMutex.Unlock()
 	if ds.squareRow[rowIdx][colIdx] != nil {
-		return fmt.Errorf("cannot set cell (%d, %d) as it already has a value %x", rowIdx, colIdx, ds.squareRow[rowIdx][colIdx])
+		return fmt.Errorf("%w: cannot set cell (%d, %d) as it already has a value %x", ErrCellAlreadySet, rowIdx, colIdx, ds.squareRow[rowIdx][colIdx])
 	}
 	if len(newShare) != int(ds.shareSize) {
-		// TODO: export this error and rename chunk to share
-		return fmt.Errorf("cannot set cell with chunk size %d because dataSquare chunk size is %d", len(newShare), ds.shareSize)
+		return fmt.Errorf("%w: cannot set cell with share size %d because dataSquare share size is %d", ErrInvalidShareSize, len(newShare), ds.shareSize)
 	}
 	ds.squareRow[rowIdx][colIdx] = newShare
 	ds.squareCol[colIdx][rowIdx] = newShare
-	ds.resetRoots()
+	ds.invalidateRoots(rowIdx, colIdx)
 	return nil
 }
 
+// CellUpdate is a share to be set at a cell by SetCells.
+type CellUpdate struct {
+	Row, Col uint
+	Share    []byte
+}
+
+// SetCells sets many cells at once, e.g. while populating a square from a
+// network stream. Like with SetCell, every cell to set must be nil and every
+// share must be of the correct size, and a cell must not be set twice. All
+// updates are validated before any cell is set, so that no cell is set if an
+// error is returned.
+func (ds *dataSquare) SetCells(cells []CellUpdate) error {
+	if err := ds.materialize(); err != nil {
+		return err
+	}
+	ds.dataMutex.Lock()
+	defer ds.dataMutex.Unlock()
+
+	updated := newBitMatrix(ds.width)
+	for _, c := range cells {
+		if c.Row >= ds.width || c.Col >= ds.width {
+			return fmt.Errorf("%w: cell (%d, %d) for width %d", ErrOutOfBounds, c.Row, c.Col, ds.width)
+		}
+		if ds.squareRow[c.Row][c.Col] != nil || updated.get(c.Row, c.Col) {
+			return fmt.Errorf("%w: cannot set cell (%d, %d) twice", ErrCellAlreadySet, c.Row, c.Col)
+		}
+		if len(c.Share) != int(ds.shareSize) {
+			return fmt.Errorf("%w: cannot set cell with share size %d because dataSquare share size is %d", ErrInvalidShareSize, len(c.Share), ds.shareSize)
+		}
+		updated.set(c.Row, c.Col)
+	}
+
+	for _, c := range cells {
+		ds.squareRow[c.Row][c.Col] = c.Share
+		ds.squareCol[c.Col][c.Row] = c.Share
+		ds.invalidateRoots(c.Row, c.Col)
+	}
+	return nil
+}
+
+// clearCell sets a specific cell to nil.
+func (ds *dataSquare) clearCell(rowIdx uint, colIdx uint) {
+	ds.mustMaterialize()
+	ds.dataMutex.Lock()
+	defer ds.dataMutex.Unlock()
+	ds.squareRow[rowIdx][colIdx] = nil
+	ds.squareCol[colIdx][rowIdx] = nil
+	ds.invalidateRoots(rowIdx, colIdx)
+}
+
 // Flattened returns the concatenated rows of the data square.
 func (ds *dataSquare) Flattened() [][]byte {
+	ds.mustMaterialize()
+	ds.dataMutex.RLock()
+	defer ds.dataMutex.RUnlock()
 	flattened := make([][]byte, 0, ds.width*ds.width)
 	for _, data := range ds.squareRow {
 		flattened = append(flattened, data...)
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"math"
 	"reflect"
+	"sync/atomic"
 	"testing"
 
 	"github.com/celestiaorg/merkletree"
 	"github.com/celestiaorg/nmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewDataSquare(t *testing.T) {
@@ -41,16 +43,15 @@ func TestInvalidDataSquareCreation(t *testing.T) {
 		name      string
 		cells     [][]byte
 		shareSize uint
+		wantErr   error
 	}{
-		{"InconsistentShareNumber", [][]byte{{1, 2}, {3, 4}, {5, 6}}, 2},
-		{"UnequalShareSize", [][]byte{{1, 2}, {3, 4}, {5, 6}, {7}}, 2},
+		{"InconsistentShareNumber", [][]byte{{1, 2}, {3, 4}, {5, 6}}, 2, ErrNotSquare},
+		{"UnequalShareSize", [][]byte{{1, 2}, {3, 4}, {5, 6}, {7}}, 2, ErrUnevenChunks},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			_, err := newDataSquare(test.cells, NewDefaultTree, test.shareSize)
-			if err == nil {
-				t.Errorf("newDataSquare failed; shares accepted with %v", test.name)
-			}
+			assert.ErrorIs(t, err, test.wantErr, "newDataSquare failed; shares accepted with %v", test.name)
 		})
 	}
 }
@@ -60,7 +61,7 @@ func TestSetCell(t *testing.T) {
 		name         string
 		originalCell []byte
 		newCell      []byte
-		wantErr      bool
+		wantErr      error
 	}
 
 	testCases := []testCase{
@@ -68,18 +69,18 @@ func TestSetCell(t *testing.T) {
 			name:         "can set cell if originally nil",
 			originalCell: nil,
 			newCell:      []byte{42},
-			wantErr:      false,
+			wantErr:      nil,
 		},
 		{
 			name:         "expect error if cell is not originally nil",
 			originalCell: []byte{1},
-			wantErr:      true,
+			wantErr:      ErrCellAlreadySet,
 		},
 		{
 			name:         "expect error if new cell is not the correct share size",
 			originalCell: nil,
 			newCell:      []byte{1, 2}, // incorrect share size
-			wantErr:      true,
+			wantErr:      ErrInvalidShareSize,
 		},
 	}
 
@@ -89,8 +90,8 @@ func TestSetCell(t *testing.T) {
 			assert.NoError(t, err)
 
 			err = ds.SetCell(0, 0, tc.newCell)
-			if tc.wantErr {
-				assert.Error(t, err)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tc.newCell, ds.GetCell(0, 0))
@@ -165,6 +166,32 @@ func TestFlattened(t *testing.T) {
 	}
 }
 
+func TestSetCells(t *testing.T) {
+	ds, err := newDataSquare([][]byte{nil, {2}, nil, {4}}, NewDefaultTree, 1)
+	require.NoError(t, err)
+
+	invalid := map[string]struct {
+		cells   []CellUpdate
+		wantErr error
+	}{
+		"cell already set":     {[]CellUpdate{{0, 0, []byte{1}}, {0, 1, []byte{1}}}, ErrCellAlreadySet},
+		"cell set twice":       {[]CellUpdate{{0, 0, []byte{1}}, {0, 0, []byte{1}}}, ErrCellAlreadySet},
+		"incorrect share size": {[]CellUpdate{{0, 0, []byte{1}}, {1, 0, []byte{1, 2}}}, ErrInvalidShareSize},
+		"cell out of bounds":   {[]CellUpdate{{0, 0, []byte{1}}, {2, 0, []byte{1}}}, ErrOutOfBounds},
+	}
+	for name, tc := range invalid {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, ds.SetCells(tc.cells), tc.wantErr)
+			// no cell is set if any update is invalid
+			assert.Nil(t, ds.GetCell(0, 0))
+		})
+	}
+
+	require.NoError(t, ds.SetCells([]CellUpdate{{0, 0, []byte{1}}, {1, 0, []byte{3}}}))
+	assert.Equal(t, [][]byte{{1}, {2}, {3}, {4}}, ds.Flattened())
+	assert.Equal(t, [][]byte{{1}, {3}}, ds.col(0))
+}
+
 func TestExtendSquare(t *testing.T) {
 	ds, err := newDataSquare([][]byte{{1, 2}}, NewDefaultTree, 2)
 	if err != nil {
@@ -526,3 +553,136 @@ func (ds *dataSquare) setCell(rowIdx uint, colIdx uint, newShare []byte) {
 	ds.squareCol[colIdx][rowIdx] = newShare
 	ds.resetRoots()
 }
+
+func TestSetCellRecomputesAffectedRoots(t *testing.T) {
+	var created atomic.Int64
+	treeFn := func(axis Axis, index uint) Tree {
+		created.Add(1)
+		return NewDefaultTree(axis, index)
+	}
+	eds, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), NewLeoRSCodec(), treeFn)
+	require.NoError(t, err)
+	wantRowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	wantColRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2*eds.Width()), created.Load())
+
+	share := eds.GetCell(1, 2)
+	eds.clearCell(1, 2)
+	require.NoError(t, eds.SetCell(1, 2, share))
+
+	created.Store(0)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+	assert.Equal(t, wantRowRoots, rowRoots)
+	assert.Equal(t, wantColRoots, colRoots)
+	assert.Equal(t, int64(2), created.Load(), "only row 1 and column 2 should be recomputed")
+
+	t.Run("incomplete axes are not cached", func(t *testing.T) {
+		eds.clearCell(1, 2)
+		_, err := eds.RowRoots()
+		assert.Error(t, err)
+		_, err = eds.getRowRoot(0)
+		assert.NoError(t, err)
+		require.NoError(t, eds.SetCell(1, 2, share))
+		rowRoots, err := eds.RowRoots()
+		require.NoError(t, err)
+		assert.Equal(t, wantRowRoots, rowRoots)
+	})
+}
+
+// consumeCountingTree counts the roots consumed by rsmt2d.
+type consumeCountingTree struct {
+	*DefaultTree
+	consumed *atomic.Int64
+}
+
+func (c consumeCountingTree) ConsumeRoot() ([]byte, error) {
+	c.consumed.Add(1)
+	return c.DefaultTree.ConsumeRoot()
+}
+
+func TestComputeRootsConsumesTrees(t *testing.T) {
+	want := createExampleEds(t, shareSize)
+	wantRowRoots, err := want.RowRoots()
+	require.NoError(t, err)
+
+	var consumed atomic.Int64
+	treeFn := func(axis Axis, index uint) Tree {
+		return consumeCountingTree{DefaultTree: NewDefaultTree(axis, index).(*DefaultTree), consumed: &consumed}
+	}
+	eds, err := ComputeExtendedDataSquare(want.FlattenedODS(), NewLeoRSCodec(), treeFn)
+	require.NoError(t, err)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	assert.Equal(t, wantRowRoots, rowRoots)
+	assert.Equal(t, int64(2*eds.Width()), consumed.Load())
+
+	t.Run("DefaultTree", func(t *testing.T) {
+		tree := NewDefaultTree(Row, 0).(*DefaultTree)
+		for _, share := range want.Row(0) {
+			require.NoError(t, tree.Push(share))
+		}
+		root, err := tree.ConsumeRoot()
+		require.NoError(t, err)
+		assert.Equal(t, wantRowRoots[0], root)
+	})
+}
+
+// squareInfoTree records the square info passed by rsmt2d.
+type squareInfoTree struct {
+	*DefaultTree
+	info *SquareInfo
+}
+
+func (s squareInfoTree) SetSquareInfo(info SquareInfo) {
+	*s.info = info
+}
+
+func TestNewTreeSetsSquareInfo(t *testing.T) {
+	var info SquareInfo
+	treeFn := func(axis Axis, index uint) Tree {
+		return squareInfoTree{DefaultTree: NewDefaultTree(axis, index).(*DefaultTree), info: &info}
+	}
+	ds, err := newDataSquare(generateRandData(16, shareSize), treeFn, shareSize)
+	require.NoError(t, err)
+	_, err = ds.getRowRoot(0)
+	require.NoError(t, err)
+	assert.Equal(t, SquareInfo{Width: 4, ShareSize: shareSize}, info)
+}
+
+// activeTree tracks the number of trees that have not been released yet.
+type activeTree struct {
+	*DefaultTree
+	active *atomic.Int64
+}
+
+func (a activeTree) Release() {
+	a.active.Add(-1)
+}
+
+func TestSetMaxRootWorkers(t *testing.T) {
+	SetMaxRootWorkers(1)
+	defer SetMaxRootWorkers(0)
+
+	var active, maxActive atomic.Int64
+	treeFn := func(axis Axis, index uint) Tree {
+		n := active.Add(1)
+		for {
+			max := maxActive.Load()
+			if n <= max || maxActive.CompareAndSwap(max, n) {
+				break
+			}
+		}
+		return activeTree{DefaultTree: NewDefaultTree(axis, index).(*DefaultTree), active: &active}
+	}
+	eds, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), NewLeoRSCodec(), treeFn)
+	require.NoError(t, err)
+	_, err = eds.RowRoots()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), maxActive.Load())
+	assert.Equal(t, int64(0), active.Load())
+}
@@ -0,0 +1,77 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrWidthMismatch is returned by Diff and WriteDiff if the squares do not
+// have the same width.
+var ErrWidthMismatch = errors.New("squares have different widths")
+
+func (c CellIndex) String() string {
+	return fmt.Sprintf("(%d, %d)", c.Row, c.Col)
+}
+
+// Diff returns the cells whose shares differ between eds and other, in
+// row-major order. A cell that is missing in only one of the squares differs.
+// Returns an error wrapping ErrWidthMismatch if the squares do not have the
+// same width.
+func (eds *ExtendedDataSquare) Diff(other *ExtendedDataSquare) ([]CellIndex, error) {
+	if eds.width != other.width {
+		return nil, fmt.Errorf("%w: %d and %d", ErrWidthMismatch, eds.width, other.width)
+	}
+	var diff []CellIndex
+	for rowIdx := uint(0); rowIdx < eds.width; rowIdx++ {
+		row, otherRow := eds.Row(rowIdx), other.Row(rowIdx)
+		for colIdx := range row {
+			if (row[colIdx] == nil) != (otherRow[colIdx] == nil) || !bytes.Equal(row[colIdx], otherRow[colIdx]) {
+				diff = append(diff, CellIndex{Row: rowIdx, Col: uint(colIdx)})
+			}
+		}
+	}
+	return diff, nil
+}
+
+// WriteDiff writes the cells that differ between eds and other to w, one per
+// line, with their quadrant and a hex prefix of both shares, e.g.
+//
+//	(0, 3) Q1: 0a1b2c3d4e5f6071... != missing
+//
+// Writes nothing if the squares are equal. See Diff.
+func (eds *ExtendedDataSquare) WriteDiff(w io.Writer, other *ExtendedDataSquare) error {
+	diff, err := eds.Diff(other)
+	if err != nil {
+		return err
+	}
+	for _, c := range diff {
+		q := Q0
+		if c.Col >= eds.originalDataWidth {
+			q++
+		}
+		if c.Row >= eds.originalDataWidth {
+			q += 2
+		}
+		_, err := fmt.Fprintf(w, "%v %v: %s != %s\n", c, q,
+			formatShare(eds.GetCell(c.Row, c.Col)), formatShare(other.GetCell(c.Row, c.Col)))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatShare returns a hex prefix of share, or "missing" if it is nil.
+func formatShare(share []byte) string {
+	const prefixSize = 8
+	if share == nil {
+		return "missing"
+	}
+	if len(share) <= prefixSize {
+		return hex.EncodeToString(share)
+	}
+	return hex.EncodeToString(share[:prefixSize]) + "..."
+}
@@ -0,0 +1,42 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	a := createExampleEds(t, shareSize)
+	b := createExampleEds(t, shareSize)
+
+	diff, err := a.Diff(b)
+	require.NoError(t, err)
+	assert.Empty(t, diff)
+	var buf bytes.Buffer
+	require.NoError(t, a.WriteDiff(&buf, b))
+	assert.Empty(t, buf.String())
+
+	b.clearCell(0, 3)
+	b.clearCell(2, 1)
+	require.NoError(t, b.SetCell(2, 1, fours))
+	a.clearCell(3, 3)
+	b.clearCell(3, 3)
+
+	diff, err = a.Diff(b)
+	require.NoError(t, err)
+	assert.Equal(t, []CellIndex{{Row: 0, Col: 3}, {Row: 2, Col: 1}}, diff)
+
+	require.NoError(t, a.WriteDiff(&buf, b))
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	assert.Regexp(t, `^\(0, 3\) Q1: [0-9a-f]{16}\.\.\. != missing$`, string(lines[0]))
+	assert.Regexp(t, `^\(2, 1\) Q2: [0-9a-f]{16}\.\.\. != 0404040404040404\.\.\.$`, string(lines[1]))
+
+	wider, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	_, err = a.Diff(wider)
+	assert.ErrorIs(t, err, ErrWidthMismatch)
+}
@@ -0,0 +1,102 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Duplication is a minimal codec whose parity shares are copies of the
+// original shares. It supports shares of any non-zero size and encoding and
+// verification are trivial, which makes it useful for tests and embedded use.
+// Unlike Reed-Solomon codecs, it can only recover a share if its original or
+// its copy is present, so it provides far weaker availability guarantees.
+const Duplication = "Duplication"
+
+func init() {
+	registerCodec(Duplication, NewDuplicationCodec())
+}
+
+type DuplicationCodec struct{}
+
+func (c *DuplicationCodec) Encode(data [][]byte) ([][]byte, error) {
+	parity := allocShares(len(data), len(data[0]))
+	if err := c.EncodeInto(data, parity); err != nil {
+		return nil, err
+	}
+	return parity, nil
+}
+
+func (c *DuplicationCodec) EncodeInto(data [][]byte, parityOut [][]byte) error {
+	if len(parityOut) != len(data) {
+		return fmt.Errorf("%w: parityOut has %d shares but data has %d shares", ErrShareCountMismatch, len(parityOut), len(data))
+	}
+	for i, share := range data {
+		if len(parityOut[i]) != len(share) {
+			return fmt.Errorf("%w: parity share %d has size %d, expected %d", ErrShardSizeMismatch, i, len(parityOut[i]), len(share))
+		}
+		copy(parityOut[i], share)
+	}
+	return nil
+}
+
+// Decode recovers every missing share from its copy. Returns an error wrapping
+// ErrTooFewShares if both a share and its copy are missing.
+func (c *DuplicationCodec) Decode(data [][]byte) ([][]byte, error) {
+	half := len(data) / 2
+	shareSize := getShareSize(data)
+	for i := 0; i < half; i++ {
+		original, parity := data[i], data[i+half]
+		for _, share := range [][]byte{original, parity} {
+			if len(share) != 0 && len(share) != shareSize {
+				return nil, fmt.Errorf("%w: share has size %d, expected %d", ErrShardSizeMismatch, len(share), shareSize)
+			}
+		}
+		switch {
+		case len(original) == 0 && len(parity) == 0:
+			return nil, fmt.Errorf("%w: share %d and its copy %d are missing", ErrTooFewShares, i, i+half)
+		case len(original) == 0:
+			data[i] = bytes.Clone(parity)
+		case len(parity) == 0:
+			data[i+half] = bytes.Clone(original)
+		}
+	}
+	return data, nil
+}
+
+// MaxChunks returns the max number of shares this codec supports in a 2D
+// original data square.
+func (c *DuplicationCodec) MaxChunks() int {
+	maxODSWidth := c.MaxAxisShares()
+	return maxODSWidth * maxODSWidth
+}
+
+// MaxAxisShares returns the max number of original shares per row or column
+// this codec supports.
+func (c *DuplicationCodec) MaxAxisShares() int {
+	// The codec itself has no limit, use the same limit as Leopard.
+	return 32768
+}
+
+func (c *DuplicationCodec) Name() string {
+	return Duplication
+}
+
+// SupportedChunkSizes returns the min and max supported share size and the
+// number of bytes supported share sizes must be a multiple of. A max of zero
+// means there is no maximum.
+func (c *DuplicationCodec) SupportedChunkSizes() (minSize, maxSize, multiple int) {
+	return 1, 0, 1
+}
+
+// ValidateChunkSize returns an error if this codec does not support
+// shareSize. Returns nil if shareSize is supported.
+func (c *DuplicationCodec) ValidateChunkSize(shareSize int) error {
+	if shareSize <= 0 {
+		return fmt.Errorf("%w: shareSize %v must be positive", ErrInvalidShareSize, shareSize)
+	}
+	return nil
+}
+
+func NewDuplicationCodec() *DuplicationCodec {
+	return &DuplicationCodec{}
+}
@@ -0,0 +1,89 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// EDSFile is an extended data square whose shares are backed by a
+// memory-mapped file instead of the heap, see OpenEDSFile. It must be closed
+// to unmap the file, after which the square must not be used anymore.
+type EDSFile struct {
+	*ExtendedDataSquare
+	mapped []byte
+}
+
+// WriteFile writes the extended data square to the file at path, creating or
+// truncating it, in the format of WriteTo. The file can be opened with
+// OpenEDSFile.
+func (eds *ExtendedDataSquare) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := eds.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// OpenEDSFile opens an extended data square written by WriteFile. The file is
+// memory-mapped and the shares of the square are slices of the mapping, so
+// they are paged in by the operating system on access instead of being read
+// onto the heap. The getters of the square, such as GetCell and Row, still
+// return copies; use ReadOnly to access the mapped shares directly. The
+// mapping is private, so modifying the square never modifies the file. On
+// platforms without mmap support the file is read into memory instead. A file
+// whose header implies more presence bits or shares than the file holds is
+// rejected before anything is allocated for them.
+func OpenEDSFile(path string) (*EDSFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() == 0 {
+		return nil, fmt.Errorf("%s: %w", path, io.ErrUnexpectedEOF)
+	}
+	mapped, err := mapFile(f, int(info.Size()))
+	if err != nil {
+		return nil, err
+	}
+
+	eds := &ExtendedDataSquare{}
+	r := bytes.NewReader(mapped)
+	err = eds.readStream(r, func(size int) ([]byte, error) {
+		// reject a header claiming more bytes than the file holds before
+		// anything is allocated for them
+		if size > r.Len() {
+			return nil, fmt.Errorf("%w: the header implies %d more bytes, but only %d are left", io.ErrUnexpectedEOF, size, r.Len())
+		}
+		offset := len(mapped) - r.Len()
+		if _, err := r.Seek(int64(size), io.SeekCurrent); err != nil {
+			return nil, err
+		}
+		return mapped[offset : offset+size : offset+size], nil
+	}, defaultDecoder())
+	if err != nil {
+		_ = unmapFile(mapped)
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &EDSFile{ExtendedDataSquare: eds, mapped: mapped}, nil
+}
+
+// Close unmaps the file backing the square.
+func (f *EDSFile) Close() error {
+	if f.mapped == nil {
+		return nil
+	}
+	mapped := f.mapped
+	f.mapped = nil
+	return unmapFile(mapped)
+}
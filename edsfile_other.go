@@ -0,0 +1,23 @@
+//go:build !unix
+
+package rsmt2d
+
+import (
+	"io"
+	"os"
+)
+
+// mapFile reads the first size bytes of f into memory, as mmap is not
+// supported on this platform.
+func mapFile(f *os.File, size int) ([]byte, error) {
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(f, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// unmapFile is a no-op, as mapFile does not map f.
+func unmapFile([]byte) error {
+	return nil
+}
@@ -0,0 +1,69 @@
+package rsmt2d
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEDSFile(t *testing.T) {
+	want, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	wantRoots, err := want.Roots()
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "eds")
+	require.NoError(t, want.WriteFile(path))
+	written, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	f, err := OpenEDSFile(path)
+	require.NoError(t, err)
+	assert.True(t, want.Equals(f.ExtendedDataSquare))
+	roots, err := f.Roots()
+	require.NoError(t, err)
+	assert.Equal(t, wantRoots, roots)
+
+	// the shares are slices of the mapping
+	last := f.ReadOnly().GetCell(f.Width()-1, f.Width()-1)
+	assert.Same(t, &f.mapped[len(f.mapped)-shareSize], &last[0])
+
+	// modifying the square does not modify the file
+	f.clearCell(0, 0)
+	require.NoError(t, f.Repair(wantRoots[:f.Width()], wantRoots[f.Width():]))
+	f.ReadOnly().GetCell(0, 1)[0]++
+	unchanged, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, written, unchanged)
+
+	require.NoError(t, f.Close())
+	require.NoError(t, f.Close())
+
+	t.Run("truncated file", func(t *testing.T) {
+		for _, size := range []int{0, 10, len(written) - 1} {
+			require.NoError(t, os.WriteFile(path, written[:size], 0o600))
+			_, err := OpenEDSFile(path)
+			assert.Error(t, err, "size %d", size)
+		}
+	})
+
+	t.Run("header claiming more than the file holds", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, largeSquareHeader(65536), 0o600))
+		_, err := OpenEDSFile(path)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+		// the presence bits fit, but the shares they mark as present do not
+		require.NoError(t, os.WriteFile(path, written[:len(written)-shareSize], 0o600))
+		_, err = OpenEDSFile(path)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := OpenEDSFile(filepath.Join(t.TempDir(), "missing"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
@@ -0,0 +1,19 @@
+//go:build unix
+
+package rsmt2d
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapFile maps the first size bytes of f into memory. The mapping is private
+// and writable, so that writes to it are never written back to f.
+func mapFile(f *os.File, size int) ([]byte, error) {
+	return syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_PRIVATE)
+}
+
+// unmapFile unmaps a mapping returned by mapFile.
+func unmapFile(mapped []byte) error {
+	return syscall.Munmap(mapped)
+}
@@ -0,0 +1,126 @@
+package rsmt2d
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/klauspost/reedsolomon"
+)
+
+// EncoderCacheStats contains statistics about an encoder cache.
+type EncoderCacheStats struct {
+	// Hits is the number of lookups that found a cached encoder.
+	Hits uint64
+	// Misses is the number of lookups that had to instantiate an encoder.
+	Misses uint64
+	// Evictions is the number of encoders evicted from the cache.
+	Evictions uint64
+	// Entries is the number of encoders currently cached.
+	Entries int
+}
+
+// encoderCache caches reedsolomon encoders by data length. If maxEntries is
+// non-zero, the least recently used encoder is evicted once the cache holds
+// more than maxEntries encoders. The zero value is an empty unbounded cache
+// ready to use. encoderCache is safe for concurrent use and must not be
+// copied after first use.
+type encoderCache struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[int]*list.Element
+	// lru orders the cached encoders from most to least recently used.
+	lru   *list.List
+	stats EncoderCacheStats
+}
+
+type encoderCacheEntry struct {
+	dataLen int
+	enc     reedsolomon.Encoder
+}
+
+// lazyInit initializes the entries of the cache if needed. c.mu must be held.
+func (c *encoderCache) lazyInit() {
+	if c.entries == nil {
+		c.entries = make(map[int]*list.Element)
+		c.lru = list.New()
+	}
+}
+
+// loadOrInit returns the cached encoder for dataLen or instantiates and caches
+// one via newEncoder.
+func (c *encoderCache) loadOrInit(dataLen int, newEncoder func(dataLen int) (reedsolomon.Encoder, error)) (reedsolomon.Encoder, error) {
+	if enc, ok := c.load(dataLen); ok {
+		return enc, nil
+	}
+
+	// Instantiate the encoder without holding the lock as it is costly.
+	enc, err := newEncoder(dataLen)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lazyInit()
+	if elem, ok := c.entries[dataLen]; ok {
+		// another goroutine cached an encoder in the meantime
+		c.lru.MoveToFront(elem)
+		return elem.Value.(*encoderCacheEntry).enc, nil
+	}
+	c.entries[dataLen] = c.lru.PushFront(&encoderCacheEntry{dataLen: dataLen, enc: enc})
+	if c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*encoderCacheEntry).dataLen)
+		c.stats.Evictions++
+	}
+	return enc, nil
+}
+
+// load returns the cached encoder for dataLen, if any, and records a hit or
+// miss.
+func (c *encoderCache) load(dataLen int) (reedsolomon.Encoder, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lazyInit()
+
+	elem, ok := c.entries[dataLen]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	c.stats.Hits++
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*encoderCacheEntry).enc, true
+}
+
+// contains returns true if an encoder for dataLen is cached. It does not
+// affect the statistics or the eviction order.
+func (c *encoderCache) contains(dataLen int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// reading a nil map is fine
+	_, ok := c.entries[dataLen]
+	return ok
+}
+
+// clear removes all cached encoders.
+func (c *encoderCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = nil
+	c.lru = nil
+}
+
+// getStats returns the statistics of the cache.
+func (c *encoderCache) getStats() EncoderCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = len(c.entries)
+	return stats
+}
@@ -0,0 +1,101 @@
+package rsmt2d
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// EventType identifies the kind of an Event.
+type EventType int
+
+const (
+	// EventExtensionStarted is emitted when the erasure extension of an
+	// original data square starts.
+	EventExtensionStarted EventType = iota
+	// EventExtensionFinished is emitted when the erasure extension of an
+	// original data square finishes.
+	EventExtensionFinished
+	// EventAxisRootComputed is emitted when the root of a row or column has
+	// been computed.
+	EventAxisRootComputed
+	// EventAxisDecoded is emitted when a row or column has been decoded during
+	// repair.
+	EventAxisDecoded
+	// EventByzantineAxis is emitted when a row or column is detected to be
+	// Byzantine during repair.
+	EventByzantineAxis
+	// EventRepairFinished is emitted when a repair finishes, successfully or
+	// not.
+	EventRepairFinished
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventExtensionStarted:
+		return "extension started"
+	case EventExtensionFinished:
+		return "extension finished"
+	case EventAxisRootComputed:
+		return "axis root computed"
+	case EventAxisDecoded:
+		return "axis decoded"
+	case EventByzantineAxis:
+		return "byzantine axis"
+	case EventRepairFinished:
+		return "repair finished"
+	default:
+		return fmt.Sprintf("unknown event type: %d", int(t))
+	}
+}
+
+// Event is a structured event describing an operation on a square.
+type Event struct {
+	// Type is the type of the event.
+	Type EventType
+	// Time is the time at which the event was emitted.
+	Time time.Time
+	// Width is the width of the square the event refers to.
+	Width uint
+	// Axis and Index identify the row or column the event refers to. They are
+	// only set for EventAxisRootComputed, EventAxisDecoded and
+	// EventByzantineAxis.
+	Axis  Axis
+	Index uint
+	// Duration is the duration of the operation that finished. It is only set
+	// for EventExtensionFinished, EventAxisRootComputed and
+	// EventRepairFinished.
+	Duration time.Duration
+	// Err is the error the operation finished with, if any.
+	Err error
+}
+
+// EventSink receives events about the processing of squares. Implementations
+// must be safe for concurrent use and should return quickly, as events are
+// delivered synchronously.
+type EventSink interface {
+	HandleEvent(event Event)
+}
+
+// eventSink holds the registered EventSink, if any.
+var eventSink atomic.Pointer[EventSink]
+
+// SetEventSink registers sink to receive events about the processing of all
+// squares. Passing nil disables event delivery.
+func SetEventSink(sink EventSink) {
+	if sink == nil {
+		eventSink.Store(nil)
+		return
+	}
+	eventSink.Store(&sink)
+}
+
+// emitEvent delivers event to the registered EventSink, if any.
+func emitEvent(event Event) {
+	sink := eventSink.Load()
+	if sink == nil {
+		return
+	}
+	event.Time = time.Now()
+	(*sink).HandleEvent(event)
+}
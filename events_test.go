@@ -0,0 +1,83 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSink struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *recordingSink) HandleEvent(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recordingSink) count(eventType EventType) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, event := range r.events {
+		if event.Type == eventType {
+			count++
+		}
+	}
+	return count
+}
+
+func TestEventSink(t *testing.T) {
+	sink := &recordingSink{}
+	SetEventSink(sink)
+	defer SetEventSink(nil)
+
+	eds := createExampleEds(t, shareSize)
+	assert.Equal(t, 1, sink.count(EventExtensionStarted))
+	assert.Equal(t, 1, sink.count(EventExtensionFinished))
+
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+	assert.Equal(t, 8, sink.count(EventAxisRootComputed))
+
+	t.Run("repair", func(t *testing.T) {
+		flattened := eds.Flattened()
+		flattened[0], flattened[1] = nil, nil
+		imported, err := ImportExtendedDataSquare(flattened, NewLeoRSCodec(), NewDefaultTree)
+		require.NoError(t, err)
+
+		require.NoError(t, imported.Repair(rowRoots, colRoots))
+		assert.Equal(t, 1, sink.count(EventAxisDecoded))
+		assert.Equal(t, 1, sink.count(EventRepairFinished))
+		assert.Equal(t, 0, sink.count(EventByzantineAxis))
+	})
+
+	t.Run("byzantine", func(t *testing.T) {
+		flattened := eds.Flattened()
+		flattened[0] = bytes.Repeat([]byte{66}, shareSize)
+		imported, err := ImportExtendedDataSquare(flattened, NewLeoRSCodec(), NewDefaultTree)
+		require.NoError(t, err)
+
+		assert.Error(t, imported.Repair(rowRoots, colRoots))
+		assert.Equal(t, 1, sink.count(EventByzantineAxis))
+		assert.Equal(t, 2, sink.count(EventRepairFinished))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		SetEventSink(nil)
+		createExampleEds(t, shareSize)
+		assert.Equal(t, 1, sink.count(EventExtensionStarted))
+	})
+}
+
+func TestEventTypeString(t *testing.T) {
+	assert.Equal(t, "axis decoded", EventAxisDecoded.String())
+	assert.Equal(t, "unknown event type: 42", EventType(42).String())
+}
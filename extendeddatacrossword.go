@@ -5,6 +5,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync/atomic"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -33,6 +35,12 @@ func (a Axis) String() string {
 	}
 }
 
+// AxisIndex identifies a row or column of an extended data square.
+type AxisIndex struct {
+	Axis  Axis
+	Index uint
+}
+
 // ErrUnrepairableDataSquare is thrown when there is insufficient shares to repair the square.
 var ErrUnrepairableDataSquare = errors.New("failed to solve data square")
 
@@ -50,6 +58,12 @@ type ErrByzantineData struct {
 	// individual inclusion is guaranteed to be provable by the full node (i.e.
 	// shares usable in a bad encoding fraud proof). Missing shares are nil.
 	Shares [][]byte
+	// Proofs contain, for each non-nil share, its inclusion proof against the
+	// expected root of the orthogonal axis, i.e. of its column for a Byzantine
+	// row. They are set by Repair if the Tree implements ProvingTree. The
+	// proof of a share is nil if its orthogonal axis is incomplete or does not
+	// match its expected root.
+	Proofs []*ShareProof
 }
 
 func (e *ErrByzantineData) Error() string {
@@ -57,6 +71,108 @@ func (e *ErrByzantineData) Error() string {
 		"byzantine %s: %d", e.Axis, e.Index)
 }
 
+// ErrByzantineAxes is returned by Repair with WithAllByzantineAxes when one
+// or more complete rows or columns fail verification. It holds an
+// ErrByzantineData for each of them, ordered by index, and unwraps to all of
+// them, so errors.As with an *ErrByzantineData yields the first Byzantine row,
+// or the first Byzantine column if all rows are valid.
+type ErrByzantineAxes struct {
+	// Rows are the Byzantine rows.
+	Rows []*ErrByzantineData
+	// Cols are the Byzantine columns.
+	Cols []*ErrByzantineData
+}
+
+func (e *ErrByzantineAxes) Error() string {
+	indices := func(errs []*ErrByzantineData) []uint {
+		idx := make([]uint, len(errs))
+		for i, err := range errs {
+			idx[i] = err.Index
+		}
+		return idx
+	}
+	return fmt.Sprintf("byzantine rows: %v, cols: %v", indices(e.Rows), indices(e.Cols))
+}
+
+func (e *ErrByzantineAxes) Unwrap() []error {
+	errs := make([]error, 0, len(e.Rows)+len(e.Cols))
+	for _, err := range e.Rows {
+		errs = append(errs, err)
+	}
+	for _, err := range e.Cols {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// RepairOption configures the behavior of Repair.
+type RepairOption func(*repairConfig)
+
+type repairConfig struct {
+	// failFast is true if Repair should return ErrUnrepairableDataSquare
+	// before attempting any repair if the square is unrepairable.
+	failFast bool
+	// onProgress, if set, is called whenever shares have been repaired.
+	onProgress func(repairedShares, totalMissing int)
+	// stats, if set, is filled with statistics about the repair.
+	stats *RepairStats
+	// allByzantineAxes is true if the sanity check should report every
+	// Byzantine axis instead of only the first one.
+	allByzantineAxes bool
+}
+
+// RepairStats holds statistics about a call to Repair.
+type RepairStats struct {
+	// Iterations is the number of passes over all rows and columns.
+	Iterations int
+	// RowsSolved is the number of rows that were decoded.
+	RowsSolved int
+	// ColsSolved is the number of columns that were decoded.
+	ColsSolved int
+	// SharesDecoded is the number of missing shares that were repaired.
+	SharesDecoded int
+}
+
+// WithFailFast makes Repair analyze the pattern of missing shares before
+// attempting any repair, and return ErrUnrepairableDataSquare right away if it
+// makes the EDS unrepairable. This avoids decoding and hashing axes in futile
+// iterations, but in that case the EDS is left unmodified instead of being
+// partially repaired, and Byzantine rows or columns that could have been
+// decoded are not detected.
+func WithFailFast() RepairOption {
+	return func(cfg *repairConfig) {
+		cfg.failFast = true
+	}
+}
+
+// WithProgress makes Repair call onProgress after each row or column it
+// decodes, with the number of shares repaired so far and the number of shares
+// that were missing when the repair started.
+func WithProgress(onProgress func(repairedShares, totalMissing int)) RepairOption {
+	return func(cfg *repairConfig) {
+		cfg.onProgress = onProgress
+	}
+}
+
+// WithRepairStats makes Repair fill stats with statistics about the repair,
+// including when the repair fails.
+func WithRepairStats(stats *RepairStats) RepairOption {
+	return func(cfg *repairConfig) {
+		cfg.stats = stats
+	}
+}
+
+// WithAllByzantineAxes makes Repair verify every complete row and column
+// before the repair, and return an ErrByzantineAxes holding all of those that
+// fail verification instead of only the first one. Rows or columns found to be
+// Byzantine once decoded are still returned as a single ErrByzantineData, as
+// the repair can not continue past them.
+func WithAllByzantineAxes() RepairOption {
+	return func(cfg *repairConfig) {
+		cfg.allByzantineAxes = true
+	}
+}
+
 // Repair attempts to repair an incomplete extended data square (EDS). The
 // parameters rowRoots and colRoots are the expected Merkle roots for each row
 // and column. rowRoots and colRoots are used to verify that a repaired row or
@@ -70,279 +186,307 @@ func (e *ErrByzantineData) Error() string {
 // complete. If repairing is unsuccessful, the EDS will be the most-repaired
 // prior to the Byzantine row or column being repaired, and the Byzantine row
 // or column prior to repair is returned in the error with missing shares as
-// nil.
+// nil. If the EDS can not be completely repaired, ErrUnrepairableDataSquare is
+// returned and the EDS is the most-repaired, unless WithFailFast is passed.
 func (eds *ExtendedDataSquare) Repair(
 	rowRoots [][]byte,
 	colRoots [][]byte,
+	opts ...RepairOption,
 ) error {
-	err := eds.preRepairSanityCheck(rowRoots, colRoots)
-	if err != nil {
-		return err
-	}
-
-	return eds.solveCrossword(rowRoots, colRoots)
+	return eds.RepairWithContext(context.Background(), rowRoots, colRoots, opts...)
 }
 
-// solveCrossword attempts to iteratively repair an EDS.
-func (eds *ExtendedDataSquare) solveCrossword(
+// RepairWithContext is like Repair but aborts the repair and returns
+// ctx.Err() if ctx is done before the repair finishes. The context is checked
+// by the sanity check of complete axes and before each row and column is
+// solved, so the EDS is left partially repaired when the repair is aborted.
+func (eds *ExtendedDataSquare) RepairWithContext(
+	ctx context.Context,
 	rowRoots [][]byte,
 	colRoots [][]byte,
-) error {
-	// Keep repeating until the square is solved
-	for {
-		// Track if the entire square is completely solved
-		solved := true
-		// Track if a single iteration of this loop made progress
-		progressMade := false
-
-		// Loop through every row and column, attempt to rebuild each row or column if incomplete
-		for i := 0; i < int(eds.width); i++ {
-			solvedRow, progressMadeRow, err := eds.solveCrosswordRow(i, rowRoots, colRoots)
-			if err != nil {
-				return err
-			}
-			solvedCol, progressMadeCol, err := eds.solveCrosswordCol(i, rowRoots, colRoots)
-			if err != nil {
-				return err
-			}
+	opts ...RepairOption,
+) (err error) {
+	var cfg repairConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 
-			solved = solved && solvedRow && solvedCol
-			progressMade = progressMade || progressMadeRow || progressMadeCol
+	start := time.Now()
+	defer func() {
+		var byzAxes *ErrByzantineAxes
+		var byzErr *ErrByzantineData
+		if errors.As(err, &byzAxes) {
+			for _, byzErr := range append(byzAxes.Rows, byzAxes.Cols...) {
+				byzErr.Proofs = eds.byzantineProofs(byzErr, rowRoots, colRoots)
+				emitEvent(Event{Type: EventByzantineAxis, Width: eds.width, Axis: byzErr.Axis, Index: byzErr.Index, Err: err})
+			}
+		} else if errors.As(err, &byzErr) {
+			byzErr.Proofs = eds.byzantineProofs(byzErr, rowRoots, colRoots)
+			emitEvent(Event{Type: EventByzantineAxis, Width: eds.width, Axis: byzErr.Axis, Index: byzErr.Index, Err: err})
 		}
+		emitEvent(Event{Type: EventRepairFinished, Width: eds.width, Duration: time.Since(start), Err: err})
+	}()
 
-		if solved {
-			break
-		}
-		if !progressMade {
-			return ErrUnrepairableDataSquare
-		}
+	err = eds.preRepairSanityCheck(ctx, rowRoots, colRoots, cfg.allByzantineAxes)
+	if err != nil {
+		return err
 	}
 
+	// Avoid decoding and hashing axes in futile iterations if the pattern of
+	// missing shares makes the square unrepairable.
+	if cfg.failFast && !analyzeRepairability(eds.presenceMatrix()).Repairable {
+		return ErrUnrepairableDataSquare
+	}
+
+	err = eds.solveCrossword(ctx, rowRoots, colRoots, cfg)
+	if err != nil {
+		return err
+	}
+
+	// Every axis of the repaired square has been verified against its
+	// expected root, either by the sanity check or when it was completed, so
+	// the expected roots are cached instead of being recomputed on demand.
+	eds.rowRoots = deepCopy(rowRoots)
+	eds.colRoots = deepCopy(colRoots)
 	return nil
 }
 
-// solveCrosswordRow attempts to repair a single row.
-// Returns
-// - if the row is solved (i.e. complete)
-// - if the row was previously unsolved and now solved
-// - an error if the repair is unsuccessful
-func (eds *ExtendedDataSquare) solveCrosswordRow(
-	rowIdx int,
+// RepairWithReport is like Repair, but also returns the cells that were
+// reconstructed by the repair, as opposed to those supplied by the caller, in
+// row-major order. The reconstructed cells are returned including when an
+// error is returned, in which case they are the cells filled in by the
+// partial repair.
+func (eds *ExtendedDataSquare) RepairWithReport(
 	rowRoots [][]byte,
 	colRoots [][]byte,
-) (bool, bool, error) {
-	isComplete := noMissingData(eds.row(uint(rowIdx)), noShareInsertion)
-	if isComplete {
-		return true, false, nil
+	opts ...RepairOption,
+) ([]CellIndex, error) {
+	supplied := eds.presenceMatrix()
+	err := eds.Repair(rowRoots, colRoots, opts...)
+
+	var repaired []CellIndex
+	for rowIdx := uint(0); rowIdx < eds.width; rowIdx++ {
+		for colIdx, share := range eds.row(rowIdx) {
+			if share != nil && !supplied.get(rowIdx, uint(colIdx)) {
+				repaired = append(repaired, CellIndex{Row: rowIdx, Col: uint(colIdx)})
+			}
+		}
 	}
+	return repaired, err
+}
 
-	// Prepare shares
-	shares := make([][]byte, eds.width)
-	copy(shares, eds.row(uint(rowIdx)))
+// RepairAxes is like Repair, but only attempts to decode the given rows and
+// columns, e.g. the few rows containing a namespace of interest. The axes are
+// decoded repeatedly until no further progress can be made, and the cells of
+// the given axes that are still missing are returned. Unlike Repair, no
+// sanity check of the whole square is done: only the decoded axes and the
+// axes they complete are verified against their roots.
+func (eds *ExtendedDataSquare) RepairAxes(
+	axes []AxisIndex,
+	rowRoots [][]byte,
+	colRoots [][]byte,
+) ([]CellIndex, error) {
+	for _, a := range axes {
+		if a.Index >= eds.width {
+			return nil, fmt.Errorf("%w: %s index %d for width %d", ErrOutOfBounds, a.Axis, a.Index, eds.width)
+		}
+	}
 
-	// Attempt rebuild the row
-	rebuiltShares, isDecoded, err := eds.rebuildShares(shares)
+	solver := NewSolver(&edsCrosswordSquare{
+		eds:      eds,
+		rowRoots: rowRoots,
+		colRoots: colRoots,
+	})
+	err := solver.solveAxes(axes)
 	if err != nil {
-		return false, false, err
-	}
-	if !isDecoded {
-		return false, false, nil
+		return nil, err
 	}
 
-	// Check that rebuilt shares matches appropriate root
-	err = eds.verifyAgainstRowRoots(rowRoots, uint(rowIdx), rebuiltShares, noShareInsertion, nil)
-	if err != nil {
-		var byzErr *ErrByzantineData
-		if errors.As(err, &byzErr) {
-			byzErr.Shares = shares
+	var missing []CellIndex
+	seen := make(map[CellIndex]bool)
+	for _, a := range axes {
+		for i, share := range solver.square.GetAxis(a.Axis, a.Index) {
+			cell := CellIndex{Row: a.Index, Col: uint(i)}
+			if a.Axis == Col {
+				cell = CellIndex{Row: uint(i), Col: a.Index}
+			}
+			if share == nil && !seen[cell] {
+				seen[cell] = true
+				missing = append(missing, cell)
+			}
 		}
-		return false, false, err
 	}
+	return missing, nil
+}
 
-	// Check that newly completed orthogonal vectors match their new merkle roots
-	for colIdx := 0; colIdx < int(eds.width); colIdx++ {
-		col := eds.col(uint(colIdx))
-		if col[rowIdx] != nil {
-			continue // not newly completed
-		}
-		if noMissingData(col, rowIdx) { // completed
-			err := eds.verifyAgainstColRoots(colRoots, uint(colIdx), col, rowIdx, rebuiltShares[colIdx])
-			if err != nil {
-				var byzErr *ErrByzantineData
-				if errors.As(err, &byzErr) {
-					byzErr.Shares = shares
-				}
-				return false, false, err
-			}
+// byzantineProofs returns the inclusion proof of each non-nil share of byzErr
+// against the expected root of its orthogonal axis, see
+// ErrByzantineData.Proofs. Returns nil if the Tree does not implement
+// ProvingTree.
+func (eds *ExtendedDataSquare) byzantineProofs(
+	byzErr *ErrByzantineData,
+	rowRoots [][]byte,
+	colRoots [][]byte,
+) []*ShareProof {
+	orthogonal, roots := Col, colRoots
+	if byzErr.Axis == Col {
+		orthogonal, roots = Row, rowRoots
+	}
 
-			if eds.verifyEncoding(col, rowIdx, rebuiltShares[colIdx]) != nil {
-				return false, false, &ErrByzantineData{Col, uint(colIdx), col}
-			}
+	proofs := make([]*ShareProof, len(byzErr.Shares))
+	for i, share := range byzErr.Shares {
+		if share == nil {
+			continue
+		}
+		rowIdx, colIdx := byzErr.Index, uint(i)
+		if byzErr.Axis == Col {
+			rowIdx, colIdx = colIdx, rowIdx
 		}
+		proof, err := eds.proveShare(orthogonal, rowIdx, colIdx)
+		if errors.Is(err, ErrNotProvingTree) {
+			return nil
+		}
+		// the orthogonal axis may be incomplete, or contain the share of
+		// another Byzantine axis
+		if err != nil || !bytes.Equal(proof.Root, roots[i]) || !bytes.Equal(proof.Share, share) {
+			continue
+		}
+		proofs[i] = &proof
 	}
+	return proofs
+}
 
-	// Insert rebuilt shares into square.
-	for colIdx, s := range rebuiltShares {
-		cellToSet := eds.GetCell(uint(rowIdx), uint(colIdx))
-		if cellToSet == nil {
-			err := eds.SetCell(uint(rowIdx), uint(colIdx), s)
-			if err != nil {
-				return false, false, err
+// presenceMatrix returns a bitMatrix in which the bits of all non-nil shares
+// are set.
+func (eds *ExtendedDataSquare) presenceMatrix() bitMatrix {
+	bm := newBitMatrix(eds.width)
+	for rowIdx := uint(0); rowIdx < eds.width; rowIdx++ {
+		for colIdx, share := range eds.row(rowIdx) {
+			if share != nil {
+				bm.set(rowIdx, uint(colIdx))
 			}
 		}
 	}
-
-	return true, true, nil
+	return bm
 }
 
-// solveCrosswordCol attempts to repair a single column.
-// Returns
-// - if the column is solved (i.e. complete)
-// - if the column was previously unsolved and now solved
-// - an error if the repair is unsuccessful
-func (eds *ExtendedDataSquare) solveCrosswordCol(
-	colIdx int,
+// solveCrossword attempts to iteratively repair an EDS.
+func (eds *ExtendedDataSquare) solveCrossword(
+	ctx context.Context,
 	rowRoots [][]byte,
 	colRoots [][]byte,
-) (bool, bool, error) {
-	isComplete := noMissingData(eds.col(uint(colIdx)), noShareInsertion)
-	if isComplete {
-		return true, false, nil
-	}
+	cfg repairConfig,
+) error {
+	solver := NewSolver(&edsCrosswordSquare{
+		eds:      eds,
+		rowRoots: rowRoots,
+		colRoots: colRoots,
+	})
+	solver.onProgress = cfg.onProgress
+	solver.stats = cfg.stats
+	return solver.SolveWithContext(ctx)
+}
 
-	// Prepare shares
-	shares := make([][]byte, eds.width)
-	copy(shares, eds.col(uint(colIdx)))
+// edsCrosswordSquare adapts an ExtendedDataSquare and its expected roots to
+// the CrosswordSquare interface.
+type edsCrosswordSquare struct {
+	eds      *ExtendedDataSquare
+	rowRoots [][]byte
+	colRoots [][]byte
+}
 
-	// Attempt rebuild
-	rebuiltShares, isDecoded, err := eds.rebuildShares(shares)
-	if err != nil {
-		return false, false, err
-	}
-	if !isDecoded {
-		return false, false, nil
-	}
+var _ CrosswordSquare = &edsCrosswordSquare{}
 
-	// Check that rebuilt shares matches appropriate root
-	err = eds.verifyAgainstColRoots(colRoots, uint(colIdx), rebuiltShares, noShareInsertion, nil)
-	if err != nil {
-		var byzErr *ErrByzantineData
-		if errors.As(err, &byzErr) {
-			byzErr.Shares = shares
-		}
-		return false, false, err
-	}
-
-	// Check that newly completed orthogonal vectors match their new merkle roots
-	for rowIdx := 0; rowIdx < int(eds.width); rowIdx++ {
-		row := eds.row(uint(rowIdx))
-		if row[colIdx] != nil {
-			continue // not newly completed
-		}
-		if noMissingData(row, colIdx) { // completed
-			err := eds.verifyAgainstRowRoots(rowRoots, uint(rowIdx), row, colIdx, rebuiltShares[rowIdx])
-			if err != nil {
-				var byzErr *ErrByzantineData
-				if errors.As(err, &byzErr) {
-					byzErr.Shares = shares
-				}
-				return false, false, err
-			}
+func (s *edsCrosswordSquare) Width() uint {
+	return s.eds.width
+}
 
-			if eds.verifyEncoding(row, colIdx, rebuiltShares[rowIdx]) != nil {
-				return false, false, &ErrByzantineData{Row, uint(rowIdx), row}
-			}
-		}
+func (s *edsCrosswordSquare) GetAxis(axis Axis, axisIdx uint) [][]byte {
+	if axis == Row {
+		return s.eds.row(axisIdx)
 	}
+	return s.eds.col(axisIdx)
+}
 
-	// Insert rebuilt shares into square.
-	for rowIdx, s := range rebuiltShares {
-		cellToSet := eds.GetCell(uint(rowIdx), uint(colIdx))
-		if cellToSet == nil {
-			err := eds.SetCell(uint(rowIdx), uint(colIdx), s)
-			if err != nil {
-				return false, false, err
-			}
-		}
+func (s *edsCrosswordSquare) SetShare(rowIdx uint, colIdx uint, share []byte) error {
+	return s.eds.SetCell(rowIdx, colIdx, share)
+}
+
+func (s *edsCrosswordSquare) VerifyAxisRoot(axis Axis, axisIdx uint, shares [][]byte) error {
+	if axis == Row {
+		return s.eds.verifyAgainstRowRoots(s.rowRoots, axisIdx, shares)
 	}
+	return s.eds.verifyAgainstColRoots(s.colRoots, axisIdx, shares)
+}
 
-	return true, true, nil
+func (s *edsCrosswordSquare) Codec() Codec {
+	return s.eds.codec
 }
 
-// rebuildShares attempts to rebuild a row or column of shares.
+// rebuildShares attempts to rebuild a row or column of shares with codec.
 // Returns
 // 1. An entire row or column of shares so original + parity shares.
 // 2. Whether the original shares could be decoded from the shares parameter.
 // 3. [Optional] an error.
-func (eds *ExtendedDataSquare) rebuildShares(
-	shares [][]byte,
-) ([][]byte, bool, error) {
-	rebuiltShares, err := eds.codec.Decode(shares)
+func rebuildShares(codec Codec, shares [][]byte) ([][]byte, bool, error) {
+	rebuiltShares, err := codec.Decode(shares)
 	if err != nil {
-		// Decode was unsuccessful but don't propagate the error because that
-		// would halt the progress of solveCrosswordRow or solveCrosswordCol.
+		if isDecodeFault(err) {
+			return nil, false, err
+		}
+		// Decode was unsuccessful due to insufficient data but don't propagate
+		// the error because that would halt the progress of the solver.
 		return nil, false, nil
 	}
 
 	return rebuiltShares, true, nil
 }
 
+// isDecodeFault returns true if err is a decoding error that is not caused by
+// insufficient data, i.e. one that wraps ErrShardSizeMismatch or
+// ErrCodecInternal.
+func isDecodeFault(err error) bool {
+	return errors.Is(err, ErrShardSizeMismatch) || errors.Is(err, ErrCodecInternal)
+}
+
+// verifyAgainstRowRoots checks that the shares of row index `rowIdx` match their expected row root available in `rowRoots`.
+// Returns a ErrByzantineData error if the computed root does not match the expected root or if the root computation fails.
 func (eds *ExtendedDataSquare) verifyAgainstRowRoots(
 	rowRoots [][]byte,
 	rowIdx uint,
-	oldShares [][]byte,
-	rebuiltIndex int,
-	rebuiltShare []byte,
+	shares [][]byte,
 ) error {
-	var root []byte
-	var err error
-	if rebuiltIndex < 0 || rebuiltShare == nil {
-		root, err = eds.computeSharesRoot(oldShares, Row, rowIdx)
-	} else {
-		root, err = eds.computeSharesRootWithRebuiltShare(oldShares, Row, rowIdx, rebuiltIndex, rebuiltShare)
-	}
+	root, err := eds.computeSharesRoot(shares, Row, rowIdx)
 	if err != nil {
 		// any error during the computation of the root is considered byzantine
 		// the shares are set to nil, as the caller will populate them
-		return &ErrByzantineData{Row, rowIdx, nil}
+		return &ErrByzantineData{Row, rowIdx, nil, nil}
 	}
 
 	if !bytes.Equal(root, rowRoots[rowIdx]) {
 		// the shares are set to nil, as the caller will populate them
-		return &ErrByzantineData{Row, rowIdx, nil}
+		return &ErrByzantineData{Row, rowIdx, nil, nil}
 	}
 
 	return nil
 }
 
-// verifyAgainstColRoots checks that the shares of column index `c` match their expected column root available in `colRoots`.
-// `colRoots` is a slice of the expected roots of the columns of the `eds`.
-// `shares` is a slice of the shares of the column index `c` of the `eds`.
-// `rebuiltIndex` is the index of the share that was rebuilt, if any.
-// `rebuiltShare` is the rebuilt share, if any.
+// verifyAgainstColRoots checks that the shares of column index `colIdx` match their expected column root available in `colRoots`.
 // Returns a ErrByzantineData error if the computed root does not match the expected root or if the root computation fails.
 func (eds *ExtendedDataSquare) verifyAgainstColRoots(
 	colRoots [][]byte,
 	colIdx uint,
 	shares [][]byte,
-	rebuiltIndex int,
-	rebuiltShare []byte,
 ) error {
-	var root []byte
-	var err error
-	if rebuiltIndex < 0 || rebuiltShare == nil {
-		root, err = eds.computeSharesRoot(shares, Col, colIdx)
-	} else {
-		root, err = eds.computeSharesRootWithRebuiltShare(shares, Col, colIdx, rebuiltIndex, rebuiltShare)
-	}
+	root, err := eds.computeSharesRoot(shares, Col, colIdx)
 	if err != nil {
 		// the shares are set to nil, as the caller will populate them
-		return &ErrByzantineData{Col, colIdx, nil}
+		return &ErrByzantineData{Col, colIdx, nil, nil}
 	}
 
 	if !bytes.Equal(root, colRoots[colIdx]) {
 		// the shares are set to nil, as the caller will populate them
-		return &ErrByzantineData{Col, colIdx, nil}
+		return &ErrByzantineData{Col, colIdx, nil, nil}
 	}
 
 	return nil
@@ -351,36 +495,72 @@ func (eds *ExtendedDataSquare) verifyAgainstColRoots(
 // preRepairSanityCheck returns an error if any row or column in the EDS is
 // complete and the computed Merkle root for that row or column doesn't match
 // the given root in rowRoots or colRoots.
+//
+// The checks run concurrently, but the reported ErrByzantineData is always the
+// one of the lowest-index byzantine axis, with a row taking precedence over
+// the column of the same index. This keeps the resulting fraud proof the same
+// on every node that checks the same square. If all is true, every axis is
+// checked and an ErrByzantineAxes holding all Byzantine axes is returned.
 func (eds *ExtendedDataSquare) preRepairSanityCheck(
+	ctx context.Context,
 	rowRoots [][]byte,
 	colRoots [][]byte,
+	all bool,
 ) error {
-	errs, _ := errgroup.WithContext(context.Background())
+	var errs errgroup.Group
+	// results holds the outcome of each check, ordered by axis index, then by
+	// axis and then by check
+	results := make([]error, 4*eds.width)
+	// firstByzantine is the lowest index into results of a failed check.
+	// Checks after it can no longer change the outcome and are skipped.
+	var firstByzantine atomic.Int64
+	firstByzantine.Store(int64(len(results)))
+	check := func(slot int, verify func() error) {
+		errs.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !all && int64(slot) > firstByzantine.Load() {
+				return nil
+			}
+			if err := verify(); err != nil {
+				results[slot] = err
+				for {
+					first := firstByzantine.Load()
+					if int64(slot) >= first || firstByzantine.CompareAndSwap(first, int64(slot)) {
+						break
+					}
+				}
+			}
+			return nil
+		})
+	}
 
 	for i := uint(0); i < eds.width; i++ {
 		i := i
+		slot := 4 * int(i)
 
 		rowIsComplete := noMissingData(eds.row(i), noShareInsertion)
 		// if there's no missing data in this row
 		if rowIsComplete {
-			errs.Go(func() error {
+			check(slot, func() error {
 				// ensure that the roots are equal
 				rowRoot, err := eds.getRowRoot(i)
 				if err != nil {
 					// any error regarding the root calculation signifies an issue in the shares e.g., out of order shares
 					// therefore, it should be treated as byzantine data
-					return &ErrByzantineData{Row, i, eds.row(i)}
+					return &ErrByzantineData{Row, i, eds.row(i), nil}
 				}
 				if !bytes.Equal(rowRoots[i], rowRoot) {
 					// if the roots are not equal, then the data is byzantine
-					return &ErrByzantineData{Row, i, eds.row(i)}
+					return &ErrByzantineData{Row, i, eds.row(i), nil}
 				}
 				return nil
 			})
-			errs.Go(func() error {
-				err := eds.verifyEncoding(eds.row(i), noShareInsertion, nil)
+			check(slot+1, func() error {
+				err := VerifyAxisEncoding(eds.row(i), eds.codec)
 				if err != nil {
-					return &ErrByzantineData{Row, i, eds.row(i)}
+					return &ErrByzantineData{Row, i, eds.row(i), nil}
 				}
 				return nil
 			})
@@ -389,31 +569,68 @@ func (eds *ExtendedDataSquare) preRepairSanityCheck(
 		colIsComplete := noMissingData(eds.col(i), noShareInsertion)
 		// if there's no missing data in this col
 		if colIsComplete {
-			errs.Go(func() error {
+			check(slot+2, func() error {
 				// ensure that the roots are equal
 				colRoot, err := eds.getColRoot(i)
 				if err != nil {
 					// any error regarding the root calculation signifies an issue in the shares e.g., out of order shares
 					// therefore, it should be treated as byzantine data
-					return &ErrByzantineData{Col, i, eds.col(i)}
+					return &ErrByzantineData{Col, i, eds.col(i), nil}
 				}
 				if !bytes.Equal(colRoots[i], colRoot) {
 					// if the roots are not equal, then the data is byzantine
-					return &ErrByzantineData{Col, i, eds.col(i)}
+					return &ErrByzantineData{Col, i, eds.col(i), nil}
 				}
 				return nil
 			})
-			errs.Go(func() error {
-				err := eds.verifyEncoding(eds.col(i), noShareInsertion, nil)
+			check(slot+3, func() error {
+				err := VerifyAxisEncoding(eds.col(i), eds.codec)
 				if err != nil {
-					return &ErrByzantineData{Col, i, eds.col(i)}
+					return &ErrByzantineData{Col, i, eds.col(i), nil}
 				}
 				return nil
 			})
 		}
 	}
 
-	return errs.Wait()
+	if err := errs.Wait(); err != nil {
+		return err
+	}
+	if all {
+		return collectByzantineAxes(results)
+	}
+	for _, err := range results {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectByzantineAxes returns an ErrByzantineAxes holding the first failed
+// check of each axis in the results of preRepairSanityCheck, or nil if no
+// check failed.
+func collectByzantineAxes(results []error) error {
+	var byzAxes ErrByzantineAxes
+	for slot := 0; slot < len(results); slot += 2 {
+		err := results[slot]
+		if err == nil {
+			err = results[slot+1]
+		}
+		if err == nil {
+			continue
+		}
+		byzErr := err.(*ErrByzantineData)
+		if byzErr.Axis == Row {
+			byzAxes.Rows = append(byzAxes.Rows, byzErr)
+		} else {
+			byzAxes.Cols = append(byzAxes.Cols, byzErr)
+		}
+	}
+	if len(byzAxes.Rows) == 0 && len(byzAxes.Cols) == 0 {
+		return nil
+	}
+	return &byzAxes
 }
 
 func noMissingData(input [][]byte, rebuiltIndex int) bool {
@@ -430,61 +647,86 @@ func noMissingData(input [][]byte, rebuiltIndex int) bool {
 
 // computeSharesRoot calculates the root of the shares for the specified axis (`i`th column or row).
 func (eds *ExtendedDataSquare) computeSharesRoot(shares [][]byte, axis Axis, i uint) ([]byte, error) {
-	tree := eds.createTreeFn(axis, i)
+	tree := eds.newTree(axis, i)
+	defer releaseTree(tree)
 	for _, d := range shares {
 		err := tree.Push(d)
 		if err != nil {
 			return nil, err
 		}
 	}
-	return tree.Root()
+	return consumeRoot(tree)
 }
 
-// computeSharesRootWithRebuiltShare computes the root of the shares with the rebuilt share `rebuiltShare` at the specified index `rebuiltIndex`.
-func (eds *ExtendedDataSquare) computeSharesRootWithRebuiltShare(shares [][]byte, axis Axis, i uint, rebuiltIndex int, rebuiltShare []byte) ([]byte, error) {
-	tree := eds.createTreeFn(axis, i)
-	for _, d := range shares[:rebuiltIndex] {
-		err := tree.Push(d)
-		if err != nil {
-			return nil, err
-		}
-	}
+// ErrParityMismatch is returned by VerifyAxisEncoding when the parity shares
+// of a row or column do not match the encoding of its original shares.
+var ErrParityMismatch = errors.New("parity data does not match encoded data")
 
-	err := tree.Push(rebuiltShare)
+// VerifyAxisEncoding checks the erasure coding of the shares of a complete row
+// or column, i.e. that the second half of shares is the parity of the first
+// half according to codec. Returns ErrParityMismatch if it is not.
+func VerifyAxisEncoding(shares [][]byte, codec Codec) error {
+	if len(shares)%2 != 0 {
+		return fmt.Errorf("%w: axis width %d must be even", ErrUnsupportedWidth, len(shares))
+	}
+	if !isComplete(shares) {
+		return fmt.Errorf("%w: can not verify its encoding", ErrIncompleteAxis)
+	}
+	half := len(shares) / 2
+	ok, err := VerifyEncoding(codec, shares[:half], shares[half:])
 	if err != nil {
-		return nil, err
+		return err
 	}
+	if !ok {
+		return ErrParityMismatch
+	}
+	return nil
+}
 
-	for _, d := range shares[rebuiltIndex+1:] {
-		err := tree.Push(d)
-		if err != nil {
-			return nil, err
-		}
+// VerifyAxisAgainstRoot checks that the shares of a complete row or column
+// match its expected root, by pushing them to a tree created by
+// treeCreatorFn for the given axis and index. Returns an error wrapping
+// ErrRootMismatch if they do not, or the error of the tree if the shares can
+// not be pushed to it.
+func VerifyAxisAgainstRoot(shares [][]byte, root []byte, treeCreatorFn TreeConstructorFn, axis Axis, idx uint) error {
+	if !isComplete(shares) {
+		return fmt.Errorf("%w: can not verify %s %d", ErrIncompleteAxis, axis, idx)
+	}
+	info := SquareInfo{Width: uint(len(shares)), ShareSize: uint(getShareSize(shares))}
+	computed, err := axisRoot(newSquareTree(treeCreatorFn, axis, idx, info), shares)
+	if err != nil {
+		return err
 	}
-	return tree.Root()
+	if !bytes.Equal(computed, root) {
+		return fmt.Errorf("%w: %s %d", ErrRootMismatch, axis, idx)
+	}
+	return nil
 }
 
-// verifyEncoding checks the Reed-Solomon encoding of the provided data.
-func (eds *ExtendedDataSquare) verifyEncoding(data [][]byte, rebuiltIndex int, rebuiltShare []byte) error {
-	if rebuiltShare != nil && rebuiltIndex >= 0 {
-		data[rebuiltIndex] = rebuiltShare
-		defer func() {
-			// revert the change to the data slice after the verification
-			data[rebuiltIndex] = nil
-		}()
+// VerifyEncoding returns true if parity are the parity shares of original
+// according to codec, e.g. to verify a bad encoding fraud proof. It uses the
+// Verify method of codecs implementing Verifier, and otherwise re-encodes
+// original and compares the result with parity.
+func VerifyEncoding(codec Codec, original, parity [][]byte) (bool, error) {
+	if len(original) != len(parity) {
+		return false, fmt.Errorf("%w: got %d parity shares for %d original shares", ErrShareCountMismatch, len(parity), len(original))
+	}
+	if verifier, ok := codec.(Verifier); ok {
+		return verifier.Verify(original, parity)
 	}
 
-	half := len(data) / 2
-	original := data[:half]
-	parity, err := eds.codec.Encode(original)
+	scratch := scratchSharesPool.Get().(*scratchShares)
+	defer scratchSharesPool.Put(scratch)
+	encoded := scratch.get(len(original), len(original[0]))
+	err := codec.EncodeInto(original, encoded)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	for i := half; i < len(data); i++ {
-		if !bytes.Equal(data[i], parity[i-half]) {
-			return errors.New("parity data does not match encoded data")
+	for i := range parity {
+		if !bytes.Equal(parity[i], encoded[i]) {
+			return false, nil
 		}
 	}
-	return nil
+	return true, nil
 }
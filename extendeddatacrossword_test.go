@@ -2,10 +2,12 @@ package rsmt2d
 
 import (
 	"bytes"
+	"context"
 	crand "crypto/rand"
 	"errors"
 	"fmt"
 	"math/rand"
+	"sync/atomic"
 	"testing"
 
 	"github.com/celestiaorg/nmt"
@@ -59,6 +61,101 @@ func TestRepairExtendedDataSquare(t *testing.T) {
 		}
 	})
 
+	t.Run("caches the verified roots", func(t *testing.T) {
+		flattened := original.Flattened()
+		flattened[0], flattened[5], flattened[10], flattened[15] = nil, nil, nil, nil
+
+		var created atomic.Int64
+		treeFn := func(axis Axis, index uint) Tree {
+			created.Add(1)
+			return NewDefaultTree(axis, index)
+		}
+		eds, err := ImportExtendedDataSquare(flattened, codec, treeFn)
+		require.NoError(t, err)
+		require.NoError(t, eds.Repair(rowRoots, colRoots))
+
+		created.Store(0)
+		gotRowRoots, err := eds.RowRoots()
+		require.NoError(t, err)
+		gotColRoots, err := eds.ColRoots()
+		require.NoError(t, err)
+		assert.Equal(t, rowRoots, gotRowRoots)
+		assert.Equal(t, colRoots, gotColRoots)
+		assert.Zero(t, created.Load())
+
+		// the cache must not alias the roots passed to Repair
+		rowRoots[0][0]++
+		defer func() { rowRoots[0][0]-- }()
+		gotRowRoots, err = eds.RowRoots()
+		require.NoError(t, err)
+		assert.NotEqual(t, rowRoots[0], gotRowRoots[0])
+	})
+
+	t.Run("reports progress and statistics", func(t *testing.T) {
+		flattened := original.Flattened()
+		// rows 0, 1 and 3 are each missing shares
+		flattened[0], flattened[1], flattened[6], flattened[14] = nil, nil, nil, nil
+		eds, err := ImportExtendedDataSquare(flattened, codec, NewDefaultTree)
+		require.NoError(t, err)
+
+		var progress [][2]int
+		var stats RepairStats
+		err = eds.Repair(rowRoots, colRoots, WithProgress(func(repaired, missing int) {
+			progress = append(progress, [2]int{repaired, missing})
+		}), WithRepairStats(&stats))
+		require.NoError(t, err)
+		assert.Equal(t, original.Flattened(), eds.Flattened())
+
+		require.NotEmpty(t, progress)
+		assert.Equal(t, [2]int{4, 4}, progress[len(progress)-1])
+		assert.Equal(t, 4, stats.SharesDecoded)
+		assert.Equal(t, len(progress), stats.RowsSolved+stats.ColsSolved)
+		assert.Positive(t, stats.Iterations)
+	})
+
+	t.Run("aborts when the context is done", func(t *testing.T) {
+		flattened := original.Flattened()
+		flattened[0], flattened[5] = nil, nil
+		eds, err := ImportExtendedDataSquare(flattened, codec, NewDefaultTree)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err = eds.RepairWithContext(ctx, rowRoots, colRoots)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, eds.GetCell(0, 0))
+
+		require.NoError(t, eds.RepairWithContext(context.Background(), rowRoots, colRoots))
+		assert.Equal(t, original.Flattened(), eds.Flattened())
+	})
+
+	t.Run("repairs only the requested axes", func(t *testing.T) {
+		flattened := original.Flattened()
+		flattened[0], flattened[1] = nil, nil
+		flattened[4], flattened[5], flattened[6] = nil, nil, nil
+		eds, err := ImportExtendedDataSquare(flattened, codec, NewDefaultTree)
+		require.NoError(t, err)
+
+		missing, err := eds.RepairAxes([]AxisIndex{{Row, 0}}, rowRoots, colRoots)
+		require.NoError(t, err)
+		assert.Empty(t, missing)
+		assert.Equal(t, original.Row(0), eds.Row(0))
+		assert.Nil(t, eds.GetCell(1, 0), "row 1 should not be repaired")
+
+		// row 1 has a single share left, but its columns can be decoded
+		missing, err = eds.RepairAxes([]AxisIndex{{Row, 1}}, rowRoots, colRoots)
+		require.NoError(t, err)
+		assert.Equal(t, []CellIndex{{1, 0}, {1, 1}, {1, 2}}, missing)
+
+		missing, err = eds.RepairAxes([]AxisIndex{{Col, 0}, {Col, 1}, {Row, 1}}, rowRoots, colRoots)
+		require.NoError(t, err)
+		assert.Empty(t, missing)
+		assert.Equal(t, original.Flattened(), eds.Flattened())
+
+		_, err = eds.RepairAxes([]AxisIndex{{Col, eds.Width()}}, rowRoots, colRoots)
+		assert.Error(t, err)
+	})
+
 	// Verify that an EDS returns an error when there are too many erasures
 	t.Run("Unrepairable", func(t *testing.T) {
 		flattened := original.Flattened()
@@ -79,6 +176,29 @@ func TestRepairExtendedDataSquare(t *testing.T) {
 		}
 	})
 
+	t.Run("Unrepairable is partially repaired", func(t *testing.T) {
+		// only the first row can be repaired
+		flattened := make([][]byte, len(original.Flattened()))
+		flattened[0], flattened[1] = original.GetCell(0, 0), original.GetCell(0, 1)
+
+		eds, err := ImportExtendedDataSquare(flattened, codec, NewDefaultTree)
+		require.NoError(t, err)
+		err = eds.Repair(rowRoots, colRoots)
+		assert.ErrorIs(t, err, ErrUnrepairableDataSquare)
+		assert.Equal(t, original.Row(0), eds.Row(0))
+	})
+
+	t.Run("Unrepairable with WithFailFast is left unmodified", func(t *testing.T) {
+		flattened := make([][]byte, len(original.Flattened()))
+		flattened[0], flattened[1] = original.GetCell(0, 0), original.GetCell(0, 1)
+
+		eds, err := ImportExtendedDataSquare(flattened, codec, NewDefaultTree)
+		require.NoError(t, err)
+		err = eds.Repair(rowRoots, colRoots, WithFailFast())
+		assert.ErrorIs(t, err, ErrUnrepairableDataSquare)
+		assert.Equal(t, flattened, eds.Flattened())
+	})
+
 	t.Run("repair in random order", func(t *testing.T) {
 		for i := 0; i < 100; i++ {
 			newEds, err := NewExtendedDataSquare(codec, NewDefaultTree, original.Width(), shareSize)
@@ -121,7 +241,7 @@ func TestValidFraudProof(t *testing.T) {
 	original := createTestEds(codec, shareSize)
 
 	var byzData *ErrByzantineData
-	corrupted, err := original.deepCopy(codec)
+	corrupted, err := original.Clone()
 	if err != nil {
 		t.Fatalf("unexpected err while copying original data: %v, codec: :%s", err, codec.Name())
 	}
@@ -189,26 +309,37 @@ func TestCorruptedEdsReturnsErrByzantineData(t *testing.T) {
 		name   string
 		coords [][]uint
 		values [][]byte
+		// axis and index are the byzantine axis that must be reported
+		axis  Axis
+		index uint
 	}{
 		{
 			name:   "corrupt a share in the original data square",
 			coords: [][]uint{{0, 0}},
 			values: [][]byte{corruptShare},
+			axis:   Row,
+			index:  0,
 		},
 		{
 			name:   "corrupt a share in the extended data square",
 			coords: [][]uint{{0, 3}},
 			values: [][]byte{corruptShare},
+			axis:   Row,
+			index:  0,
 		},
 		{
 			name:   "corrupt a share at (0, 0) and delete shares from the rest of the row",
 			coords: [][]uint{{0, 0}, {0, 1}, {0, 2}, {0, 3}},
 			values: [][]byte{corruptShare, nil, nil, nil},
+			axis:   Col,
+			index:  0,
 		},
 		{
 			name:   "corrupt a share at (3, 0) and delete part of the first row ",
 			coords: [][]uint{{3, 0}, {0, 1}, {0, 2}, {0, 3}},
 			values: [][]byte{corruptShare, nil, nil, nil},
+			axis:   Col,
+			index:  0,
 		},
 		{
 			// This test case sets all shares along the diagonal to nil so that
@@ -225,6 +356,8 @@ func TestCorruptedEdsReturnsErrByzantineData(t *testing.T) {
 			// O O O _
 			coords: [][]uint{{0, 0}, {1, 1}, {2, 2}, {3, 3}, {0, 1}},
 			values: [][]byte{nil, nil, nil, nil, corruptShare},
+			axis:   Row,
+			index:  0,
 		},
 	}
 
@@ -251,15 +384,101 @@ func TestCorruptedEdsReturnsErrByzantineData(t *testing.T) {
 			err = eds.Repair(rowRoots, colRoots)
 			assert.Error(t, err)
 
-			// due to parallelisation, the ErrByzantineData axis may be either row or col
 			var byzData *ErrByzantineData
-			assert.ErrorAs(t, err, &byzData, "did not return a ErrByzantineData for a bad col or row")
+			require.ErrorAs(t, err, &byzData, "did not return a ErrByzantineData for a bad col or row")
+			assert.Equal(t, test.axis, byzData.Axis)
+			assert.Equal(t, test.index, byzData.Index)
 			assert.NotEmpty(t, byzData.Shares)
 			assert.Contains(t, byzData.Shares, corruptShare)
 		})
 	}
 }
 
+func TestRepairWithReport(t *testing.T) {
+	codec := NewLeoRSCodec()
+	original := createTestEds(codec, shareSize)
+	rowRoots, err := original.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := original.ColRoots()
+	require.NoError(t, err)
+
+	flattened := original.Flattened()
+	flattened[1], flattened[6], flattened[15] = nil, nil, nil
+	eds, err := ImportExtendedDataSquare(flattened, codec, NewDefaultTree)
+	require.NoError(t, err)
+
+	repaired, err := eds.RepairWithReport(rowRoots, colRoots)
+	require.NoError(t, err)
+	assert.Equal(t, []CellIndex{{0, 1}, {1, 2}, {3, 3}}, repaired)
+	assert.True(t, original.Equals(eds))
+
+	// nothing is reconstructed in a complete square
+	repaired, err = eds.RepairWithReport(rowRoots, colRoots)
+	require.NoError(t, err)
+	assert.Empty(t, repaired)
+}
+
+func TestRepairWithAllByzantineAxes(t *testing.T) {
+	codec := NewLeoRSCodec()
+	eds := createTestEds(codec, shareSize)
+	rowRoots, err := eds.getRowRoots()
+	require.NoError(t, err)
+	colRoots, err := eds.getColRoots()
+	require.NoError(t, err)
+
+	corruptShare := bytes.Repeat([]byte{66}, shareSize)
+	eds.setCell(0, 0, corruptShare)
+	eds.setCell(2, 3, corruptShare)
+
+	err = eds.Repair(rowRoots, colRoots, WithAllByzantineAxes())
+	var byzAxes *ErrByzantineAxes
+	require.ErrorAs(t, err, &byzAxes)
+	axisIndices := func(errs []*ErrByzantineData) (indices []uint) {
+		for _, byzErr := range errs {
+			assert.Contains(t, byzErr.Shares, corruptShare)
+			indices = append(indices, byzErr.Index)
+		}
+		return indices
+	}
+	assert.Equal(t, []uint{0, 2}, axisIndices(byzAxes.Rows))
+	assert.Equal(t, []uint{0, 3}, axisIndices(byzAxes.Cols))
+
+	// the aggregate unwraps to the first Byzantine axis
+	var byzData *ErrByzantineData
+	require.ErrorAs(t, err, &byzData)
+	assert.Equal(t, Row, byzData.Axis)
+	assert.Equal(t, uint(0), byzData.Index)
+}
+
+func TestRepairAttachesByzantineProofs(t *testing.T) {
+	codec := NewLeoRSCodec()
+	eds := createTestEds(codec, shareSize)
+	rowRoots, err := eds.getRowRoots()
+	require.NoError(t, err)
+	colRoots, err := eds.getColRoots()
+	require.NoError(t, err)
+
+	corruptShare := bytes.Repeat([]byte{66}, shareSize)
+	eds.setCell(0, 0, corruptShare)
+
+	err = eds.Repair(rowRoots, colRoots)
+	var byzData *ErrByzantineData
+	require.ErrorAs(t, err, &byzData)
+	require.Equal(t, Row, byzData.Axis)
+	require.Len(t, byzData.Proofs, len(byzData.Shares))
+
+	// column 0 contains the corrupted share, so it does not match its root
+	assert.Nil(t, byzData.Proofs[0])
+	for i := 1; i < len(byzData.Proofs); i++ {
+		proof := byzData.Proofs[i]
+		require.NotNil(t, proof)
+		assert.Equal(t, Col, proof.Axis)
+		assert.Equal(t, colRoots[i], proof.Root)
+		assert.Equal(t, byzData.Shares[i], proof.Share)
+		assert.Equal(t, uint(0), proof.Index)
+	}
+}
+
 func BenchmarkRepair(b *testing.B) {
 	// For different ODS sizes
 	for originalDataWidth := 4; originalDataWidth <= 512; originalDataWidth *= 2 {
@@ -524,9 +743,9 @@ func repairNewFromCorrupted(codec Codec, corrupted *ExtendedDataSquare, corrupte
 	}
 
 	// set corrupted share first
-	corruptedX, corruptedY := corruptedIdx/int(corrupted.Width()), corruptedIdx%int(corrupted.Width())
-	share := corrupted.GetCell(uint(corruptedX), uint(corruptedY))
-	err = square.SetCell(uint(corruptedX), uint(corruptedY), share)
+	corruptedX, corruptedY := CoordinatesOf(uint(corruptedIdx), corrupted.Width())
+	share := corrupted.GetCell(corruptedX, corruptedY)
+	err = square.SetCell(corruptedX, corruptedY, share)
 	if err != nil {
 		return nil, fmt.Errorf("failure to set corrupted share: %w", err)
 	}
@@ -549,7 +768,7 @@ func repairNewFromCorrupted(codec Codec, corrupted *ExtendedDataSquare, corrupte
 		}
 		var errByz *ErrByzantineData
 		if errors.As(err, &errByz) {
-			err = checkErrByzantine(errByz, corruptedX, corruptedY)
+			err = checkErrByzantine(errByz, int(corruptedX), int(corruptedY))
 			if err != nil {
 				prettyPrintSamples(samples, corruptedIdx)
 			}
@@ -658,3 +877,50 @@ func createTestEdsWithNMT(t *testing.T, codec Codec, shareSize, namespaceSize in
 
 	return eds
 }
+
+func TestVerifyEncoding(t *testing.T) {
+	// Duplication does not implement Verifier
+	for _, codec := range []Codec{NewLeoRSCodec(), NewRSGF8Codec(), NewDuplicationCodec()} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			// the scratch shares are reused across calls with different sizes
+			for _, width := range []int{4, 2, 8} {
+				original := generateRandData(width, shareSize)
+				parity, err := codec.Encode(original)
+				require.NoError(t, err)
+
+				ok, err := VerifyEncoding(codec, original, parity)
+				require.NoError(t, err)
+				assert.True(t, ok)
+				assert.NoError(t, VerifyAxisEncoding(append(deepCopy(original), parity...), codec))
+
+				parity[len(parity)-1] = bytes.Repeat([]byte{1}, shareSize)
+				ok, err = VerifyEncoding(codec, original, parity)
+				require.NoError(t, err)
+				assert.False(t, ok)
+				assert.ErrorIs(t, VerifyAxisEncoding(append(deepCopy(original), parity...), codec), ErrParityMismatch)
+			}
+
+			_, err := VerifyEncoding(codec, generateRandData(4, shareSize), generateRandData(2, shareSize))
+			assert.Error(t, err)
+			assert.Error(t, VerifyAxisEncoding(make([][]byte, 4), codec))
+		})
+	}
+}
+
+func TestVerifyAxisAgainstRoot(t *testing.T) {
+	eds := createTestEds(NewLeoRSCodec(), shareSize)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+
+	for i := uint(0); i < eds.Width(); i++ {
+		assert.NoError(t, VerifyAxisAgainstRoot(eds.Row(i), rowRoots[i], NewDefaultTree, Row, i))
+		assert.NoError(t, VerifyAxisAgainstRoot(eds.Col(i), colRoots[i], NewDefaultTree, Col, i))
+	}
+	assert.ErrorIs(t, VerifyAxisAgainstRoot(eds.Row(0), rowRoots[1], NewDefaultTree, Row, 0), ErrRootMismatch)
+
+	incomplete := eds.Row(0)
+	incomplete[1] = nil
+	assert.Error(t, VerifyAxisAgainstRoot(incomplete, rowRoots[0], NewDefaultTree, Row, 0))
+}
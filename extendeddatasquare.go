@@ -4,44 +4,303 @@ package rsmt2d
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash"
+	"io"
+	"runtime"
+	"time"
 
+	"github.com/celestiaorg/merkletree"
 	"golang.org/x/sync/errgroup"
 )
 
 // ExtendedDataSquare represents an extended piece of data.
+//
+// SetCell, SetCells, GetCell, Row, Col and Flattened can be called
+// concurrently, e.g. to populate a square from parallel network fetches while
+// reading it. Other methods, in particular the computation of roots and
+// Repair, must not run concurrently with methods that modify the square.
 type ExtendedDataSquare struct {
 	*dataSquare
 	codec             Codec
 	originalDataWidth uint
 }
 
+// codecID identifies the codec of a serialized square.
+type codecID struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+}
+
+// UnmarshalJSON unmarshals a codec identifier. For backwards compatibility, a
+// bare codec name is also accepted and assumed to have the default version.
+func (id *codecID) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err == nil {
+		*id = codecID{Name: name, Version: defaultCodecVersion}
+		return nil
+	}
+	type plain codecID
+	return json.Unmarshal(b, (*plain)(id))
+}
+
+// MarshalJSON serializes the shares of the square, its share size and its
+// codec. Missing shares are serialized as null and are missing again after
+// UnmarshalJSON, so that incomplete squares, e.g. squares being sampled or
+// repaired, can be snapshotted and restored. If the roots of all rows and
+// columns have already been computed, they are serialized as well.
 func (eds *ExtendedDataSquare) MarshalJSON() ([]byte, error) {
+	return eds.MarshalJSONWith()
+}
+
+// MarshalJSONWith is like MarshalJSON, but configured by opts. If the square
+// is compressed, see WithCompression, its shares are serialized as the
+// compressed output of WriteTo instead of as a list.
+func (eds *ExtendedDataSquare) MarshalJSONWith(opts ...MarshalOption) ([]byte, error) {
+	cfg := newMarshalConfig(opts...)
+	codec := codecID{
+		Name:    eds.codec.Name(),
+		Version: codecVersion(eds.codec),
+	}
+	tree, err := cfg.newTreeSpec()
+	if err != nil {
+		return nil, err
+	}
+	rowRoots, colRoots, _ := eds.cachedRoots()
+	if cfg.compression == NoCompression {
+		return json.Marshal(&struct {
+			DataSquare [][]byte  `json:"data_square"`
+			ShareSize  uint      `json:"share_size"`
+			Codec      codecID   `json:"codec"`
+			Tree       *treeSpec `json:"tree,omitempty"`
+			RowRoots   [][]byte  `json:"row_roots,omitempty"`
+			ColRoots   [][]byte  `json:"col_roots,omitempty"`
+		}{
+			DataSquare: eds.dataSquare.Flattened(),
+			ShareSize:  eds.shareSize,
+			Codec:      codec,
+			Tree:       tree,
+			RowRoots:   rowRoots,
+			ColRoots:   colRoots,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := eds.writeCompressed(&buf, cfg.compression); err != nil {
+		return nil, err
+	}
 	return json.Marshal(&struct {
-		DataSquare [][]byte `json:"data_square"`
-		Codec      string   `json:"codec"`
+		CompressedDataSquare []byte      `json:"compressed_data_square"`
+		Compression          Compression `json:"compression"`
+		Codec                codecID     `json:"codec"`
+		Tree                 *treeSpec   `json:"tree,omitempty"`
+		RowRoots             [][]byte    `json:"row_roots,omitempty"`
+		ColRoots             [][]byte    `json:"col_roots,omitempty"`
 	}{
-		DataSquare: eds.dataSquare.Flattened(),
-		Codec:      eds.codec.Name(),
+		CompressedDataSquare: buf.Bytes(),
+		Compression:          cfg.compression,
+		Codec:                codec,
+		Tree:                 tree,
+		RowRoots:             rowRoots,
+		ColRoots:             colRoots,
 	})
 }
 
+// UnmarshalJSON unmarshals a square serialized by MarshalJSON or
+// MarshalJSONWith, decompressing it if needed. The square uses the tree
+// recorded by WithTree, or DefaultTree if there is none. Serialized roots are
+// verified against the shares and cached. Returns an error wrapping
+// ErrIncompatibleCodecVersion if the square was encoded with a different
+// version of its codec than the registered one, or ErrRootMismatch if a
+// serialized root does not match the shares.
 func (eds *ExtendedDataSquare) UnmarshalJSON(b []byte) error {
+	decoded, err := unmarshalJSON(b, defaultDecoder())
+	if err != nil {
+		return err
+	}
+	*eds = *decoded
+	return nil
+}
+
+// UnmarshalJSONWith is like UnmarshalJSON, but imports the square with codec
+// and treeCreatorFn instead of the registered codec and the recorded tree, so
+// that squares can be deserialized without registering their codec. codec
+// must have the name and version the square was encoded with. opts are
+// applied as by ImportExtendedDataSquare, see also WithTrustedRoots.
+func UnmarshalJSONWith(
+	b []byte,
+	codec Codec,
+	treeCreatorFn TreeConstructorFn,
+	opts ...ImportOption,
+) (*ExtendedDataSquare, error) {
+	return unmarshalJSON(b, squareDecoder{codec: codec, treeCreatorFn: treeCreatorFn, opts: opts})
+}
+
+// unmarshalJSON unmarshals a square serialized by MarshalJSONWith, resolving
+// its codec and tree with dec.
+func unmarshalJSON(b []byte, dec squareDecoder) (*ExtendedDataSquare, error) {
 	var aux struct {
-		DataSquare [][]byte `json:"data_square"`
-		Codec      string   `json:"codec"`
+		DataSquare           [][]byte    `json:"data_square"`
+		ShareSize            int         `json:"share_size"`
+		CompressedDataSquare []byte      `json:"compressed_data_square"`
+		Compression          Compression `json:"compression"`
+		Codec                codecID     `json:"codec"`
+		Tree                 *treeSpec   `json:"tree"`
+		RowRoots             [][]byte    `json:"row_roots"`
+		ColRoots             [][]byte    `json:"col_roots"`
 	}
 
+	if err := newImportConfig(dec.opts...).checkJSONLimits(b); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return nil, err
+	}
+	codec, err := dec.resolveCodec(aux.Codec)
+	if err != nil {
+		return nil, err
+	}
+	if dec.treeCreatorFn == nil && aux.Tree != nil {
+		if dec.treeCreatorFn, err = aux.Tree.treeConstructor(); err != nil {
+			return nil, err
+		}
+	}
+	decoded := &ExtendedDataSquare{}
+	if aux.CompressedDataSquare != nil {
+		if err := decoded.readCompressed(bytes.NewReader(aux.CompressedDataSquare), aux.Compression, dec); err != nil {
+			return nil, err
+		}
+	} else {
+		shareSize := getShareSize(aux.DataSquare)
+		if shareSize == 0 {
+			// the square has no shares, squares serialized before share_size
+			// was recorded had at least one
+			shareSize = aux.ShareSize
+		}
+		if decoded, err = importExtendedDataSquare(aux.DataSquare, shareSize, codec, dec.tree(), dec.opts...); err != nil {
+			return nil, err
+		}
+	}
+	if aux.RowRoots != nil || aux.ColRoots != nil {
+		if err := decoded.restoreRoots(aux.RowRoots, aux.ColRoots, newImportConfig(dec.opts...).trustedRoots); err != nil {
+			return nil, err
+		}
+	}
+	return decoded, nil
+}
+
+// squareDecoder resolves the codec and tree of a deserialized square.
+type squareDecoder struct {
+	// codec is the codec of the square. If nil, the registered codec is
+	// used.
+	codec Codec
+	// treeCreatorFn is the tree constructor of the square. If nil, the tree
+	// recorded by WithTree or else the default tree is used.
+	treeCreatorFn TreeConstructorFn
+	opts          []ImportOption
+}
+
+// defaultDecoder returns the squareDecoder used by the unmarshalling methods
+// of ExtendedDataSquare: the registered codec and the recorded or default
+// tree.
+func defaultDecoder() squareDecoder {
+	return squareDecoder{}
+}
+
+// tree returns the tree constructor of the square.
+func (dec squareDecoder) tree() TreeConstructorFn {
+	if dec.treeCreatorFn == nil {
+		return NewDefaultTree
+	}
+	return dec.treeCreatorFn
+}
+
+// resolveCodec returns the codec of a square that was encoded with the codec
+// identified by id.
+func (dec squareDecoder) resolveCodec(id codecID) (Codec, error) {
+	if dec.codec == nil {
+		return id.codec()
+	}
+	if name := dec.codec.Name(); id.Name != name {
+		return nil, fmt.Errorf("square was encoded with codec %q, not %q", id.Name, name)
+	}
+	if version := codecVersion(dec.codec); id.Version != version {
+		return nil, fmt.Errorf("%w: square was encoded with %s version %d but version %d was supplied",
+			ErrIncompatibleCodecVersion, id.Name, id.Version, version)
+	}
+	return dec.codec, nil
+}
+
+// codec returns the registered codec identified by id. Returns an error
+// wrapping ErrCodecNotRegistered or ErrIncompatibleCodecVersion if there is
+// no such codec.
+func (id codecID) codec() (Codec, error) {
+	codec, ok := GetCodec(id.Name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrCodecNotRegistered, id.Name)
+	}
+	if version := codecVersion(codec); id.Version != version {
+		return nil, fmt.Errorf("%w: square was encoded with %s version %d but version %d is registered",
+			ErrIncompatibleCodecVersion, id.Name, id.Version, version)
+	}
+	return codec, nil
+}
+
+// MarshalODS is a compact alternative to MarshalJSON that only serializes the
+// original data square and the codec, which makes the output about four
+// times smaller. Returns an error if the original data square is incomplete
+// (i.e. some shares are nil).
+func (eds *ExtendedDataSquare) MarshalODS() ([]byte, error) {
+	ods := make([][]byte, 0, eds.originalDataWidth*eds.originalDataWidth)
+	for rowIdx := uint(0); rowIdx < eds.originalDataWidth; rowIdx++ {
+		row := eds.rowSlice(rowIdx, 0, eds.originalDataWidth)
+		if !isComplete(row) {
+			return nil, fmt.Errorf("%w: can not marshal incomplete original row %d", ErrIncompleteAxis, rowIdx)
+		}
+		ods = append(ods, row...)
+	}
+	return json.Marshal(&struct {
+		ODS   [][]byte `json:"original_data_square"`
+		Codec codecID  `json:"codec"`
+	}{
+		ODS: ods,
+		Codec: codecID{
+			Name:    eds.codec.Name(),
+			Version: codecVersion(eds.codec),
+		},
+	})
+}
+
+// UnmarshalODS unmarshals a square serialized by MarshalODS by extending its
+// original data square again. As the extension is deterministic, the
+// resulting square and its roots are identical to the ones of the marshalled
+// square. Like UnmarshalJSON, it honors DefaultMaxImportWidth and
+// DefaultMaxImportBytes.
+func (eds *ExtendedDataSquare) UnmarshalODS(b []byte) error {
+	var aux struct {
+		ODS   [][]byte `json:"original_data_square"`
+		Codec codecID  `json:"codec"`
+	}
+
+	// the original data square is a quarter of the extended one
+	if err := newImportConfig().checkJSONFieldLimits(b, "original_data_square", 4); err != nil {
+		return err
+	}
 	if err := json.Unmarshal(b, &aux); err != nil {
 		return err
 	}
-	importedEds, err := ImportExtendedDataSquare(aux.DataSquare, codecs[aux.Codec], NewDefaultTree)
+	codec, err := aux.Codec.codec()
 	if err != nil {
 		return err
 	}
-	*eds = *importedEds
+	computedEds, err := ComputeExtendedDataSquare(aux.ODS, codec, NewDefaultTree)
+	if err != nil {
+		return err
+	}
+	*eds = *computedEds
 	return nil
 }
 
@@ -51,10 +310,77 @@ func ComputeExtendedDataSquare(
 	data [][]byte,
 	codec Codec,
 	treeCreatorFn TreeConstructorFn,
+) (*ExtendedDataSquare, error) {
+	return ComputeExtendedDataSquareWithContext(context.Background(), data, codec, treeCreatorFn)
+}
+
+// ComputeExtendedDataSquareWithContext is like ComputeExtendedDataSquare but
+// aborts the extension and returns ctx.Err() if ctx is done before the
+// extension finishes.
+func ComputeExtendedDataSquareWithContext(
+	ctx context.Context,
+	data [][]byte,
+	codec Codec,
+	treeCreatorFn TreeConstructorFn,
+) (*ExtendedDataSquare, error) {
+	eds, err := newOriginalDataSquare(data, codec, treeCreatorFn)
+	if err != nil {
+		return nil, err
+	}
+
+	emitEvent(Event{Type: EventExtensionStarted, Width: eds.width})
+	start := time.Now()
+	err = eds.erasureExtendSquare(ctx, codec)
+	emitEvent(Event{Type: EventExtensionFinished, Width: eds.width, Duration: time.Since(start), Err: err})
+	if err != nil {
+		return nil, err
+	}
+
+	return eds, nil
+}
+
+// ComputeExtendedDataSquareLazy is like ComputeExtendedDataSquare, but defers
+// the encoding of the parity quadrants until a share outside of the original
+// data square, or anything depending on one such as a root, is first
+// accessed. The original data can be read, e.g. via ODS or GetCell, without
+// encoding the parity quadrants. The shares of the parity quadrants are
+// allocated right away.
+func ComputeExtendedDataSquareLazy(
+	data [][]byte,
+	codec Codec,
+	treeCreatorFn TreeConstructorFn,
+) (*ExtendedDataSquare, error) {
+	eds, err := newOriginalDataSquare(data, codec, treeCreatorFn)
+	if err != nil {
+		return nil, err
+	}
+
+	odsWidth := eds.width
+	if err := eds.allocExtension(); err != nil {
+		return nil, err
+	}
+	eds.lazy = &lazyExtension{
+		odsWidth: odsWidth,
+		encode: func() error {
+			emitEvent(Event{Type: EventExtensionStarted, Width: odsWidth})
+			start := time.Now()
+			err := eds.encodeExtension(context.Background(), codec)
+			emitEvent(Event{Type: EventExtensionFinished, Width: eds.width, Duration: time.Since(start), Err: err})
+			return err
+		},
+	}
+	return eds, nil
+}
+
+// newOriginalDataSquare validates data and returns the not yet extended
+// square holding it.
+func newOriginalDataSquare(
+	data [][]byte,
+	codec Codec,
+	treeCreatorFn TreeConstructorFn,
 ) (*ExtendedDataSquare, error) {
 	if len(data) > codec.MaxChunks() {
-		// TODO: export this error and rename chunk to share
-		return nil, errors.New("number of chunks exceeds the maximum")
+		return nil, fmt.Errorf("%w: %d shares exceed the maximum of %d for %s", ErrUnsupportedWidth, len(data), codec.MaxChunks(), codec.Name())
 	}
 
 	shareSize := getShareSize(data)
@@ -66,29 +392,63 @@ func ComputeExtendedDataSquare(
 	if err != nil {
 		return nil, err
 	}
-
-	eds := ExtendedDataSquare{dataSquare: ds, codec: codec}
-	err = eds.erasureExtendSquare(codec)
+	err = ValidateWidth(codec, ds.width)
 	if err != nil {
 		return nil, err
 	}
 
-	return &eds, nil
+	return &ExtendedDataSquare{dataSquare: ds, codec: codec}, nil
 }
 
 // ImportExtendedDataSquare imports an extended data square, represented as flattened shares of data.
+// The shares are not copied: the square takes ownership of them, so they must
+// not be modified afterwards. data itself is copied, so that the caller may
+// reuse it; use ImportExtendedDataSquareNoCopy to avoid that copy.
+// The size of the imported square can be limited via opts, see WithMaxWidth
+// and WithMaxBytes. The parity of the imported square can be verified via
+// opts, see WithParityCheck and WithSampledParityCheck.
 func ImportExtendedDataSquare(
 	data [][]byte,
 	codec Codec,
 	treeCreatorFn TreeConstructorFn,
+	opts ...ImportOption,
+) (*ExtendedDataSquare, error) {
+	return ImportExtendedDataSquareNoCopy(append([][]byte(nil), data...), codec, treeCreatorFn, opts...)
+}
+
+// ImportExtendedDataSquareNoCopy is like ImportExtendedDataSquare, but takes
+// ownership of data as well as of its shares: the rows of the square are
+// slices of data, so neither data nor its shares must be modified or reused
+// afterwards. It is meant for callers that build data only to import it, and
+// saves copying the slice of shares of a large square.
+func ImportExtendedDataSquareNoCopy(
+	data [][]byte,
+	codec Codec,
+	treeCreatorFn TreeConstructorFn,
+	opts ...ImportOption,
+) (*ExtendedDataSquare, error) {
+	return importExtendedDataSquare(data, getShareSize(data), codec, treeCreatorFn, opts...)
+}
+
+// importExtendedDataSquare is like ImportExtendedDataSquare, but takes the
+// share size, so that squares without any share can be imported.
+func importExtendedDataSquare(
+	data [][]byte,
+	shareSize int,
+	codec Codec,
+	treeCreatorFn TreeConstructorFn,
+	opts ...ImportOption,
 ) (*ExtendedDataSquare, error) {
 	if len(data) > 4*codec.MaxChunks() {
-		// TODO: export this error and rename chunk to share
-		return nil, errors.New("number of chunks exceeds the maximum")
+		return nil, fmt.Errorf("%w: %d shares exceed the maximum of %d for %s", ErrUnsupportedWidth, len(data), 4*codec.MaxChunks(), codec.Name())
 	}
 
-	shareSize := getShareSize(data)
-	err := codec.ValidateChunkSize(shareSize)
+	cfg := newImportConfig(opts...)
+	err := cfg.checkLimits(len(data), shareSize)
+	if err != nil {
+		return nil, err
+	}
+	err = codec.ValidateChunkSize(shareSize)
 	if err != nil {
 		return nil, err
 	}
@@ -102,12 +462,218 @@ func ImportExtendedDataSquare(
 	if err != nil {
 		return nil, err
 	}
+	err = ValidateWidth(codec, eds.width/2)
+	if err != nil {
+		return nil, err
+	}
 
 	eds.originalDataWidth = eds.width / 2
 
+	if cfg.parityCheck {
+		err = eds.checkParity(cfg.paritySamples)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &eds, nil
 }
 
+// ImportFromBytes imports an extended data square of the given width from
+// the concatenation of its shares in row-major order, as returned by
+// FlattenedBytes. The shares are sliced from data without copying it, so data
+// must not be modified afterwards. opts are applied as by
+// ImportExtendedDataSquare.
+func ImportFromBytes(
+	data []byte,
+	shareSize uint,
+	edsWidth uint,
+	codec Codec,
+	treeCreatorFn TreeConstructorFn,
+	opts ...ImportOption,
+) (*ExtendedDataSquare, error) {
+	if shareSize == 0 {
+		return nil, fmt.Errorf("%w: share size must be positive", ErrInvalidShareSize)
+	}
+	if uint(len(data)) != edsWidth*edsWidth*shareSize {
+		return nil, fmt.Errorf("got %d bytes, expected %d for %d shares of %d bytes", len(data), edsWidth*edsWidth*shareSize, edsWidth*edsWidth, shareSize)
+	}
+	shares := make([][]byte, edsWidth*edsWidth)
+	for i := range shares {
+		start := uint(i) * shareSize
+		shares[i] = data[start : start+shareSize : start+shareSize]
+	}
+	return ImportExtendedDataSquareNoCopy(shares, codec, treeCreatorFn, opts...)
+}
+
+// ImportFromReader is like ImportFromBytes, but reads the shares from r. The
+// width, share size and import limits are validated before the square is
+// allocated, and the shares are read directly into the square, so that no
+// more than the square itself is held in memory. Reading stops at the end of
+// the square.
+func ImportFromReader(
+	r io.Reader,
+	shareSize uint,
+	edsWidth uint,
+	codec Codec,
+	treeCreatorFn TreeConstructorFn,
+	opts ...ImportOption,
+) (*ExtendedDataSquare, error) {
+	if shareSize == 0 {
+		return nil, fmt.Errorf("%w: share size must be positive", ErrInvalidShareSize)
+	}
+	if err := validateEdsWidth(edsWidth); err != nil {
+		return nil, err
+	}
+	if err := ValidateWidth(codec, edsWidth/2); err != nil {
+		return nil, err
+	}
+	if err := newImportConfig(opts...).checkLimits(int(edsWidth*edsWidth), int(shareSize)); err != nil {
+		return nil, err
+	}
+	if err := codec.ValidateChunkSize(int(shareSize)); err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, edsWidth*edsWidth*shareSize)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("reading %d shares of %d bytes: %w", edsWidth*edsWidth, shareSize, err)
+	}
+	return ImportFromBytes(data, shareSize, edsWidth, codec, treeCreatorFn, opts...)
+}
+
+// ImportAndVerify is like ImportExtendedDataSquare, but also verifies the
+// supplied shares against the expected rowRoots and colRoots. Every row and
+// column with at least half of its shares is recomputed, decoding its missing
+// shares if needed, and compared against its root, so that every supplied
+// share is verified unless both its row and column have fewer than half of
+// their shares. If an axis does not match its root, an ErrByzantineData with
+// the supplied shares of the lowest-index such axis is returned, with a row
+// taking precedence over the column of the same index.
+func ImportAndVerify(
+	data [][]byte,
+	codec Codec,
+	treeCreatorFn TreeConstructorFn,
+	rowRoots [][]byte,
+	colRoots [][]byte,
+	opts ...ImportOption,
+) (*ExtendedDataSquare, error) {
+	eds, err := ImportExtendedDataSquare(data, codec, treeCreatorFn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if uint(len(rowRoots)) != eds.width || uint(len(colRoots)) != eds.width {
+		return nil, fmt.Errorf("got %d row roots and %d column roots for width %d", len(rowRoots), len(colRoots), eds.width)
+	}
+
+	for i := uint(0); i < eds.width; i++ {
+		if err := eds.verifySuppliedShares(Row, i, rowRoots[i]); err != nil {
+			return nil, err
+		}
+		if err := eds.verifySuppliedShares(Col, i, colRoots[i]); err != nil {
+			return nil, err
+		}
+	}
+	return eds, nil
+}
+
+// verifySuppliedShares recomputes the row or column at axisIdx from its
+// shares and returns an ErrByzantineData if it does not match root. Axes with
+// fewer than half of their shares can not be recomputed and are not verified.
+func (eds *ExtendedDataSquare) verifySuppliedShares(axis Axis, axisIdx uint, root []byte) error {
+	var supplied [][]byte
+	if axis == Row {
+		supplied = eds.row(axisIdx)
+	} else {
+		supplied = eds.col(axisIdx)
+	}
+
+	present := uint(0)
+	for _, share := range supplied {
+		if share != nil {
+			present++
+		}
+	}
+	if present < eds.width/2 {
+		return nil
+	}
+
+	shares := supplied
+	if present < eds.width {
+		var err error
+		shares, err = eds.codec.Decode(deepCopy(supplied))
+		if err != nil {
+			return err
+		}
+	}
+	computed, err := eds.computeSharesRoot(shares, axis, axisIdx)
+	if err != nil || !bytes.Equal(computed, root) {
+		// any error regarding the root calculation signifies an issue in the
+		// shares, e.g. out of order shares, so it is treated as byzantine
+		return &ErrByzantineData{axis, axisIdx, deepCopy(supplied), nil}
+	}
+	return nil
+}
+
+// VerifyODS verifies the original data square ods, given in row-major order,
+// against the first half of rowRoots, the row roots of its extended data
+// square, without constructing the extended data square. The rows are
+// extended with codec one at a time, reusing a single buffer for the parity
+// shares, and their roots are computed with trees created by treeCreatorFn.
+// The parity rows are not verified, as they can only be computed from the
+// columns of the whole square. If a row does not match its root, an
+// ErrByzantineData with the shares of the original row is returned.
+func VerifyODS(ods [][]byte, rowRoots [][]byte, codec Codec, treeCreatorFn TreeConstructorFn) error {
+	width := uint(len(rowRoots))
+	if width == 0 {
+		return fmt.Errorf("%w: no row roots", ErrUnsupportedWidth)
+	}
+	if err := validateEdsWidth(width); err != nil {
+		return err
+	}
+	odsWidth := width / 2
+	if uint(len(ods)) != odsWidth*odsWidth {
+		return fmt.Errorf("got %d shares for %d row roots, expected %d", len(ods), width, odsWidth*odsWidth)
+	}
+	if err := ValidateWidth(codec, odsWidth); err != nil {
+		return err
+	}
+	shareSize := getShareSize(ods)
+	if err := codec.ValidateChunkSize(shareSize); err != nil {
+		return err
+	}
+
+	shares := make([][]byte, width)
+	parity := shares[odsWidth:]
+	buf := make([]byte, int(odsWidth)*shareSize)
+	for i := range parity {
+		parity[i] = buf[i*shareSize : (i+1)*shareSize : (i+1)*shareSize]
+	}
+	info := SquareInfo{Width: width, ShareSize: uint(shareSize)}
+	for rowIdx := uint(0); rowIdx < odsWidth; rowIdx++ {
+		row := ods[rowIdx*odsWidth : (rowIdx+1)*odsWidth]
+		if !isComplete(row) {
+			return fmt.Errorf("%w: can not verify row %d", ErrIncompleteAxis, rowIdx)
+		}
+		if err := codec.EncodeInto(row, parity); err != nil {
+			return err
+		}
+		copy(shares, row)
+		root, err := axisRoot(newSquareTree(treeCreatorFn, Row, rowIdx, info), shares)
+		if err != nil || !bytes.Equal(root, rowRoots[rowIdx]) {
+			// any error regarding the root calculation signifies an issue in
+			// the shares, so it is treated as byzantine
+			byzShares := make([][]byte, width)
+			copy(byzShares, row)
+			return &ErrByzantineData{Row, rowIdx, deepCopy(byzShares), nil}
+		}
+	}
+	return nil
+}
+
 // NewExtendedDataSquare returns a new extended data square with a width of
 // edsWidth. All shares are initialized to nil so that the returned extended
 // data square can be populated via subsequent SetCell invocations.
@@ -116,6 +682,10 @@ func NewExtendedDataSquare(codec Codec, treeCreatorFn TreeConstructorFn, edsWidt
 	if err != nil {
 		return nil, err
 	}
+	err = ValidateWidth(codec, edsWidth/2)
+	if err != nil {
+		return nil, err
+	}
 	err = codec.ValidateChunkSize(int(shareSize))
 	if err != nil {
 		return nil, err
@@ -136,28 +706,42 @@ func NewExtendedDataSquare(codec Codec, treeCreatorFn TreeConstructorFn, edsWidt
 	return &eds, nil
 }
 
-func (eds *ExtendedDataSquare) erasureExtendSquare(codec Codec) error {
+func (eds *ExtendedDataSquare) erasureExtendSquare(ctx context.Context, codec Codec) error {
+	if err := eds.allocExtension(); err != nil {
+		return err
+	}
+	return eds.encodeExtension(ctx, codec)
+}
+
+// allocExtension extends the original data square with zeroed shares, to be
+// populated by encodeExtension.
+func (eds *ExtendedDataSquare) allocExtension() error {
 	eds.originalDataWidth = eds.width
 
-	// Extend original square with filler shares. O represents original data. F
-	// represents filler shares.
+	// Extend original square with zeroed shares. O represents original data.
+	// Z represents zeroed shares. The zeroed shares are allocated up front so
+	// that the erasure data can be encoded directly into them.
 	//
 	//  ------- -------
 	// |       |       |
-	// |   O   |   F   |
+	// |   O   |   Z   |
 	// |       |       |
 	//  ------- -------
 	// |       |       |
-	// |   F   |   F   |
+	// |   Z   |   Z   |
 	// |       |       |
 	//  ------- -------
 	if err := eds.extendSquare(eds.width, bytes.Repeat([]byte{0}, int(eds.shareSize))); err != nil {
 		return err
 	}
+	eds.allocExtendedQuadrants(eds.originalDataWidth)
+	return nil
+}
 
-	errs, _ := errgroup.WithContext(context.Background())
-
-	// Populate filler shares in Q1 and Q2. E represents erasure data.
+// encodeExtension populates the zeroed shares allocated by allocExtension
+// with erasure data.
+func (eds *ExtendedDataSquare) encodeExtension(ctx context.Context, codec Codec) error {
+	// Populate zeroed shares in Q1 and Q2. E represents erasure data.
 	//
 	//  ------- -------
 	// |       |       |
@@ -165,28 +749,21 @@ func (eds *ExtendedDataSquare) erasureExtendSquare(codec Codec) error {
 	// |   ↓   |       |
 	//  ------- -------
 	// |       |       |
-	// |   E   |   F   |
+	// |   E   |   Z   |
 	// |       |       |
 	//  ------- -------
-	for i := uint(0); i < eds.originalDataWidth; i++ {
-		i := i
-
-		// Encode Q0 and populate Q1 with erasure data
-		errs.Go(func() error {
-			return eds.erasureExtendRow(codec, i)
-		})
-
-		// Encode Q0 and populate Q2 with erasure data
-		errs.Go(func() error {
-			return eds.erasureExtendCol(codec, i)
-		})
-	}
-
-	if err := errs.Wait(); err != nil {
+	//
+	// The shares are sliced directly, as the accessors of a lazily extended
+	// square would wait for this encoding. As shares are shared by the
+	// row-major and column-major storage, the columns do not require copying.
+	axes := make([][][]byte, 0, 2*eds.originalDataWidth)
+	axes = append(axes, eds.squareRow[:eds.originalDataWidth]...)
+	axes = append(axes, eds.squareCol[:eds.originalDataWidth]...)
+	if err := encodeAxesInPlace(ctx, codec, axes, eds.originalDataWidth); err != nil {
 		return err
 	}
 
-	// Populate filler shares in Q3. Note that the parity data in `Q3` will be
+	// Populate zeroed shares in Q3. Note that the parity data in `Q3` will be
 	// identical if it is vertically extended from `Q1` or horizontally extended
 	// from `Q2`.
 	//
@@ -199,43 +776,98 @@ func (eds *ExtendedDataSquare) erasureExtendSquare(codec Codec) error {
 	// |   E → |   E   |
 	// |       |       |
 	//  ------- -------
-	for i := eds.originalDataWidth; i < eds.width; i++ {
-		i := i
+	return encodeAxesInPlace(ctx, codec, eds.squareRow[eds.originalDataWidth:eds.width], eds.originalDataWidth)
+}
 
-		// Encode Q2 and populate Q3 with erasure data
+// encodeAxesInPlace encodes the first half shares of every axis in axes
+// directly into its second half. The axes are split into one batch of
+// consecutive axes per CPU, which are encoded concurrently with
+// encodeBatchInto.
+func encodeAxesInPlace(ctx context.Context, codec Codec, axes [][][]byte, half uint) error {
+	errs, _ := errgroup.WithContext(ctx)
+	procs := runtime.GOMAXPROCS(0)
+	batchSize := (len(axes) + procs - 1) / procs
+	for start := 0; start < len(axes); start += batchSize {
+		batch := axes[start:min(start+batchSize, len(axes))]
 		errs.Go(func() error {
-			return eds.erasureExtendRow(codec, i)
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			original := make([][][]byte, len(batch))
+			parity := make([][][]byte, len(batch))
+			for i, axis := range batch {
+				original[i], parity[i] = axis[:half], axis[half:]
+			}
+			return encodeBatchInto(codec, original, parity)
 		})
 	}
-
 	return errs.Wait()
 }
 
-func (eds *ExtendedDataSquare) erasureExtendRow(codec Codec, rowIdx uint) error {
-	parityShares, err := codec.Encode(eds.rowSlice(rowIdx, 0, eds.originalDataWidth))
+// Clone returns a deep copy of the extended data square, including its
+// missing shares and its cached roots. The copy uses the same codec and tree
+// constructor as the original.
+func (eds *ExtendedDataSquare) Clone() (*ExtendedDataSquare, error) {
+	ds, err := newDataSquare(deepCopy(eds.dataSquare.Flattened()), eds.createTreeFn, eds.shareSize)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if eds.rowRoots != nil {
+		ds.rowRoots = deepCopy(eds.rowRoots)
 	}
-	return eds.setRowSlice(rowIdx, eds.originalDataWidth, parityShares)
+	if eds.colRoots != nil {
+		ds.colRoots = deepCopy(eds.colRoots)
+	}
+	return &ExtendedDataSquare{dataSquare: ds, codec: eds.codec, originalDataWidth: eds.originalDataWidth}, nil
 }
 
-func (eds *ExtendedDataSquare) erasureExtendCol(codec Codec, colIdx uint) error {
-	parityShares, err := codec.Encode(eds.colSlice(0, colIdx, eds.originalDataWidth))
-	if err != nil {
+// Grow re-extends the extended data square to an original data square of
+// width newOdsWidth. The shares of the current original data square are kept
+// in the top left of the new one, and the new original cells are filled with
+// zeroed shares, e.g. as padding. The parity quadrants and roots are
+// recomputed. Returns an error if the original data square is incomplete or
+// if newOdsWidth is smaller than the current width or not supported by the
+// codec, in which case the square is not modified.
+func (eds *ExtendedDataSquare) Grow(newOdsWidth uint) error {
+	odsWidth := eds.originalDataWidth
+	if newOdsWidth < odsWidth {
+		return fmt.Errorf("%w: cannot shrink original data square of width %d to %d", ErrUnsupportedWidth, odsWidth, newOdsWidth)
+	}
+	if newOdsWidth == odsWidth {
+		return nil
+	}
+	if err := ValidateWidth(eds.codec, newOdsWidth); err != nil {
 		return err
 	}
-	return eds.setColSlice(colIdx, eds.originalDataWidth, parityShares)
-}
 
-func (eds *ExtendedDataSquare) deepCopy(codec Codec) (ExtendedDataSquare, error) {
-	imported, err := ImportExtendedDataSquare(eds.Flattened(), codec, eds.createTreeFn)
-	return *imported, err
+	data := make([][]byte, newOdsWidth*newOdsWidth)
+	for rowIdx := uint(0); rowIdx < odsWidth; rowIdx++ {
+		row := eds.rowSlice(rowIdx, 0, odsWidth)
+		if !isComplete(row) {
+			return fmt.Errorf("%w: can not grow square with incomplete original row %d", ErrIncompleteAxis, rowIdx)
+		}
+		copy(data[FlatIndex(rowIdx, 0, newOdsWidth):], row)
+	}
+	padding := allocShares(int(newOdsWidth*newOdsWidth-odsWidth*odsWidth), int(eds.shareSize))
+	for i := range data {
+		if data[i] == nil {
+			data[i], padding = padding[0], padding[1:]
+		}
+	}
+
+	grown, err := ComputeExtendedDataSquare(data, eds.codec, eds.createTreeFn)
+	if err != nil {
+		return err
+	}
+	eds.dataSquare = grown.dataSquare
+	eds.originalDataWidth = grown.originalDataWidth
+	return nil
 }
 
 // Col returns a column slice.
 // This slice is a copy of the internal column slice.
 func (eds *ExtendedDataSquare) Col(colIdx uint) [][]byte {
-	return deepCopy(eds.col(colIdx))
+	return eds.colCopy(colIdx)
 }
 
 // ColRoots returns the Merkle roots of all the columns in the square. Returns
@@ -251,7 +883,7 @@ func (eds *ExtendedDataSquare) ColRoots() ([][]byte, error) {
 // Row returns a row slice.
 // This slice is a copy of the internal row slice.
 func (eds *ExtendedDataSquare) Row(rowIdx uint) [][]byte {
-	return deepCopy(eds.row(rowIdx))
+	return eds.rowCopy(rowIdx)
 }
 
 // RowRoots returns the Merkle roots of all the rows in the square. Returns an
@@ -276,28 +908,209 @@ func deepCopy(original [][]byte) [][]byte {
 	return dest
 }
 
+// Reset sets every share in the extended data square to nil while keeping the
+// row-major and column-major slices of share references, so that the square
+// can be repopulated via SetCell. The shares themselves are not reused.
+func (eds *ExtendedDataSquare) Reset() {
+	eds.dataSquare.reset()
+}
+
 // Width returns the width of the square.
 func (eds *ExtendedDataSquare) Width() uint {
 	return eds.width
 }
 
+// OdsWidth returns the width of the original data square, i.e. half the width
+// of the extended data square.
+func (eds *ExtendedDataSquare) OdsWidth() uint {
+	return eds.width / 2
+}
+
+// FlatIndex returns the index of the cell (rowIdx, colIdx) of a square of the
+// given width in its flattened, row-major representation, see Flattened.
+func FlatIndex(rowIdx, colIdx, width uint) uint {
+	return rowIdx*width + colIdx
+}
+
+// CoordinatesOf returns the row and column index of the cell at flatIdx in
+// the flattened, row-major representation of a square of the given width. It
+// is the inverse of FlatIndex.
+func CoordinatesOf(flatIdx, width uint) (rowIdx, colIdx uint) {
+	return flatIdx / width, flatIdx % width
+}
+
+// IsComplete returns true if no share of the extended data square is missing
+// (i.e. nil).
+func (eds *ExtendedDataSquare) IsComplete() bool {
+	for rowIdx := uint(0); rowIdx < eds.width; rowIdx++ {
+		if !isComplete(eds.row(rowIdx)) {
+			return false
+		}
+	}
+	return true
+}
+
+// MissingCells returns the cells of the extended data square whose share is
+// missing (i.e. nil), in row-major order.
+func (eds *ExtendedDataSquare) MissingCells() []CellIndex {
+	var missing []CellIndex
+	for rowIdx := uint(0); rowIdx < eds.width; rowIdx++ {
+		for colIdx, share := range eds.row(rowIdx) {
+			if share == nil {
+				missing = append(missing, CellIndex{Row: rowIdx, Col: uint(colIdx)})
+			}
+		}
+	}
+	return missing
+}
+
 // Flattened returns the extended data square as a flattened slice of bytes.
 func (eds *ExtendedDataSquare) Flattened() [][]byte {
 	return deepCopy(eds.dataSquare.Flattened())
 }
 
-// FlattenedODS returns the original data square as a flattened slice of bytes.
-func (eds *ExtendedDataSquare) FlattenedODS() (flattened [][]byte) {
+// FlattenedBytes returns all shares of the extended data square concatenated
+// in row-major order, in a single allocation. Missing shares are written as
+// zeroed bytes, so the square should be complete, see IsComplete.
+func (eds *ExtendedDataSquare) FlattenedBytes() []byte {
+	return eds.concatShares(eds.width)
+}
+
+// FlattenedODSBytes is like FlattenedBytes, but only returns the shares of the
+// original data square.
+func (eds *ExtendedDataSquare) FlattenedODSBytes() []byte {
+	return eds.concatShares(eds.originalDataWidth)
+}
+
+// concatShares concatenates the shares of the top left width x width
+// sub-square in row-major order.
+func (eds *ExtendedDataSquare) concatShares(width uint) []byte {
+	buf := make([]byte, width*width*eds.shareSize)
+	for rowIdx := uint(0); rowIdx < width; rowIdx++ {
+		for colIdx, share := range eds.rowSlice(rowIdx, 0, width) {
+			copy(buf[FlatIndex(rowIdx, uint(colIdx), width)*eds.shareSize:], share)
+		}
+	}
+	return buf
+}
+
+// ForEachShare calls fn for every share in the extended data square in
+// row-major order without copying the square. The share passed to fn must not
+// be modified. Missing shares are passed as nil. Iteration stops at the first
+// error returned by fn, which is then returned by ForEachShare.
+func (eds *ExtendedDataSquare) ForEachShare(fn func(rowIdx uint, colIdx uint, share []byte) error) error {
+	for rowIdx := uint(0); rowIdx < eds.width; rowIdx++ {
+		for colIdx, share := range eds.row(rowIdx) {
+			if err := fn(rowIdx, uint(colIdx), share); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Cells returns an iterator over the cells of the extended data square in
+// row-major order, which yields the index and share of every cell without
+// copying the square. Missing shares are yielded as nil. The shares must not
+// be modified. The iterator has the signature of an iter.Seq2, so that it can
+// be ranged over from Go 1.23 on.
+func (eds *ExtendedDataSquare) Cells() func(yield func(CellIndex, []byte) bool) {
+	return func(yield func(CellIndex, []byte) bool) {
+		for rowIdx := uint(0); rowIdx < eds.width; rowIdx++ {
+			for colIdx, share := range eds.row(rowIdx) {
+				if !yield(CellIndex{Row: rowIdx, Col: uint(colIdx)}, share) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Rows returns an iterator over the rows of the extended data square, which
+// yields the index and shares of every row without copying them. The shares
+// must not be modified. Like Cells, it has the signature of an iter.Seq2.
+func (eds *ExtendedDataSquare) Rows() func(yield func(uint, [][]byte) bool) {
+	return func(yield func(uint, [][]byte) bool) {
+		for rowIdx := uint(0); rowIdx < eds.width; rowIdx++ {
+			if !yield(rowIdx, eds.row(rowIdx)) {
+				return
+			}
+		}
+	}
+}
+
+// Cols returns an iterator over the columns of the extended data square, which
+// yields the index and shares of every column without copying them. The shares
+// must not be modified. Like Cells, it has the signature of an iter.Seq2.
+func (eds *ExtendedDataSquare) Cols() func(yield func(uint, [][]byte) bool) {
+	return func(yield func(uint, [][]byte) bool) {
+		for colIdx := uint(0); colIdx < eds.width; colIdx++ {
+			if !yield(colIdx, eds.col(colIdx)) {
+				return
+			}
+		}
+	}
+}
+
+// Quadrant identifies one of the four quadrants of an extended data square.
+type Quadrant int
+
+const (
+	// Q0 is the top left quadrant, i.e. the original data square.
+	Q0 Quadrant = iota
+	// Q1 is the top right quadrant, which extends the rows of Q0.
+	Q1
+	// Q2 is the bottom left quadrant, which extends the columns of Q0.
+	Q2
+	// Q3 is the bottom right quadrant, which extends the rows of Q2 and the
+	// columns of Q1.
+	Q3
+)
+
+func (q Quadrant) String() string {
+	switch q {
+	case Q0, Q1, Q2, Q3:
+		return fmt.Sprintf("Q%d", int(q))
+	default:
+		panic(fmt.Sprintf("invalid quadrant: %d", q))
+	}
+}
+
+// Quadrant returns the shares of quadrant q as a flattened slice of bytes in
+// row-major order. Panics if q is not a valid quadrant.
+func (eds *ExtendedDataSquare) Quadrant(q Quadrant) (flattened [][]byte) {
+	var rowOffset, colOffset uint
+	switch q {
+	case Q0:
+	case Q1:
+		colOffset = eds.originalDataWidth
+	case Q2:
+		rowOffset = eds.originalDataWidth
+	case Q3:
+		rowOffset, colOffset = eds.originalDataWidth, eds.originalDataWidth
+	default:
+		panic(fmt.Sprintf("invalid quadrant: %d", q))
+	}
+
 	flattened = make([][]byte, eds.originalDataWidth*eds.originalDataWidth)
 	for rowIdx := uint(0); rowIdx < eds.originalDataWidth; rowIdx++ {
-		row := eds.Row(rowIdx)
-		for colIdx := uint(0); colIdx < eds.originalDataWidth; colIdx++ {
-			flattened[(rowIdx*eds.originalDataWidth)+colIdx] = row[colIdx]
-		}
+		row := deepCopy(eds.rowSlice(rowOffset+rowIdx, colOffset, eds.originalDataWidth))
+		copy(flattened[rowIdx*eds.originalDataWidth:], row)
 	}
 	return flattened
 }
 
+// ODS returns the original data square as a flattened slice of bytes. It is
+// an alias for Quadrant(Q0).
+func (eds *ExtendedDataSquare) ODS() [][]byte {
+	return eds.Quadrant(Q0)
+}
+
+// FlattenedODS returns the original data square as a flattened slice of bytes.
+func (eds *ExtendedDataSquare) FlattenedODS() (flattened [][]byte) {
+	return eds.Quadrant(Q0)
+}
+
 // Equals returns true if other is equal to eds.
 func (eds *ExtendedDataSquare) Equals(other *ExtendedDataSquare) bool {
 	if eds.originalDataWidth != other.originalDataWidth {
@@ -327,6 +1140,34 @@ func (eds *ExtendedDataSquare) Equals(other *ExtendedDataSquare) bool {
 	return true
 }
 
+// ContentHash returns the digest with h of the codec name, the width, the
+// share size and the shares of the square in row-major order, with missing
+// shares distinguished from present ones. Two squares have the same content
+// hash if and only if they are Equals (up to collisions of h), so the hash can
+// be used as a cache key or to compare squares without holding both of them.
+// h is reset first. The hash is not cached but computed on every call, as h
+// may differ between calls and the square may be modified in between; a
+// caller that uses one h and no longer modifies the square can cache it.
+func (eds *ExtendedDataSquare) ContentHash(h hash.Hash) []byte {
+	h.Reset()
+	name := eds.codec.Name()
+	var header []byte
+	header = binary.BigEndian.AppendUint64(header, uint64(len(name)))
+	header = append(header, name...)
+	header = binary.BigEndian.AppendUint64(header, uint64(eds.width))
+	header = binary.BigEndian.AppendUint64(header, uint64(eds.shareSize))
+	h.Write(header)
+	for _, share := range eds.dataSquare.Flattened() {
+		if share == nil {
+			h.Write([]byte{0})
+			continue
+		}
+		h.Write([]byte{1})
+		h.Write(share)
+	}
+	return h.Sum(nil)
+}
+
 // Roots returns a byte slice with this eds's RowRoots and ColRoots
 // concatenated.
 func (eds *ExtendedDataSquare) Roots() (roots [][]byte, err error) {
@@ -345,11 +1186,52 @@ func (eds *ExtendedDataSquare) Roots() (roots [][]byte, err error) {
 	return roots, nil
 }
 
-// validateEdsWidth returns an error if edsWidth is not a valid width for an
-// extended data square.
+// restoreRoots caches the deserialized roots of the square. Unless trusted,
+// the roots of the square are computed and compared against them first.
+// Returns an error wrapping ErrRootMismatch if they do not match.
+func (eds *ExtendedDataSquare) restoreRoots(rowRoots, colRoots [][]byte, trusted bool) error {
+	if uint(len(rowRoots)) != eds.width || uint(len(colRoots)) != eds.width {
+		return fmt.Errorf("got %d row roots and %d column roots for width %d", len(rowRoots), len(colRoots), eds.width)
+	}
+	if trusted {
+		eds.rowRoots = deepCopy(rowRoots)
+		eds.colRoots = deepCopy(colRoots)
+		return nil
+	}
+	if err := eds.computeRoots(); err != nil {
+		return err
+	}
+	for i := uint(0); i < eds.width; i++ {
+		if !bytes.Equal(eds.rowRoots[i], rowRoots[i]) {
+			return fmt.Errorf("%w: row %d", ErrRootMismatch, i)
+		}
+		if !bytes.Equal(eds.colRoots[i], colRoots[i]) {
+			return fmt.Errorf("%w: column %d", ErrRootMismatch, i)
+		}
+	}
+	return nil
+}
+
+// DataRoot returns a single commitment to the square: the root of the Merkle
+// tree, built with hasher, whose leaves are the row roots followed by the
+// column roots, as returned by Roots.
+func (eds *ExtendedDataSquare) DataRoot(hasher hash.Hash) ([]byte, error) {
+	roots, err := eds.Roots()
+	if err != nil {
+		return nil, err
+	}
+	tree := merkletree.New(hasher)
+	for _, root := range roots {
+		tree.Push(root)
+	}
+	return tree.Root(), nil
+}
+
+// validateEdsWidth returns an error wrapping ErrUnsupportedWidth if edsWidth
+// is not a valid width for an extended data square.
 func validateEdsWidth(edsWidth uint) error {
 	if edsWidth%2 != 0 {
-		return fmt.Errorf("extended data square width %v must be even", edsWidth)
+		return fmt.Errorf("%w: extended data square width %v must be even", ErrUnsupportedWidth, edsWidth)
 	}
 
 	return nil
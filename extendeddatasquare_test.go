@@ -2,11 +2,16 @@ package rsmt2d
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"io"
 	"reflect"
 	"sort"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -73,6 +78,156 @@ func TestComputeExtendedDataSquare(t *testing.T) {
 	})
 }
 
+func TestComputeExtendedDataSquareWithContext(t *testing.T) {
+	ods := [][]byte{
+		ones, twos,
+		threes, fours,
+	}
+
+	t.Run("computes the EDS", func(t *testing.T) {
+		eds, err := ComputeExtendedDataSquareWithContext(context.Background(), ods, NewLeoRSCodec(), NewDefaultTree)
+		require.NoError(t, err)
+		assert.True(t, eds.Equals(createExampleEds(t, shareSize)))
+	})
+
+	t.Run("returns an error if the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := ComputeExtendedDataSquareWithContext(ctx, ods, NewLeoRSCodec(), NewDefaultTree)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestComputeExtendedDataSquareEncodesInPlace(t *testing.T) {
+	eds := createExampleEds(t, shareSize)
+
+	// every share must be shared by the row-major and column-major storage,
+	// and every extended share must have its own backing array
+	seen := make(map[*byte]bool)
+	for rowIdx := uint(0); rowIdx < eds.width; rowIdx++ {
+		for colIdx := uint(0); colIdx < eds.width; colIdx++ {
+			share := eds.squareRow[rowIdx][colIdx]
+			assert.Same(t, &share[0], &eds.squareCol[colIdx][rowIdx][0])
+			if rowIdx >= eds.originalDataWidth || colIdx >= eds.originalDataWidth {
+				assert.False(t, seen[&share[0]])
+				seen[&share[0]] = true
+			}
+		}
+	}
+}
+
+func TestComputeExtendedDataSquareLazy(t *testing.T) {
+	codec := NewLeoRSCodec()
+	data := generateRandData(16, shareSize)
+	want, err := ComputeExtendedDataSquare(deepCopy(data), codec, NewDefaultTree)
+	require.NoError(t, err)
+	wantRoots, err := want.Roots()
+	require.NoError(t, err)
+
+	sink := &recordingSink{}
+	SetEventSink(sink)
+	defer SetEventSink(nil)
+
+	eds, err := ComputeExtendedDataSquareLazy(deepCopy(data), codec, NewDefaultTree)
+	require.NoError(t, err)
+	assert.Equal(t, want.Width(), eds.Width())
+
+	// the original data is accessible without encoding the parity quadrants
+	assert.Equal(t, want.ODS(), eds.ODS())
+	assert.Equal(t, want.GetCell(3, 3), eds.GetCell(3, 3))
+	assert.Equal(t, 0, sink.count(EventExtensionFinished))
+
+	// the parity quadrants are encoded once, on first access
+	var wg sync.WaitGroup
+	for i := uint(0); i < eds.Width(); i++ {
+		wg.Add(1)
+		go func(i uint) {
+			defer wg.Done()
+			assert.Equal(t, want.Row(i), eds.Row(i))
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 1, sink.count(EventExtensionFinished))
+
+	roots, err := eds.Roots()
+	require.NoError(t, err)
+	assert.Equal(t, wantRoots, roots)
+	assert.True(t, want.Equals(eds))
+	assert.Equal(t, 1, sink.count(EventExtensionFinished))
+
+	t.Run("roots encode the parity quadrants", func(t *testing.T) {
+		eds, err := ComputeExtendedDataSquareLazy(deepCopy(data), codec, NewDefaultTree)
+		require.NoError(t, err)
+		rowRoots, err := eds.RowRoots()
+		require.NoError(t, err)
+		assert.Equal(t, wantRoots[:want.Width()], rowRoots)
+	})
+}
+
+func TestNonPowerOfTwoWidths(t *testing.T) {
+	for _, codec := range []Codec{NewLeoRSCodec(), NewRSGF8Codec()} {
+		for _, odsWidth := range []int{6, 12} {
+			t.Run(fmt.Sprintf("%s/%d", codec.Name(), odsWidth), func(t *testing.T) {
+				require.NoError(t, ValidateWidth(codec, uint(odsWidth)))
+
+				eds, err := ComputeExtendedDataSquare(generateRandData(odsWidth*odsWidth, 64), codec, NewDefaultTree)
+				require.NoError(t, err)
+				assert.Equal(t, uint(2*odsWidth), eds.Width())
+				rowRoots, err := eds.RowRoots()
+				require.NoError(t, err)
+				colRoots, err := eds.ColRoots()
+				require.NoError(t, err)
+
+				// keep only the original data square
+				flattened := eds.Flattened()
+				for i := range flattened {
+					if i%(2*odsWidth) >= odsWidth || i >= 2*odsWidth*odsWidth {
+						flattened[i] = nil
+					}
+				}
+				imported, err := ImportExtendedDataSquare(flattened, codec, NewDefaultTree)
+				require.NoError(t, err)
+				require.NoError(t, imported.Repair(rowRoots, colRoots))
+				assert.True(t, imported.Equals(eds))
+			})
+		}
+	}
+}
+
+// oddWidthsCodec is a codec that only supports odd original data square widths.
+type oddWidthsCodec struct {
+	*LeoRSCodec
+}
+
+func (c *oddWidthsCodec) ValidateWidth(odsWidth uint) error {
+	if odsWidth%2 == 0 {
+		return fmt.Errorf("width %d must be odd", odsWidth)
+	}
+	return nil
+}
+
+func TestUnsupportedWidth(t *testing.T) {
+	t.Run("ComputeExtendedDataSquare exceeding MaxChunks", func(t *testing.T) {
+		_, err := ComputeExtendedDataSquare(generateRandData(129*129, 1), NewRSGF8Codec(), NewDefaultTree)
+		assert.ErrorIs(t, err, ErrUnsupportedWidth)
+	})
+	t.Run("ComputeExtendedDataSquare with a width rejected by the codec", func(t *testing.T) {
+		codec := &oddWidthsCodec{NewLeoRSCodec()}
+		_, err := ComputeExtendedDataSquare(generateRandData(36, shareSize), codec, NewDefaultTree)
+		assert.ErrorIs(t, err, ErrUnsupportedWidth)
+		_, err = ComputeExtendedDataSquare(generateRandData(25, shareSize), codec, NewDefaultTree)
+		assert.NoError(t, err)
+	})
+	t.Run("ImportExtendedDataSquare with an odd width", func(t *testing.T) {
+		_, err := ImportExtendedDataSquare(generateRandData(9, shareSize), NewLeoRSCodec(), NewDefaultTree)
+		assert.ErrorIs(t, err, ErrUnsupportedWidth)
+	})
+	t.Run("NewExtendedDataSquare exceeding MaxChunks", func(t *testing.T) {
+		_, err := NewExtendedDataSquare(NewRSGF8Codec(), NewDefaultTree, 2*129, 1)
+		assert.ErrorIs(t, err, ErrUnsupportedWidth)
+	})
+}
+
 func TestImportExtendedDataSquare(t *testing.T) {
 	t.Run("is able to import an EDS", func(t *testing.T) {
 		eds := createExampleEds(t, shareSize)
@@ -80,11 +235,165 @@ func TestImportExtendedDataSquare(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, eds.Flattened(), got.Flattened())
 	})
+	t.Run("does not copy the shares", func(t *testing.T) {
+		data := createExampleEds(t, shareSize).Flattened()
+		got, err := ImportExtendedDataSquare(data, NewLeoRSCodec(), NewDefaultTree)
+		require.NoError(t, err)
+		for i, share := range data {
+			assert.Same(t, &share[0], &got.row(uint(i) / 4)[uint(i)%4][0])
+			assert.Same(t, &share[0], &got.col(uint(i) % 4)[uint(i)/4][0])
+		}
+
+		// data itself is copied, so it can be reused
+		want := got.Flattened()
+		data[0] = nil
+		assert.Equal(t, want, got.Flattened())
+	})
+	t.Run("no copy takes ownership of data", func(t *testing.T) {
+		data := createExampleEds(t, shareSize).Flattened()
+		got, err := ImportExtendedDataSquareNoCopy(data, NewLeoRSCodec(), NewDefaultTree)
+		require.NoError(t, err)
+		assert.Same(t, &data[0], &got.row(0)[0])
+		assert.Same(t, &data[len(data)-1], &got.row(3)[3])
+	})
 	t.Run("returns an error if shareSize is not a multiple of 64", func(t *testing.T) {
 		share := bytes.Repeat([]byte{1}, 65)
 		_, err := ImportExtendedDataSquare([][]byte{share}, NewLeoRSCodec(), NewDefaultTree)
 		assert.Error(t, err)
 	})
+	t.Run("returns an error if the width exceeds the limit", func(t *testing.T) {
+		eds := createExampleEds(t, shareSize)
+		_, err := ImportExtendedDataSquare(eds.Flattened(), NewLeoRSCodec(), NewDefaultTree, WithMaxWidth(2))
+		assert.ErrorIs(t, err, ErrSquareTooLarge)
+		_, err = ImportExtendedDataSquare(eds.Flattened(), NewLeoRSCodec(), NewDefaultTree, WithMaxWidth(4))
+		assert.NoError(t, err)
+	})
+	t.Run("returns an error if the size exceeds the limit", func(t *testing.T) {
+		eds := createExampleEds(t, shareSize)
+		_, err := ImportExtendedDataSquare(eds.Flattened(), NewLeoRSCodec(), NewDefaultTree, WithMaxBytes(16*shareSize-1))
+		assert.ErrorIs(t, err, ErrSquareTooLarge)
+		_, err = ImportExtendedDataSquare(eds.Flattened(), NewLeoRSCodec(), NewDefaultTree, WithMaxBytes(16*shareSize))
+		assert.NoError(t, err)
+	})
+	t.Run("returns ErrByzantineData if the parity does not match", func(t *testing.T) {
+		shares := createExampleEds(t, shareSize).Flattened()
+		_, err := ImportExtendedDataSquare(shares, NewLeoRSCodec(), NewDefaultTree, WithParityCheck())
+		assert.NoError(t, err)
+
+		// corrupt a parity share in Q1
+		shares[2] = bytes.Repeat([]byte{42}, shareSize)
+
+		_, err = ImportExtendedDataSquare(shares, NewLeoRSCodec(), NewDefaultTree)
+		assert.NoError(t, err)
+		_, err = ImportExtendedDataSquare(shares, NewLeoRSCodec(), NewDefaultTree, WithParityCheck())
+		var byzErr *ErrByzantineData
+		require.ErrorAs(t, err, &byzErr)
+		assert.Equal(t, uint(0), byzErr.Index)
+
+		// every complete axis is sampled if samples exceeds their number
+		_, err = ImportExtendedDataSquare(shares, NewLeoRSCodec(), NewDefaultTree, WithSampledParityCheck(8))
+		assert.ErrorAs(t, err, &byzErr)
+	})
+	t.Run("skips incomplete axes during the parity check", func(t *testing.T) {
+		shares := createExampleEds(t, shareSize).Flattened()
+		shares[0] = nil
+		_, err := ImportExtendedDataSquare(shares, NewLeoRSCodec(), NewDefaultTree, WithParityCheck())
+		assert.NoError(t, err)
+	})
+	t.Run("UnmarshalJSON honors the default limits", func(t *testing.T) {
+		edsBytes, err := json.Marshal(createExampleEds(t, shareSize))
+		require.NoError(t, err)
+
+		DefaultMaxImportWidth = 2
+		defer func() { DefaultMaxImportWidth = 0 }()
+
+		var eds ExtendedDataSquare
+		err = json.Unmarshal(edsBytes, &eds)
+		assert.ErrorIs(t, err, ErrSquareTooLarge)
+	})
+	t.Run("checkJSONLimits rejects oversized input before decoding", func(t *testing.T) {
+		edsBytes, err := json.Marshal(createExampleEds(t, shareSize))
+		require.NoError(t, err)
+
+		assert.NoError(t, newImportConfig(WithMaxWidth(4), WithMaxBytes(16*shareSize)).checkJSONLimits(edsBytes))
+		assert.ErrorIs(t, newImportConfig(WithMaxWidth(3)).checkJSONLimits(edsBytes), ErrSquareTooLarge)
+		assert.ErrorIs(t, newImportConfig(WithMaxBytes(16*shareSize-1)).checkJSONLimits(edsBytes), ErrSquareTooLarge)
+
+		// the limits are checked before the shares are decoded, so that an
+		// invalid share after the limit is never reached
+		truncated := []byte(`{"codec":"Leopard","data_square":["AA==","AA==","AA==","AA==","AA==","not base64`)
+		assert.ErrorIs(t, newImportConfig(WithMaxWidth(2)).checkJSONLimits(truncated), ErrSquareTooLarge)
+	})
+}
+
+func TestImportAndVerify(t *testing.T) {
+	eds := createExampleEds(t, shareSize)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+
+	shares := eds.Flattened()
+	// rows 0 and 1 miss a share each, so they are decoded before verification
+	shares[0], shares[5] = nil, nil
+	got, err := ImportAndVerify(shares, NewLeoRSCodec(), NewDefaultTree, rowRoots, colRoots)
+	require.NoError(t, err)
+	assert.Equal(t, shares, got.Flattened())
+
+	t.Run("returns ErrByzantineData for a bad share", func(t *testing.T) {
+		corrupted := append([][]byte(nil), shares...)
+		corrupted[6] = bytes.Repeat([]byte{42}, shareSize)
+		_, err := ImportAndVerify(corrupted, NewLeoRSCodec(), NewDefaultTree, rowRoots, colRoots)
+		var byzErr *ErrByzantineData
+		require.ErrorAs(t, err, &byzErr)
+		assert.Equal(t, Row, byzErr.Axis)
+		assert.Equal(t, uint(1), byzErr.Index)
+		assert.Nil(t, byzErr.Shares[1])
+		assert.Equal(t, corrupted[6], byzErr.Shares[2])
+	})
+	t.Run("does not verify axes with fewer than half of their shares", func(t *testing.T) {
+		sparse := make([][]byte, len(shares))
+		sparse[0] = bytes.Repeat([]byte{42}, shareSize)
+		_, err := ImportAndVerify(sparse, NewLeoRSCodec(), NewDefaultTree, rowRoots, colRoots)
+		assert.NoError(t, err)
+	})
+	t.Run("returns an error if the number of roots does not match", func(t *testing.T) {
+		_, err := ImportAndVerify(shares, NewLeoRSCodec(), NewDefaultTree, rowRoots[1:], colRoots)
+		assert.Error(t, err)
+	})
+}
+
+func TestVerifyODS(t *testing.T) {
+	codec := NewLeoRSCodec()
+	eds, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), codec, NewDefaultTree)
+	require.NoError(t, err)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+
+	ods := eds.FlattenedODS()
+	require.NoError(t, VerifyODS(ods, rowRoots, codec, NewDefaultTree))
+
+	t.Run("returns ErrByzantineData for a bad share", func(t *testing.T) {
+		corrupted := append([][]byte(nil), ods...)
+		corrupted[6] = bytes.Repeat([]byte{42}, shareSize)
+		err := VerifyODS(corrupted, rowRoots, codec, NewDefaultTree)
+		var byzErr *ErrByzantineData
+		require.ErrorAs(t, err, &byzErr)
+		assert.Equal(t, Row, byzErr.Axis)
+		assert.Equal(t, uint(1), byzErr.Index)
+		assert.Equal(t, corrupted[4:8], byzErr.Shares[:4])
+		assert.Equal(t, make([][]byte, 4), byzErr.Shares[4:])
+	})
+	t.Run("returns an error for a missing share", func(t *testing.T) {
+		incomplete := append([][]byte(nil), ods...)
+		incomplete[3] = nil
+		assert.ErrorIs(t, VerifyODS(incomplete, rowRoots, codec, NewDefaultTree), ErrIncompleteAxis)
+	})
+	t.Run("returns an error if the number of roots does not match", func(t *testing.T) {
+		assert.Error(t, VerifyODS(ods, rowRoots[2:], codec, NewDefaultTree))
+		assert.ErrorIs(t, VerifyODS(ods, rowRoots[1:], codec, NewDefaultTree), ErrUnsupportedWidth)
+		assert.ErrorIs(t, VerifyODS(nil, nil, codec, NewDefaultTree), ErrUnsupportedWidth)
+	})
 }
 
 func TestMarshalJSON(t *testing.T) {
@@ -110,6 +419,190 @@ func TestMarshalJSON(t *testing.T) {
 	if !reflect.DeepEqual(result.squareRow, eds.squareRow) {
 		t.Errorf("eds not equal after json marshal/unmarshal")
 	}
+
+	t.Run("returns an error for an unregistered codec", func(t *testing.T) {
+		edsBytes, err := json.Marshal(&struct {
+			DataSquare [][]byte `json:"data_square"`
+			Codec      string   `json:"codec"`
+		}{result.Flattened(), "unknown"})
+		require.NoError(t, err)
+
+		var eds ExtendedDataSquare
+		assert.Error(t, json.Unmarshal(edsBytes, &eds))
+	})
+
+	t.Run("serializes the codec version", func(t *testing.T) {
+		var aux struct {
+			Codec map[string]any `json:"codec"`
+		}
+		require.NoError(t, json.Unmarshal(edsBytes, &aux))
+		assert.Equal(t, map[string]any{"name": Leopard, "version": float64(1)}, aux.Codec)
+	})
+
+	t.Run("accepts a codec without version", func(t *testing.T) {
+		edsBytes, err := json.Marshal(&struct {
+			DataSquare [][]byte `json:"data_square"`
+			Codec      string   `json:"codec"`
+		}{result.Flattened(), Leopard})
+		require.NoError(t, err)
+
+		var eds ExtendedDataSquare
+		require.NoError(t, json.Unmarshal(edsBytes, &eds))
+		assert.True(t, eds.Equals(result))
+	})
+
+	t.Run("returns an error for an incompatible codec version", func(t *testing.T) {
+		edsBytes, err := json.Marshal(&struct {
+			DataSquare [][]byte `json:"data_square"`
+			Codec      codecID  `json:"codec"`
+		}{result.Flattened(), codecID{Name: Leopard, Version: 2}})
+		require.NoError(t, err)
+
+		var eds ExtendedDataSquare
+		assert.ErrorIs(t, json.Unmarshal(edsBytes, &eds), ErrIncompatibleCodecVersion)
+	})
+}
+
+func TestSnapshotIncompleteSquare(t *testing.T) {
+	want, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	rowRoots, err := want.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := want.ColRoots()
+	require.NoError(t, err)
+
+	incomplete, err := want.Clone()
+	require.NoError(t, err)
+	for rowIdx := uint(0); rowIdx < incomplete.Width(); rowIdx++ {
+		for colIdx := uint(0); colIdx < incomplete.Width(); colIdx++ {
+			if (rowIdx+colIdx)%3 == 0 {
+				incomplete.clearCell(rowIdx, colIdx)
+			}
+		}
+	}
+	empty, err := want.Clone()
+	require.NoError(t, err)
+	for rowIdx := uint(0); rowIdx < empty.Width(); rowIdx++ {
+		for colIdx := uint(0); colIdx < empty.Width(); colIdx++ {
+			empty.clearCell(rowIdx, colIdx)
+		}
+	}
+
+	formats := map[string]struct {
+		marshal   func(*ExtendedDataSquare) ([]byte, error)
+		unmarshal func(*ExtendedDataSquare, []byte) error
+	}{
+		"JSON":   {(*ExtendedDataSquare).MarshalJSON, (*ExtendedDataSquare).UnmarshalJSON},
+		"binary": {(*ExtendedDataSquare).MarshalBinary, (*ExtendedDataSquare).UnmarshalBinary},
+		"CBOR":   {(*ExtendedDataSquare).MarshalCBOR, (*ExtendedDataSquare).UnmarshalCBOR},
+	}
+	for name, format := range formats {
+		t.Run(name, func(t *testing.T) {
+			for _, snapshot := range []*ExtendedDataSquare{incomplete, empty} {
+				b, err := format.marshal(snapshot)
+				require.NoError(t, err)
+				var restored ExtendedDataSquare
+				require.NoError(t, format.unmarshal(&restored, b))
+				assert.True(t, snapshot.Equals(&restored))
+				assert.Equal(t, snapshot.MissingCells(), restored.MissingCells())
+			}
+
+			b, err := format.marshal(incomplete)
+			require.NoError(t, err)
+			var restored ExtendedDataSquare
+			require.NoError(t, format.unmarshal(&restored, b))
+			require.NoError(t, restored.Repair(rowRoots, colRoots))
+			assert.True(t, want.Equals(&restored))
+		})
+	}
+}
+
+func TestMarshalODS(t *testing.T) {
+	want, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	wantRoots, err := want.Roots()
+	require.NoError(t, err)
+
+	odsBytes, err := want.MarshalODS()
+	require.NoError(t, err)
+	edsBytes, err := json.Marshal(want)
+	require.NoError(t, err)
+	assert.Less(t, 3*len(odsBytes), len(edsBytes))
+
+	var eds ExtendedDataSquare
+	require.NoError(t, eds.UnmarshalODS(odsBytes))
+	assert.True(t, want.Equals(&eds))
+	roots, err := eds.Roots()
+	require.NoError(t, err)
+	assert.Equal(t, wantRoots, roots)
+
+	t.Run("honors the default limits", func(t *testing.T) {
+		DefaultMaxImportWidth = want.Width() - 1
+		defer func() { DefaultMaxImportWidth = 0 }()
+
+		var eds ExtendedDataSquare
+		assert.ErrorIs(t, eds.UnmarshalODS(odsBytes), ErrSquareTooLarge)
+
+		// the limits are checked before the shares are decoded
+		DefaultMaxImportWidth = 2
+		truncated := []byte(`{"codec":{"name":"Leopard"},"original_data_square":["AA==","AA==","not base64`)
+		assert.ErrorIs(t, eds.UnmarshalODS(truncated), ErrSquareTooLarge)
+	})
+
+	t.Run("returns an error for an incompatible codec version", func(t *testing.T) {
+		odsBytes, err := json.Marshal(&struct {
+			ODS   [][]byte `json:"original_data_square"`
+			Codec codecID  `json:"codec"`
+		}{want.FlattenedODS(), codecID{Name: Leopard, Version: 2}})
+		require.NoError(t, err)
+
+		var eds ExtendedDataSquare
+		assert.ErrorIs(t, eds.UnmarshalODS(odsBytes), ErrIncompatibleCodecVersion)
+	})
+
+	t.Run("returns an error for an incomplete original data square", func(t *testing.T) {
+		incomplete, err := want.Clone()
+		require.NoError(t, err)
+		incomplete.clearCell(1, 0)
+		_, err = incomplete.MarshalODS()
+		assert.ErrorIs(t, err, ErrIncompleteAxis)
+	})
+}
+
+func TestUnmarshalJSONWith(t *testing.T) {
+	// versionTwoCodec is not registered
+	codec := &versionTwoCodec{NewLeoRSCodec()}
+	want, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), codec, NewDefaultTree)
+	require.NoError(t, err)
+
+	var trees atomic.Int64
+	treeFn := func(axis Axis, index uint) Tree {
+		trees.Add(1)
+		return NewDefaultTree(axis, index)
+	}
+
+	for _, opts := range [][]MarshalOption{nil, {WithCompression(Deflate)}} {
+		edsBytes, err := want.MarshalJSONWith(opts...)
+		require.NoError(t, err)
+
+		var unregistered ExtendedDataSquare
+		assert.ErrorIs(t, json.Unmarshal(edsBytes, &unregistered), ErrCodecNotRegistered)
+
+		eds, err := UnmarshalJSONWith(edsBytes, codec, treeFn)
+		require.NoError(t, err)
+		assert.True(t, want.Equals(eds))
+		assert.Equal(t, codec, eds.codec)
+
+		trees.Store(0)
+		_, err = eds.RowRoots()
+		require.NoError(t, err)
+		assert.Positive(t, trees.Load())
+
+		_, err = UnmarshalJSONWith(edsBytes, NewLeoRSCodec(), treeFn)
+		assert.Error(t, err)
+		_, err = UnmarshalJSONWith(edsBytes, codec, treeFn, WithMaxWidth(want.Width()-1))
+		assert.ErrorIs(t, err, ErrSquareTooLarge)
+	}
 }
 
 func TestNewExtendedDataSquare(t *testing.T) {
@@ -376,6 +869,102 @@ func TestFlattened_EDS(t *testing.T) {
 	assert.Equal(t, want, got)
 }
 
+func TestCoordinateConversion(t *testing.T) {
+	eds := createExampleEds(t, shareSize)
+	assert.Equal(t, uint(2), eds.OdsWidth())
+
+	flattened := eds.Flattened()
+	for i := range flattened {
+		rowIdx, colIdx := CoordinatesOf(uint(i), eds.Width())
+		assert.Equal(t, flattened[i], eds.GetCell(rowIdx, colIdx))
+		assert.Equal(t, uint(i), FlatIndex(rowIdx, colIdx, eds.Width()))
+	}
+}
+
+func TestMissingCells(t *testing.T) {
+	eds := createExampleEds(t, shareSize)
+	assert.True(t, eds.IsComplete())
+	assert.Empty(t, eds.MissingCells())
+
+	shares := eds.Flattened()
+	shares[3], shares[8] = nil, nil
+	eds, err := ImportExtendedDataSquare(shares, NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	assert.False(t, eds.IsComplete())
+	assert.Equal(t, []CellIndex{{0, 3}, {2, 0}}, eds.MissingCells())
+}
+
+func TestForEachShare(t *testing.T) {
+	eds := createExampleEds(t, shareSize)
+
+	var got [][]byte
+	err := eds.ForEachShare(func(rowIdx uint, colIdx uint, share []byte) error {
+		assert.Equal(t, eds.GetCell(rowIdx, colIdx), share)
+		got = append(got, share)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, eds.Flattened(), got)
+
+	t.Run("stops on error", func(t *testing.T) {
+		wantErr := fmt.Errorf("stop")
+		visited := 0
+		err := eds.ForEachShare(func(_ uint, colIdx uint, _ []byte) error {
+			visited++
+			if colIdx == 1 {
+				return wantErr
+			}
+			return nil
+		})
+		assert.ErrorIs(t, err, wantErr)
+		assert.Equal(t, 2, visited)
+	})
+}
+
+func TestIterators(t *testing.T) {
+	eds := createExampleEds(t, shareSize)
+
+	var cells [][]byte
+	eds.Cells()(func(idx CellIndex, share []byte) bool {
+		assert.Equal(t, eds.GetCell(idx.Row, idx.Col), share)
+		cells = append(cells, share)
+		return true
+	})
+	assert.Equal(t, eds.Flattened(), cells)
+
+	var rows, cols [][][]byte
+	eds.Rows()(func(rowIdx uint, row [][]byte) bool {
+		assert.Equal(t, uint(len(rows)), rowIdx)
+		rows = append(rows, row)
+		return true
+	})
+	eds.Cols()(func(colIdx uint, col [][]byte) bool {
+		assert.Equal(t, uint(len(cols)), colIdx)
+		cols = append(cols, col)
+		return true
+	})
+	for i := uint(0); i < eds.Width(); i++ {
+		assert.Equal(t, eds.Row(i), rows[i])
+		assert.Equal(t, eds.Col(i), cols[i])
+	}
+
+	t.Run("stops early", func(t *testing.T) {
+		visited := 0
+		eds.Cells()(func(idx CellIndex, _ []byte) bool {
+			visited++
+			return idx.Col != 1
+		})
+		assert.Equal(t, 2, visited)
+
+		visited = 0
+		eds.Rows()(func(uint, [][]byte) bool {
+			visited++
+			return false
+		})
+		assert.Equal(t, 1, visited)
+	})
+}
+
 func TestFlattenedODS(t *testing.T) {
 	example := createExampleEds(t, shareSize)
 	want := [][]byte{
@@ -387,6 +976,30 @@ func TestFlattenedODS(t *testing.T) {
 	assert.Equal(t, want, got)
 }
 
+func TestQuadrant(t *testing.T) {
+	example := createExampleEds(t, shareSize)
+	flattened := example.Flattened()
+	// flattened indexes of the shares of each quadrant of the 4x4 square
+	want := map[Quadrant][]int{
+		Q0: {0, 1, 4, 5},
+		Q1: {2, 3, 6, 7},
+		Q2: {8, 9, 12, 13},
+		Q3: {10, 11, 14, 15},
+	}
+	for q, indexes := range want {
+		t.Run(q.String(), func(t *testing.T) {
+			shares := make([][]byte, len(indexes))
+			for i, idx := range indexes {
+				shares[i] = flattened[idx]
+			}
+			assert.Equal(t, shares, example.Quadrant(q))
+		})
+	}
+
+	assert.Equal(t, [][]byte{ones, twos, threes, fours}, example.ODS())
+	assert.Panics(t, func() { example.Quadrant(Quadrant(4)) })
+}
+
 func TestEquals(t *testing.T) {
 	t.Run("returns true for two equal EDS", func(t *testing.T) {
 		a := createExampleEds(t, shareSize)
@@ -439,6 +1052,39 @@ func TestEquals(t *testing.T) {
 	})
 }
 
+func TestContentHash(t *testing.T) {
+	a := createExampleEds(t, shareSize)
+	want := a.ContentHash(sha256.New())
+	assert.Equal(t, want, createExampleEds(t, shareSize).ContentHash(sha256.New()))
+
+	h := sha256.New()
+	h.Write([]byte("leftover"))
+	assert.Equal(t, want, a.ContentHash(h), "the hash must be reset")
+
+	unequalCodecs := createExampleEds(t, shareSize)
+	unequalCodecs.codec = newTestCodec()
+	modified := createExampleEds(t, shareSize)
+	modified.clearCell(0, 0)
+	require.NoError(t, modified.SetCell(0, 0, twos))
+	missing := createExampleEds(t, shareSize)
+	missing.clearCell(0, 0)
+	zeroed := createExampleEds(t, shareSize)
+	zeroed.clearCell(0, 0)
+	require.NoError(t, zeroed.SetCell(0, 0, make([]byte, shareSize)))
+
+	for name, other := range map[string]*ExtendedDataSquare{
+		"unequal codecs":     unequalCodecs,
+		"unequal share size": createExampleEds(t, shareSize*2),
+		"modified share":     modified,
+		"missing share":      missing,
+		"zero share":         zeroed,
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.NotEqual(t, want, other.ContentHash(sha256.New()))
+		})
+	}
+}
+
 func TestRoots(t *testing.T) {
 	t.Run("returns roots for a 4x4 EDS", func(t *testing.T) {
 		eds, err := ComputeExtendedDataSquare([][]byte{
@@ -482,6 +1128,42 @@ func TestRoots(t *testing.T) {
 	})
 }
 
+func TestDataRoot(t *testing.T) {
+	eds := createExampleEds(t, shareSize)
+	roots, err := eds.Roots()
+	require.NoError(t, err)
+	require.Len(t, roots, 8)
+
+	// the data root is the RFC 6962 root of the 8 row and column roots
+	sum := func(prefix byte, data ...[]byte) []byte {
+		h := sha256.New()
+		h.Write([]byte{prefix})
+		for _, d := range data {
+			h.Write(d)
+		}
+		return h.Sum(nil)
+	}
+	level := make([][]byte, len(roots))
+	for i, root := range roots {
+		level[i] = sum(0, root)
+	}
+	for len(level) > 1 {
+		next := make([][]byte, len(level)/2)
+		for i := range next {
+			next[i] = sum(1, level[2*i], level[2*i+1])
+		}
+		level = next
+	}
+
+	dataRoot, err := eds.DataRoot(sha256.New())
+	require.NoError(t, err)
+	assert.Equal(t, level[0], dataRoot)
+
+	eds.setCell(0, 0, nil)
+	_, err = eds.DataRoot(sha256.New())
+	assert.Error(t, err)
+}
+
 func TestDeepCopy(t *testing.T) {
 	original := make([][]byte, 16)
 	// fill first 8 shares with random data, leave the rest nil
@@ -499,6 +1181,42 @@ func TestDeepCopy(t *testing.T) {
 	require.NotEqual(t, original, copied)
 }
 
+func TestClone(t *testing.T) {
+	var treeCalls atomic.Int64
+	treeFn := func(axis Axis, index uint) Tree {
+		treeCalls.Add(1)
+		return NewDefaultTree(axis, index)
+	}
+	eds, err := ComputeExtendedDataSquare(generateRandData(4, shareSize), NewLeoRSCodec(), treeFn)
+	require.NoError(t, err)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+
+	clone, err := eds.Clone()
+	require.NoError(t, err)
+	assert.True(t, eds.Equals(clone))
+	assert.Equal(t, eds.codec, clone.codec)
+
+	// the cached roots are copied instead of being recomputed
+	calls := treeCalls.Load()
+	cloneRowRoots, err := clone.RowRoots()
+	require.NoError(t, err)
+	assert.Equal(t, rowRoots, cloneRowRoots)
+	assert.Equal(t, calls, treeCalls.Load())
+
+	// the clone uses the tree constructor of the original
+	clone.clearCell(0, 0)
+	require.NoError(t, clone.SetCell(0, 0, eds.GetCell(0, 0)))
+	_, err = clone.RowRoots()
+	require.NoError(t, err)
+	assert.Greater(t, treeCalls.Load(), calls)
+
+	// modifying the clone does not affect the original
+	clone.clearCell(0, 0)
+	assert.NotNil(t, eds.GetCell(0, 0))
+	assert.True(t, eds.IsComplete())
+}
+
 func createExampleEds(t *testing.T, shareSize int) (eds *ExtendedDataSquare) {
 	ones := bytes.Repeat([]byte{1}, shareSize)
 	twos := bytes.Repeat([]byte{2}, shareSize)
@@ -513,3 +1231,215 @@ func createExampleEds(t *testing.T, shareSize int) (eds *ExtendedDataSquare) {
 	require.NoError(t, err)
 	return eds
 }
+
+func TestConcurrentSetAndGetCell(t *testing.T) {
+	want, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	wantRoots, err := want.RowRoots()
+	require.NoError(t, err)
+
+	eds, err := NewExtendedDataSquare(NewLeoRSCodec(), NewDefaultTree, want.Width(), shareSize)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for rowIdx := uint(0); rowIdx < eds.Width(); rowIdx++ {
+		rowIdx := rowIdx
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for colIdx, share := range want.Row(rowIdx) {
+				assert.NoError(t, eds.SetCell(rowIdx, uint(colIdx), share))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for colIdx := uint(0); colIdx < eds.Width(); colIdx++ {
+				if cell := eds.GetCell(rowIdx, colIdx); cell != nil {
+					assert.Equal(t, want.GetCell(rowIdx, colIdx), cell)
+				}
+				eds.Col(colIdx)
+			}
+			eds.Row(rowIdx)
+			eds.Flattened()
+		}()
+	}
+	wg.Wait()
+
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	assert.Equal(t, wantRoots, rowRoots)
+}
+
+func TestFlattenedBytes(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+
+	data := eds.FlattenedBytes()
+	assert.Equal(t, bytes.Join(eds.Flattened(), nil), data)
+	assert.Equal(t, bytes.Join(eds.FlattenedODS(), nil), eds.FlattenedODSBytes())
+
+	imported, err := ImportFromBytes(data, shareSize, eds.Width(), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	assert.True(t, eds.Equals(imported))
+	// the shares alias the imported bytes
+	assert.Same(t, &data[shareSize], &imported.row(0)[1][0])
+
+	_, err = ImportFromBytes(data[1:], shareSize, eds.Width(), NewLeoRSCodec(), NewDefaultTree)
+	assert.Error(t, err)
+	_, err = ImportFromBytes(data, 0, eds.Width(), NewLeoRSCodec(), NewDefaultTree)
+	assert.ErrorIs(t, err, ErrInvalidShareSize)
+	_, err = ImportFromBytes(data, shareSize, eds.Width(), NewLeoRSCodec(), NewDefaultTree, WithMaxWidth(eds.Width()/2))
+	assert.Error(t, err)
+}
+
+func TestImportFromReader(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	data := eds.FlattenedBytes()
+
+	r := bytes.NewReader(append(append([]byte(nil), data...), "trailing"...))
+	imported, err := ImportFromReader(r, shareSize, eds.Width(), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	assert.True(t, eds.Equals(imported))
+	assert.Equal(t, len("trailing"), r.Len())
+
+	_, err = ImportFromReader(bytes.NewReader(data[1:]), shareSize, eds.Width(), NewLeoRSCodec(), NewDefaultTree)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	_, err = ImportFromReader(bytes.NewReader(nil), shareSize, eds.Width(), NewLeoRSCodec(), NewDefaultTree)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	_, err = ImportFromReader(bytes.NewReader(data), 0, eds.Width(), NewLeoRSCodec(), NewDefaultTree)
+	assert.ErrorIs(t, err, ErrInvalidShareSize)
+
+	// limits are checked before anything is read or allocated
+	r = bytes.NewReader(data)
+	_, err = ImportFromReader(r, shareSize, 1<<15, NewLeoRSCodec(), NewDefaultTree, WithMaxWidth(eds.Width()))
+	assert.ErrorIs(t, err, ErrSquareTooLarge)
+	assert.Equal(t, len(data), r.Len())
+}
+
+func TestGrow(t *testing.T) {
+	ods := generateRandData(4, shareSize)
+	eds, err := ComputeExtendedDataSquare(ods, NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	_, err = eds.RowRoots()
+	require.NoError(t, err)
+
+	require.NoError(t, eds.Grow(4))
+	assert.Equal(t, uint(8), eds.Width())
+
+	padded := make([][]byte, 16)
+	for i := range padded {
+		padded[i] = make([]byte, shareSize)
+	}
+	for i, share := range ods {
+		padded[FlatIndex(uint(i)/2, uint(i)%2, 4)] = share
+	}
+	want, err := ComputeExtendedDataSquare(padded, NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	assert.True(t, want.Equals(eds))
+	wantRoots, err := want.RowRoots()
+	require.NoError(t, err)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	assert.Equal(t, wantRoots, rowRoots)
+
+	require.NoError(t, eds.Grow(4))
+	assert.True(t, want.Equals(eds))
+
+	t.Run("invalid width", func(t *testing.T) {
+		assert.ErrorIs(t, eds.Grow(2), ErrUnsupportedWidth)
+		assert.ErrorIs(t, eds.Grow(uint(MaxAxisShares(eds.codec))+1), ErrUnsupportedWidth)
+		assert.True(t, want.Equals(eds))
+	})
+
+	t.Run("incomplete original data", func(t *testing.T) {
+		eds.clearCell(1, 1)
+		assert.ErrorIs(t, eds.Grow(8), ErrIncompleteAxis)
+		assert.Equal(t, uint(8), eds.Width())
+	})
+}
+
+func TestWithTree(t *testing.T) {
+	var calls atomic.Int64
+	require.NoError(t, RegisterTreeFactory("counting", func(params json.RawMessage) (TreeConstructorFn, error) {
+		var depth int
+		if err := json.Unmarshal(params, &depth); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTreeParams, err)
+		}
+		return func(axis Axis, index uint) Tree {
+			calls.Add(1)
+			return NewDefaultTree(axis, index)
+		}, nil
+	}))
+	defer DeregisterTree("counting")
+	assert.ErrorIs(t, RegisterTreeFactory("counting", nil), ErrTreeAlreadyRegistered)
+	assert.Contains(t, Trees(), DefaultTreeName)
+
+	want, err := ComputeExtendedDataSquare(generateRandData(4, shareSize), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+
+	for _, opts := range [][]MarshalOption{nil, {WithCompression(Deflate)}} {
+		edsBytes, err := want.MarshalJSONWith(append(opts, WithTree("counting", 3))...)
+		require.NoError(t, err)
+		var eds ExtendedDataSquare
+		require.NoError(t, json.Unmarshal(edsBytes, &eds))
+		assert.True(t, want.Equals(&eds))
+		calls.Store(0)
+		_, err = eds.RowRoots()
+		require.NoError(t, err)
+		assert.Positive(t, calls.Load())
+	}
+
+	t.Run("no tree uses the default tree", func(t *testing.T) {
+		edsBytes, err := json.Marshal(want)
+		require.NoError(t, err)
+		assert.NotContains(t, string(edsBytes), `"tree"`)
+	})
+
+	t.Run("missing parameters", func(t *testing.T) {
+		edsBytes, err := want.MarshalJSONWith(WithTree("counting", nil))
+		require.NoError(t, err)
+		var eds ExtendedDataSquare
+		assert.ErrorIs(t, json.Unmarshal(edsBytes, &eds), ErrInvalidTreeParams)
+	})
+
+	t.Run("unregistered tree", func(t *testing.T) {
+		edsBytes, err := want.MarshalJSONWith(WithTree("unknown", nil))
+		require.NoError(t, err)
+		var eds ExtendedDataSquare
+		assert.ErrorIs(t, json.Unmarshal(edsBytes, &eds), ErrTreeNotRegistered)
+	})
+}
+
+func TestReplaceTree(t *testing.T) {
+	factory := func(json.RawMessage) (TreeConstructorFn, error) {
+		return nil, ErrInvalidTreeParams
+	}
+	original, ok := GetTree(DefaultTreeName)
+	require.True(t, ok)
+
+	restore := ReplaceTree(DefaultTreeName, factory)
+	got, ok := GetTree(DefaultTreeName)
+	require.True(t, ok)
+	_, err := got(nil)
+	assert.ErrorIs(t, err, ErrInvalidTreeParams)
+	restore()
+	got, ok = GetTree(DefaultTreeName)
+	require.True(t, ok)
+	treeFn, err := got(nil)
+	require.NoError(t, err)
+	wantFn, err := original(nil)
+	require.NoError(t, err)
+	assert.IsType(t, wantFn(Row, 0), treeFn(Row, 0))
+
+	t.Run("restoring a new tree deregisters it", func(t *testing.T) {
+		restore := ReplaceTree("replaced", factory)
+		assert.Contains(t, Trees(), "replaced")
+		restore()
+		assert.NotContains(t, Trees(), "replaced")
+	})
+
+	t.Run("MustRegisterTree panics if the tree is registered", func(t *testing.T) {
+		assert.Panics(t, func() { MustRegisterTree(DefaultTreeName, factory) })
+	})
+}
@@ -0,0 +1,225 @@
+package rsmt2d
+
+import "fmt"
+
+// Fountain is an experimental systematic random linear fountain codec over
+// GF(2^8), intended for comparing 2D Reed-Solomon with rateless codes for
+// data availability sampling.
+//
+// Each repair share j is a linear combination of the original shares with
+// pseudo-random coefficients that only depend on j, so repair shares can be
+// generated indefinitely (the code is rateless) and any prefix of them is
+// valid for any number of repair shares. Unlike Reed-Solomon, the code is not
+// MDS: decoding from exactly half of the shares fails with a small
+// probability, in which case more shares are needed.
+const Fountain = "Fountain"
+
+func init() {
+	registerCodec(Fountain, NewFountainCodec())
+}
+
+type FountainCodec struct{}
+
+func (c *FountainCodec) Encode(data [][]byte) ([][]byte, error) {
+	parity := allocShares(len(data), len(data[0]))
+	if err := c.EncodeInto(data, parity); err != nil {
+		return nil, err
+	}
+	return parity, nil
+}
+
+func (c *FountainCodec) EncodeInto(data [][]byte, parityOut [][]byte) error {
+	if len(parityOut) != len(data) {
+		return fmt.Errorf("%w: parityOut has %d shares but data has %d shares", ErrShareCountMismatch, len(parityOut), len(data))
+	}
+	shareSize := len(data[0])
+	for i, share := range data {
+		if len(share) != shareSize {
+			return fmt.Errorf("%w: share %d has size %d, expected %d", ErrShardSizeMismatch, i, len(share), shareSize)
+		}
+	}
+	for j, out := range parityOut {
+		if len(out) != shareSize {
+			return fmt.Errorf("%w: parity share %d has size %d, expected %d", ErrShardSizeMismatch, j, len(out), shareSize)
+		}
+		clear(out)
+		for i, share := range data {
+			gfMulAdd(out, share, fountainCoefficient(j, i))
+		}
+	}
+	return nil
+}
+
+// Decode recovers the missing shares by Gaussian elimination over all
+// present shares. Returns an error wrapping ErrTooFewShares if the present
+// shares do not determine the original shares.
+func (c *FountainCodec) Decode(data [][]byte) ([][]byte, error) {
+	half := len(data) / 2
+	shareSize := getShareSize(data)
+	if shareSize == 0 {
+		return nil, fmt.Errorf("%w: all shares are missing", ErrTooFewShares)
+	}
+
+	// Build the system of equations coefs * original = values from the
+	// present shares.
+	var coefs, values [][]byte
+	for k, share := range data {
+		if len(share) == 0 {
+			continue
+		}
+		if len(share) != shareSize {
+			return nil, fmt.Errorf("%w: share %d has size %d, expected %d", ErrShardSizeMismatch, k, len(share), shareSize)
+		}
+		row := make([]byte, half)
+		if k < half {
+			row[k] = 1
+		} else {
+			for i := range row {
+				row[i] = fountainCoefficient(k-half, i)
+			}
+		}
+		coefs = append(coefs, row)
+		values = append(values, append([]byte(nil), share...))
+	}
+
+	// Reduce the system to the identity with Gauss-Jordan elimination.
+	for col := 0; col < half; col++ {
+		pivot := -1
+		for r := col; r < len(coefs); r++ {
+			if coefs[r][col] != 0 {
+				pivot = r
+				break
+			}
+		}
+		if pivot == -1 {
+			return nil, fmt.Errorf("%w: present shares determine less than %d original shares", ErrTooFewShares, half)
+		}
+		coefs[col], coefs[pivot] = coefs[pivot], coefs[col]
+		values[col], values[pivot] = values[pivot], values[col]
+
+		inv := gfInv(coefs[col][col])
+		gfMul(coefs[col], inv)
+		gfMul(values[col], inv)
+		for r := range coefs {
+			if r == col || coefs[r][col] == 0 {
+				continue
+			}
+			factor := coefs[r][col]
+			gfMulAdd(coefs[r], coefs[col], factor)
+			gfMulAdd(values[r], values[col], factor)
+		}
+	}
+
+	for i := 0; i < half; i++ {
+		if len(data[i]) == 0 {
+			data[i] = values[i]
+		}
+	}
+	for j := 0; j < half; j++ {
+		if len(data[half+j]) != 0 {
+			continue
+		}
+		share := make([]byte, shareSize)
+		for i := 0; i < half; i++ {
+			gfMulAdd(share, data[i], fountainCoefficient(j, i))
+		}
+		data[half+j] = share
+	}
+	return data, nil
+}
+
+// MaxChunks returns the max number of shares this codec supports in a 2D
+// original data square.
+func (c *FountainCodec) MaxChunks() int {
+	maxODSWidth := c.MaxAxisShares()
+	return maxODSWidth * maxODSWidth
+}
+
+// MaxAxisShares returns the max number of original shares per row or column
+// this codec supports.
+func (c *FountainCodec) MaxAxisShares() int {
+	// The code is rateless so there is no hard limit, but decoding is cubic in
+	// the ODS width so it is limited to keep repairs practical.
+	return 512
+}
+
+func (c *FountainCodec) Name() string {
+	return Fountain
+}
+
+// SupportedChunkSizes returns the min and max supported share size and the
+// number of bytes supported share sizes must be a multiple of. A max of zero
+// means there is no maximum.
+func (c *FountainCodec) SupportedChunkSizes() (minSize, maxSize, multiple int) {
+	return 1, 0, 1
+}
+
+// ValidateChunkSize returns an error if this codec does not support
+// shareSize. Returns nil if shareSize is supported.
+func (c *FountainCodec) ValidateChunkSize(shareSize int) error {
+	// Shares are coded byte by byte so any non-zero size is supported.
+	if shareSize <= 0 {
+		return fmt.Errorf("%w: shareSize %v must be positive", ErrInvalidShareSize, shareSize)
+	}
+	return nil
+}
+
+func NewFountainCodec() *FountainCodec {
+	return &FountainCodec{}
+}
+
+// fountainCoefficient returns the non-zero coefficient of original share i in
+// repair share j.
+func fountainCoefficient(j, i int) byte {
+	// splitmix64
+	x := uint64(j)<<32 | uint64(uint32(i))
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	x ^= x >> 31
+	return byte(x%255) + 1
+}
+
+// gfExp and gfLog are the exponent and logarithm tables of GF(2^8) with the
+// primitive polynomial x^8 + x^4 + x^3 + x^2 + 1.
+var gfExp, gfLog = func() (exp [510]byte, log [256]byte) {
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i] = byte(x)
+		exp[i+255] = byte(x)
+		log[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11d
+		}
+	}
+	return exp, log
+}()
+
+func gfMulByte(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+func gfInv(a byte) byte {
+	return gfExp[255-int(gfLog[a])]
+}
+
+// gfMul sets dst to dst * c.
+func gfMul(dst []byte, c byte) {
+	for i := range dst {
+		dst[i] = gfMulByte(dst[i], c)
+	}
+}
+
+// gfMulAdd sets dst to dst + src * c.
+func gfMulAdd(dst, src []byte, c byte) {
+	if c == 0 {
+		return
+	}
+	for i := range dst {
+		dst[i] ^= gfMulByte(src[i], c)
+	}
+}
@@ -0,0 +1,165 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidBadEncodingProof is returned by VerifyBadEncoding when a
+// BadEncodingProof does not prove that its axis is incorrectly encoded.
+var ErrInvalidBadEncodingProof = errors.New("invalid bad encoding proof")
+
+// ErrNotVerifyingTree is returned when an inclusion proof must be verified
+// with a Tree implementation that cannot verify it.
+var ErrNotVerifyingTree = errors.New("tree does not implement VerifyingTree")
+
+// BadEncodingProof proves that a row or column of an extended data square is
+// incorrectly encoded, i.e. that its shares are not a codeword of the codec or
+// do not match its root. It can be verified with VerifyBadEncoding using only
+// the roots of the square.
+type BadEncodingProof struct {
+	// Axis is the axis of the Byzantine row or column.
+	Axis Axis
+	// Index is the index of the Byzantine row or column.
+	Index uint
+	// Shares are the shares of the Byzantine axis that are proven by Proofs.
+	// At least half of them are non-nil.
+	Shares [][]byte
+	// Proofs contain, for each non-nil share, its inclusion proof against
+	// the root of the orthogonal axis.
+	Proofs []*ShareProof
+}
+
+// NewBadEncodingProof returns the BadEncodingProof for byzErr, as returned by
+// Repair of eds. The proofs attached to byzErr are used if present, and are
+// otherwise generated from eds, whose Tree must implement ProvingTree. Shares
+// whose orthogonal axis is incomplete are left out. Returns an error if fewer
+// than half of the shares of the axis can be proven.
+func NewBadEncodingProof(byzErr *ErrByzantineData, eds *ExtendedDataSquare) (*BadEncodingProof, error) {
+	if uint(len(byzErr.Shares)) != eds.width {
+		return nil, fmt.Errorf("got %d shares for width %d", len(byzErr.Shares), eds.width)
+	}
+	orthogonal := Col
+	if byzErr.Axis == Col {
+		orthogonal = Row
+	}
+
+	proof := &BadEncodingProof{
+		Axis:   byzErr.Axis,
+		Index:  byzErr.Index,
+		Shares: make([][]byte, eds.width),
+		Proofs: make([]*ShareProof, eds.width),
+	}
+	proven := uint(0)
+	for i, share := range byzErr.Shares {
+		if share == nil {
+			continue
+		}
+		var shareProof *ShareProof
+		if len(byzErr.Proofs) == len(byzErr.Shares) {
+			shareProof = byzErr.Proofs[i]
+		} else {
+			rowIdx, colIdx := byzErr.Index, uint(i)
+			if byzErr.Axis == Col {
+				rowIdx, colIdx = colIdx, rowIdx
+			}
+			p, err := eds.proveShare(orthogonal, rowIdx, colIdx)
+			if errors.Is(err, ErrNotProvingTree) {
+				return nil, err
+			}
+			if err == nil && bytes.Equal(p.Share, share) {
+				shareProof = &p
+			}
+		}
+		if shareProof == nil {
+			continue
+		}
+		proof.Shares[i] = share
+		proof.Proofs[i] = shareProof
+		proven++
+	}
+	if proven < eds.width/2 {
+		return nil, fmt.Errorf("only %d of the %d shares of %s %d can be proven, need %d", proven, eds.width, byzErr.Axis, byzErr.Index, eds.width/2)
+	}
+	return proof, nil
+}
+
+// VerifyBadEncoding returns nil if proof proves that its axis of the extended
+// data square with the given row and column roots is incorrectly encoded.
+// Every share of the proof is verified against the root of its orthogonal axis
+// with a tree created by treeCreatorFn, which must implement VerifyingTree.
+// The axis is then decoded from the proven shares with codec, and is
+// incorrectly encoded if its parity does not match its original data or if
+// its root does not match the expected root. Otherwise, an error wrapping
+// ErrInvalidBadEncodingProof is returned.
+func VerifyBadEncoding(
+	proof *BadEncodingProof,
+	rowRoots [][]byte,
+	colRoots [][]byte,
+	codec Codec,
+	treeCreatorFn TreeConstructorFn,
+) error {
+	width := uint(len(rowRoots))
+	if err := validateEdsWidth(width); err != nil {
+		return err
+	}
+	if uint(len(colRoots)) != width {
+		return fmt.Errorf("got %d column roots for %d row roots", len(colRoots), width)
+	}
+	if proof.Index >= width {
+		return fmt.Errorf("%w: %s index %d is out of bounds for width %d", ErrInvalidBadEncodingProof, proof.Axis, proof.Index, width)
+	}
+	if uint(len(proof.Shares)) != width || uint(len(proof.Proofs)) != width {
+		return fmt.Errorf("%w: got %d shares and %d proofs for width %d", ErrInvalidBadEncodingProof, len(proof.Shares), len(proof.Proofs), width)
+	}
+
+	axisRoots, orthogonal, orthogonalRoots := rowRoots, Col, colRoots
+	if proof.Axis == Col {
+		axisRoots, orthogonal, orthogonalRoots = colRoots, Row, rowRoots
+	}
+	info := SquareInfo{Width: width, ShareSize: uint(getShareSize(proof.Shares))}
+
+	proven := uint(0)
+	for i, share := range proof.Shares {
+		if share == nil {
+			continue
+		}
+		p := proof.Proofs[i]
+		if p == nil || p.Axis != orthogonal || p.Index != proof.Index || p.NumLeaves != width ||
+			!bytes.Equal(p.Share, share) || !bytes.Equal(p.Root, orthogonalRoots[i]) {
+			return fmt.Errorf("%w: share %d does not match its proof", ErrInvalidBadEncodingProof, i)
+		}
+		tree, ok := newSquareTree(treeCreatorFn, orthogonal, uint(i), info).(VerifyingTree)
+		if !ok {
+			return ErrNotVerifyingTree
+		}
+		verified := tree.VerifyProof(p.Root, share, p.Proof, int(p.Index), int(p.NumLeaves))
+		releaseTree(tree)
+		if !verified {
+			return fmt.Errorf("%w: invalid inclusion proof for share %d", ErrInvalidBadEncodingProof, i)
+		}
+		proven++
+	}
+	if proven < width/2 {
+		return fmt.Errorf("%w: only %d shares are proven, need %d", ErrInvalidBadEncodingProof, proven, width/2)
+	}
+
+	shares, err := codec.Decode(deepCopy(proof.Shares))
+	if err != nil {
+		return err
+	}
+	ok, err := VerifyEncoding(codec, shares[:width/2], shares[width/2:])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	// the decoded axis does not match its root, or can not be committed to
+	// at all, e.g. because of out of order namespaces
+	if VerifyAxisAgainstRoot(shares, axisRoots[proof.Index], treeCreatorFn, proof.Axis, proof.Index) != nil {
+		return nil
+	}
+	return fmt.Errorf("%w: %s %d is correctly encoded", ErrInvalidBadEncodingProof, proof.Axis, proof.Index)
+}
@@ -0,0 +1,73 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBadEncodingProof(t *testing.T) {
+	codec := NewLeoRSCodec()
+	corruptShare := bytes.Repeat([]byte{66}, shareSize)
+
+	// the roots commit to a square whose first row has a corrupted parity
+	// share, i.e. the square is incorrectly encoded
+	eds := createTestEds(codec, shareSize)
+	eds.setCell(0, 3, corruptShare)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+
+	err = eds.Repair(rowRoots, colRoots)
+	var byzData *ErrByzantineData
+	require.ErrorAs(t, err, &byzData)
+	assert.Equal(t, Row, byzData.Axis)
+	assert.Equal(t, uint(0), byzData.Index)
+
+	proof, err := NewBadEncodingProof(byzData, eds)
+	require.NoError(t, err)
+	assert.Equal(t, byzData.Shares, proof.Shares)
+	assert.NoError(t, VerifyBadEncoding(proof, rowRoots, colRoots, codec, NewDefaultTree))
+
+	t.Run("half of the shares", func(t *testing.T) {
+		half := *proof
+		half.Shares = append([][]byte(nil), proof.Shares...)
+		half.Proofs = append([]*ShareProof(nil), proof.Proofs...)
+		for _, i := range []int{1, 2} {
+			half.Shares[i], half.Proofs[i] = nil, nil
+		}
+		assert.NoError(t, VerifyBadEncoding(&half, rowRoots, colRoots, codec, NewDefaultTree))
+
+		half.Shares[0], half.Proofs[0] = nil, nil
+		assert.ErrorIs(t, VerifyBadEncoding(&half, rowRoots, colRoots, codec, NewDefaultTree), ErrInvalidBadEncodingProof)
+	})
+
+	t.Run("proofs generated from the square", func(t *testing.T) {
+		generated, err := NewBadEncodingProof(&ErrByzantineData{byzData.Axis, byzData.Index, byzData.Shares, nil}, eds)
+		require.NoError(t, err)
+		assert.Equal(t, proof, generated)
+	})
+
+	t.Run("tampered share", func(t *testing.T) {
+		tampered := *proof
+		tampered.Shares = append([][]byte(nil), proof.Shares...)
+		tampered.Shares[1] = corruptShare
+		assert.ErrorIs(t, VerifyBadEncoding(&tampered, rowRoots, colRoots, codec, NewDefaultTree), ErrInvalidBadEncodingProof)
+	})
+
+	t.Run("correctly encoded axis", func(t *testing.T) {
+		honest := *proof
+		honest.Axis, honest.Index = Row, 1
+		honest.Shares = eds.Row(1)
+		honest.Proofs = make([]*ShareProof, len(honest.Shares))
+		for i := range honest.Proofs {
+			p, err := eds.ProveColShare(1, uint(i))
+			require.NoError(t, err)
+			honest.Proofs[i] = &p
+		}
+		assert.ErrorIs(t, VerifyBadEncoding(&honest, rowRoots, colRoots, codec, NewDefaultTree), ErrInvalidBadEncodingProof)
+	})
+}
@@ -0,0 +1,151 @@
+// Package fsstore implements rsmt2d.EDSStore on the filesystem.
+//
+// Every square is stored in its own file in the format of
+// rsmt2d.ExtendedDataSquare.WriteTo, named after the hex encoded data root
+// with the ".eds" extension. Squares are written to a temporary file first
+// and renamed into place, so that a square is either fully stored or not at
+// all, and the files can be memory-mapped with rsmt2d.OpenEDSFile.
+package fsstore
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/celestiaorg/rsmt2d"
+)
+
+var _ rsmt2d.EDSStore = &Store{}
+
+// fileExt is the extension of the files storing squares.
+const fileExt = ".eds"
+
+// Store is an rsmt2d.EDSStore keeping one file per square in a directory.
+type Store struct {
+	dir string
+}
+
+// New returns a store of the squares in dir, creating dir if it does not
+// exist.
+func New(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Path returns the path of the file storing the square with the data root
+// dataRoot.
+func (s *Store) Path(dataRoot []byte) string {
+	return filepath.Join(s.dir, hex.EncodeToString(dataRoot)+fileExt)
+}
+
+// Put fulfills the rsmt2d.EDSStore interface.
+func (s *Store) Put(ctx context.Context, dataRoot []byte, eds *rsmt2d.ExtendedDataSquare) error {
+	if err := validateDataRoot(dataRoot); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(s.dir, "put-*"+fileExt+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := eds.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.Path(dataRoot))
+}
+
+// Get fulfills the rsmt2d.EDSStore interface. The square is read onto the
+// heap as by rsmt2d.ExtendedDataSquare.ReadFrom, use Open to memory-map it
+// instead.
+func (s *Store) Get(ctx context.Context, dataRoot []byte) (*rsmt2d.ExtendedDataSquare, error) {
+	if err := validateDataRoot(dataRoot); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(s.Path(dataRoot))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %x", rsmt2d.ErrSquareNotFound, dataRoot)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	eds := &rsmt2d.ExtendedDataSquare{}
+	if _, err := eds.ReadFrom(f); err != nil {
+		return nil, fmt.Errorf("%s: %w", f.Name(), err)
+	}
+	return eds, nil
+}
+
+// Open is like Get, but memory-maps the square with rsmt2d.OpenEDSFile. The
+// returned file must be closed.
+func (s *Store) Open(ctx context.Context, dataRoot []byte) (*rsmt2d.EDSFile, error) {
+	if err := validateDataRoot(dataRoot); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f, err := rsmt2d.OpenEDSFile(s.Path(dataRoot))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %x", rsmt2d.ErrSquareNotFound, dataRoot)
+	}
+	return f, err
+}
+
+// Has fulfills the rsmt2d.EDSStore interface.
+func (s *Store) Has(ctx context.Context, dataRoot []byte) (bool, error) {
+	if err := validateDataRoot(dataRoot); err != nil {
+		return false, err
+	}
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	_, err := os.Stat(s.Path(dataRoot))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Delete fulfills the rsmt2d.EDSStore interface.
+func (s *Store) Delete(ctx context.Context, dataRoot []byte) error {
+	if err := validateDataRoot(dataRoot); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	err := os.Remove(s.Path(dataRoot))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// validateDataRoot returns an error if dataRoot is empty.
+func validateDataRoot(dataRoot []byte) error {
+	if len(dataRoot) == 0 {
+		return errors.New("empty data root")
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package fsstore
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/celestiaorg/rsmt2d"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	s, err := New(filepath.Join(t.TempDir(), "squares"))
+	require.NoError(t, err)
+
+	eds := newSquare(t)
+	dataRoot, err := eds.DataRoot(sha256.New())
+	require.NoError(t, err)
+
+	has, err := s.Has(ctx, dataRoot)
+	require.NoError(t, err)
+	assert.False(t, has)
+	_, err = s.Get(ctx, dataRoot)
+	assert.ErrorIs(t, err, rsmt2d.ErrSquareNotFound)
+	_, err = s.Open(ctx, dataRoot)
+	assert.ErrorIs(t, err, rsmt2d.ErrSquareNotFound)
+
+	require.NoError(t, s.Put(ctx, dataRoot, eds))
+	has, err = s.Has(ctx, dataRoot)
+	require.NoError(t, err)
+	assert.True(t, has)
+
+	got, err := s.Get(ctx, dataRoot)
+	require.NoError(t, err)
+	assert.True(t, eds.Equals(got))
+
+	f, err := s.Open(ctx, dataRoot)
+	require.NoError(t, err)
+	assert.True(t, eds.Equals(f.ExtendedDataSquare))
+	require.NoError(t, f.Close())
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(filepath.Dir(s.Path(dataRoot)))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, filepath.Base(s.Path(dataRoot)), entries[0].Name())
+
+	require.NoError(t, s.Delete(ctx, dataRoot))
+	require.NoError(t, s.Delete(ctx, dataRoot))
+	has, err = s.Has(ctx, dataRoot)
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	t.Run("empty data root", func(t *testing.T) {
+		assert.Error(t, s.Put(ctx, nil, eds))
+		_, err := s.Get(ctx, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.ErrorIs(t, s.Put(ctx, dataRoot, eds), context.Canceled)
+	})
+
+	t.Run("concurrent puts", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, s.Put(ctx, dataRoot, eds))
+			}()
+		}
+		wg.Wait()
+		got, err := s.Get(ctx, dataRoot)
+		require.NoError(t, err)
+		assert.True(t, eds.Equals(got))
+	})
+}
+
+func newSquare(t *testing.T) *rsmt2d.ExtendedDataSquare {
+	ods := make([][]byte, 16)
+	for i := range ods {
+		ods[i] = make([]byte, 64)
+		_, err := rand.Read(ods[i])
+		require.NoError(t, err)
+	}
+	eds, err := rsmt2d.ComputeExtendedDataSquare(ods, rsmt2d.NewLeoRSCodec(), rsmt2d.NewDefaultTree)
+	require.NoError(t, err)
+	return eds
+}
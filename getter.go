@@ -0,0 +1,93 @@
+package rsmt2d
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ShareGetter fetches the shares of an extended data square from elsewhere,
+// e.g. from peers on the network, see RepairWithGetter.
+type ShareGetter interface {
+	// GetShare returns the share at (row, col) of the extended data square.
+	GetShare(ctx context.Context, row, col uint) ([]byte, error)
+}
+
+// RepairWithGetter is like RepairWithContext, but first fetches missing
+// shares from getter if the shares at hand are insufficient to repair the
+// square. The pattern of present shares is analyzed as by
+// AnalyzeRepairability, and every row that would remain incomplete gets as
+// many of its missing shares fetched as it needs to have half of its shares,
+// so that the square becomes repairable without fetching any share the repair
+// can decode. The rows are fetched concurrently. Fetched shares are verified
+// by the repair like any other share, so a getter serving bad shares results
+// in an ErrByzantineData. Returns an error wrapping the error of getter if a
+// share can not be fetched, or ErrInvalidShareSize if a fetched share is of
+// the wrong size, in which case the square is left unmodified.
+func (eds *ExtendedDataSquare) RepairWithGetter(
+	ctx context.Context,
+	rowRoots [][]byte,
+	colRoots [][]byte,
+	getter ShareGetter,
+	opts ...RepairOption,
+) error {
+	if err := eds.fetchMissing(ctx, getter); err != nil {
+		return err
+	}
+	return eds.RepairWithContext(ctx, rowRoots, colRoots, opts...)
+}
+
+// fetchMissing fetches the missing shares the square needs to be repairable
+// from getter.
+func (eds *ExtendedDataSquare) fetchMissing(ctx context.Context, getter ShareGetter) error {
+	// bm is the presence matrix once every decodable axis has been repaired
+	bm := eds.presenceMatrix()
+	if analyzeRepairability(bm).Repairable {
+		return nil
+	}
+
+	threshold := eds.width / 2
+	fetched := make([][]CellUpdate, eds.width)
+	errs, ctx := errgroup.WithContext(ctx)
+	for rowIdx := uint(0); rowIdx < eds.width; rowIdx++ {
+		count := bm.rowCount(rowIdx)
+		if count >= threshold {
+			continue
+		}
+		rowIdx, needed := rowIdx, threshold-count
+		errs.Go(func() error {
+			cells := make([]CellUpdate, 0, needed)
+			for colIdx := uint(0); colIdx < eds.width && uint(len(cells)) < needed; colIdx++ {
+				if bm.get(rowIdx, colIdx) {
+					continue
+				}
+				cell := CellIndex{Row: rowIdx, Col: colIdx}
+				share, err := getter.GetShare(ctx, rowIdx, colIdx)
+				if err != nil {
+					return fmt.Errorf("getting share %s: %w", cell, err)
+				}
+				if len(share) != int(eds.shareSize) {
+					return fmt.Errorf("%w: got share %s of %d bytes, expected %d", ErrInvalidShareSize, cell, len(share), eds.shareSize)
+				}
+				cells = append(cells, CellUpdate{Row: rowIdx, Col: colIdx, Share: share})
+			}
+			fetched[rowIdx] = cells
+			return nil
+		})
+	}
+	if err := errs.Wait(); err != nil {
+		return err
+	}
+
+	// the shares are only set once all of them have been fetched, so that
+	// the square is left unmodified if any of them can not be
+	var cells []CellUpdate
+	for _, row := range fetched {
+		cells = append(cells, row...)
+	}
+	if err := eds.SetCells(cells); err != nil {
+		return fmt.Errorf("setting fetched shares: %w", err)
+	}
+	return nil
+}
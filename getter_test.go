@@ -0,0 +1,112 @@
+package rsmt2d
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// squareGetter is a ShareGetter serving the shares of a complete square and
+// recording the cells it served.
+type squareGetter struct {
+	eds *ExtendedDataSquare
+	err error
+	// truncate is a cell whose share is served without its last byte
+	truncate *CellIndex
+
+	mu      sync.Mutex
+	fetched []CellIndex
+}
+
+func (g *squareGetter) GetShare(_ context.Context, row, col uint) ([]byte, error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.fetched = append(g.fetched, CellIndex{Row: row, Col: col})
+	share := g.eds.GetCell(row, col)
+	if g.truncate != nil && (CellIndex{Row: row, Col: col}) == *g.truncate {
+		share = share[:len(share)-1]
+	}
+	return share, nil
+}
+
+func TestRepairWithGetter(t *testing.T) {
+	codec := NewLeoRSCodec()
+	original, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), codec, NewDefaultTree)
+	require.NoError(t, err)
+	rowRoots, err := original.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := original.ColRoots()
+	require.NoError(t, err)
+
+	// sparse returns a copy of original with only the shares in rows 0 to 3
+	// of columns 0 and 1 present, so that only these columns can be repaired
+	sparse := func(t *testing.T) *ExtendedDataSquare {
+		shares := make([][]byte, 8*8)
+		for rowIdx := 0; rowIdx < 4; rowIdx++ {
+			for colIdx := 0; colIdx < 2; colIdx++ {
+				shares[rowIdx*8+colIdx] = original.GetCell(uint(rowIdx), uint(colIdx))
+			}
+		}
+		eds, err := ImportExtendedDataSquare(shares, codec, NewDefaultTree)
+		require.NoError(t, err)
+		return eds
+	}
+
+	eds := sparse(t)
+	require.ErrorIs(t, eds.Repair(rowRoots, colRoots), ErrUnrepairableDataSquare)
+
+	eds = sparse(t)
+	getter := &squareGetter{eds: original}
+	require.NoError(t, eds.RepairWithGetter(context.Background(), rowRoots, colRoots, getter))
+	assert.True(t, eds.Equals(original))
+	// every row has two shares once columns 0 and 1 are repaired, so two more
+	// shares are fetched for each row
+	assert.Len(t, getter.fetched, 8*2)
+	for _, cell := range getter.fetched {
+		assert.GreaterOrEqual(t, cell.Col, uint(2), "fetched cell %s could be repaired", cell)
+	}
+
+	t.Run("does not fetch shares of a repairable square", func(t *testing.T) {
+		shares := original.Flattened()
+		shares[0] = nil
+		eds, err := ImportExtendedDataSquare(shares, codec, NewDefaultTree)
+		require.NoError(t, err)
+		getter := &squareGetter{eds: original}
+		require.NoError(t, eds.RepairWithGetter(context.Background(), rowRoots, colRoots, getter))
+		assert.True(t, eds.Equals(original))
+		assert.Empty(t, getter.fetched)
+	})
+
+	t.Run("returns the error of the getter", func(t *testing.T) {
+		errGet := errors.New("share not found")
+		eds := sparse(t)
+		want := eds.Flattened()
+		err := eds.RepairWithGetter(context.Background(), rowRoots, colRoots, &squareGetter{eds: original, err: errGet})
+		assert.ErrorIs(t, err, errGet)
+		assert.Equal(t, want, eds.Flattened(), "the square should be left unmodified")
+	})
+
+	t.Run("rejects shares of the wrong size", func(t *testing.T) {
+		eds := sparse(t)
+		want := eds.Flattened()
+		getter := &squareGetter{eds: original, truncate: &CellIndex{Row: 7, Col: 3}}
+		err := eds.RepairWithGetter(context.Background(), rowRoots, colRoots, getter)
+		assert.ErrorIs(t, err, ErrInvalidShareSize)
+		assert.Equal(t, want, eds.Flattened(), "the square should be left unmodified")
+	})
+
+	t.Run("verifies fetched shares", func(t *testing.T) {
+		other, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), codec, NewDefaultTree)
+		require.NoError(t, err)
+		err = sparse(t).RepairWithGetter(context.Background(), rowRoots, colRoots, &squareGetter{eds: other})
+		var byzErr *ErrByzantineData
+		assert.ErrorAs(t, err, &byzErr)
+	})
+}
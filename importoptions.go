@@ -0,0 +1,213 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// ErrSquareTooLarge is returned when an imported extended data square exceeds
+// the configured import limits.
+var ErrSquareTooLarge = errors.New("extended data square exceeds the import limit")
+
+var (
+	// DefaultMaxImportWidth is the maximum width of an extended data square
+	// accepted by ImportExtendedDataSquare and UnmarshalJSON unless overridden
+	// with WithMaxWidth. Zero means no limit.
+	DefaultMaxImportWidth uint
+	// DefaultMaxImportBytes is the maximum total size in bytes of the shares of
+	// an extended data square accepted by ImportExtendedDataSquare and
+	// UnmarshalJSON unless overridden with WithMaxBytes. Zero means no limit.
+	DefaultMaxImportBytes uint64
+)
+
+// ImportOption configures the behavior of ImportExtendedDataSquare.
+type ImportOption func(*importConfig)
+
+type importConfig struct {
+	maxWidth uint
+	maxBytes uint64
+	// parityCheck is true if the parity of the imported square should be
+	// verified.
+	parityCheck bool
+	// paritySamples is the number of randomly chosen axes to verify. Zero
+	// means all axes are verified.
+	paritySamples uint
+	// trustedRoots is true if serialized roots should be cached without
+	// being verified.
+	trustedRoots bool
+}
+
+func newImportConfig(opts ...ImportOption) importConfig {
+	cfg := importConfig{
+		maxWidth: DefaultMaxImportWidth,
+		maxBytes: DefaultMaxImportBytes,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithMaxWidth limits the width of the imported extended data square. Zero
+// means no limit.
+func WithMaxWidth(maxWidth uint) ImportOption {
+	return func(cfg *importConfig) {
+		cfg.maxWidth = maxWidth
+	}
+}
+
+// WithMaxBytes limits the total size in bytes of the shares of the imported
+// extended data square. Zero means no limit.
+func WithMaxBytes(maxBytes uint64) ImportOption {
+	return func(cfg *importConfig) {
+		cfg.maxBytes = maxBytes
+	}
+}
+
+// WithParityCheck makes ImportExtendedDataSquare re-encode the original data
+// of every complete row and column and compare it against the supplied parity
+// shares. If they do not match, an ErrByzantineData is returned for the first
+// mismatching axis found.
+func WithParityCheck() ImportOption {
+	return func(cfg *importConfig) {
+		cfg.parityCheck = true
+		cfg.paritySamples = 0
+	}
+}
+
+// WithSampledParityCheck is like WithParityCheck but only verifies up to
+// samples randomly chosen complete rows and columns, trading detection
+// probability for import speed.
+func WithSampledParityCheck(samples uint) ImportOption {
+	return func(cfg *importConfig) {
+		cfg.parityCheck = samples != 0
+		cfg.paritySamples = samples
+	}
+}
+
+// WithTrustedRoots makes UnmarshalJSONWith cache the row and column roots
+// serialized with the square instead of verifying them against the shares,
+// which skips computing the roots. It must only be used for input that cannot
+// have been tampered with, e.g. squares read back from local storage.
+func WithTrustedRoots() ImportOption {
+	return func(cfg *importConfig) {
+		cfg.trustedRoots = true
+	}
+}
+
+// checkLimits returns ErrSquareTooLarge if a square of shareCount shares of
+// shareSize bytes each exceeds the configured limits. It is meant to be called
+// before anything proportional to the square size is allocated.
+func (cfg importConfig) checkLimits(shareCount int, shareSize int) error {
+	if cfg.maxWidth != 0 && uint64(shareCount) > uint64(cfg.maxWidth)*uint64(cfg.maxWidth) {
+		return fmt.Errorf("%w: %d shares exceed the max width %d", ErrSquareTooLarge, shareCount, cfg.maxWidth)
+	}
+	if cfg.maxBytes != 0 && uint64(shareCount)*uint64(shareSize) > cfg.maxBytes {
+		return fmt.Errorf("%w: %d shares of %d bytes exceed the max of %d bytes", ErrSquareTooLarge, shareCount, shareSize, cfg.maxBytes)
+	}
+	return nil
+}
+
+// checkJSONLimits returns ErrSquareTooLarge if the data_square of the
+// serialized square b exceeds the configured limits. The shares are streamed
+// without being decoded, and streaming stops as soon as a limit is exceeded,
+// so that oversized input is rejected before its shares are allocated.
+func (cfg importConfig) checkJSONLimits(b []byte) error {
+	return cfg.checkJSONFieldLimits(b, "data_square", 1)
+}
+
+// checkJSONFieldLimits is like checkJSONLimits, but streams the shares of the
+// given field of b, each of which stands for scale shares of the extended
+// data square, e.g. 4 for the shares of an original data square.
+func (cfg importConfig) checkJSONFieldLimits(b []byte, field string, scale uint64) error {
+	if cfg.maxWidth == 0 && cfg.maxBytes == 0 {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		// leave reporting malformed input to json.Unmarshal
+		return nil
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil
+		}
+		if key != field {
+			var skipped json.RawMessage
+			if err := dec.Decode(&skipped); err != nil {
+				return nil
+			}
+			continue
+		}
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+			return nil
+		}
+
+		var shareCount, totalBytes uint64
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil
+			}
+			share, _ := tok.(string)
+			shareCount += scale
+			// the decoded size of a base64 encoded share, ignoring padding
+			totalBytes += uint64(len(strings.TrimRight(share, "="))) * 3 / 4 * scale
+
+			if cfg.maxWidth != 0 && shareCount > uint64(cfg.maxWidth)*uint64(cfg.maxWidth) {
+				return fmt.Errorf("%w: more than %d shares exceed the max width %d", ErrSquareTooLarge, shareCount-scale, cfg.maxWidth)
+			}
+			if cfg.maxBytes != 0 && totalBytes > cfg.maxBytes {
+				return fmt.Errorf("%w: shares exceed the max of %d bytes", ErrSquareTooLarge, cfg.maxBytes)
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// checkParity re-encodes complete rows and columns of eds and compares the
+// result against their parity shares. If samples is non-zero, only up to
+// samples randomly chosen complete axes are checked.
+func (eds *ExtendedDataSquare) checkParity(samples uint) error {
+	type axisIndex struct {
+		axis Axis
+		idx  uint
+	}
+
+	axes := make([]axisIndex, 0, 2*eds.width)
+	for i := uint(0); i < eds.width; i++ {
+		if noMissingData(eds.row(i), noShareInsertion) {
+			axes = append(axes, axisIndex{Row, i})
+		}
+		if noMissingData(eds.col(i), noShareInsertion) {
+			axes = append(axes, axisIndex{Col, i})
+		}
+	}
+
+	if samples != 0 && samples < uint(len(axes)) {
+		rand.Shuffle(len(axes), func(i, j int) {
+			axes[i], axes[j] = axes[j], axes[i]
+		})
+		axes = axes[:samples]
+	}
+
+	for _, a := range axes {
+		var shares [][]byte
+		if a.axis == Row {
+			shares = eds.row(a.idx)
+		} else {
+			shares = eds.col(a.idx)
+		}
+		if err := VerifyAxisEncoding(shares, eds.codec); err != nil {
+			return &ErrByzantineData{a.axis, a.idx, deepCopy(shares), nil}
+		}
+	}
+	return nil
+}
@@ -1,13 +1,17 @@
 package rsmt2d
 
 import (
+	"errors"
 	"fmt"
-	"sync"
 
 	"github.com/klauspost/reedsolomon"
 )
 
-var _ Codec = &LeoRSCodec{}
+var (
+	_ VersionedCodec = &LeoRSCodec{}
+	_ Verifier       = &LeoRSCodec{}
+	_ BatchEncoder   = &LeoRSCodec{}
+)
 
 func init() {
 	registerCodec(Leopard, NewLeoRSCodec())
@@ -17,83 +21,244 @@ type LeoRSCodec struct {
 	// Cache the encoders of various sizes to not have to re-instantiate those
 	// as it is costly.
 	//
-	// Note that past sizes are not removed from the cache at all as the various
-	// data sizes are expected to relatively small and will not cause any memory issue.
-	//
-	// TODO: switch to a generic version of sync.Map with type reedsolomon.Encoder
-	// once it made it into the standard lib
-	encCache sync.Map
+	// By default, past sizes are not removed from the cache at all as the
+	// various data sizes are expected to relatively small and will not cause
+	// any memory issue. Use NewLeoRSCodecWithCache to bound the cache.
+	encCache encoderCache
+	// encOpts are passed to reedsolomon when instantiating encoders.
+	encOpts []reedsolomon.Option
+}
+
+// LeoRSCodecOption configures a LeoRSCodec created by NewLeoRSCodec.
+type LeoRSCodecOption func(*LeoRSCodec)
+
+// WithAutoGoroutines splits the byte ranges of the shares of a single
+// encoding or decoding across goroutines, picking the number of goroutines
+// for optimal speed with shares of shareSize bytes. Other share sizes still
+// work but may not run at the optimal speed. This is mostly useful for very
+// wide shares (e.g. 8KB). Overrides WithMaxGoroutines.
+func WithAutoGoroutines(shareSize int) LeoRSCodecOption {
+	return func(l *LeoRSCodec) {
+		l.encOpts = append(l.encOpts, reedsolomon.WithAutoGoroutines(shareSize))
+	}
+}
+
+// WithMaxGoroutines sets the maximum number of goroutines the byte ranges of
+// the shares of a single encoding or decoding are split across.
+func WithMaxGoroutines(n int) LeoRSCodecOption {
+	return func(l *LeoRSCodec) {
+		l.encOpts = append(l.encOpts, reedsolomon.WithMaxGoroutines(n))
+	}
+}
+
+// WithEncoderCacheSize bounds the encoder cache to maxEntries encoders,
+// evicting the least recently used encoder when the cache is full. A
+// maxEntries of zero means the cache is unbounded.
+func WithEncoderCacheSize(maxEntries int) LeoRSCodecOption {
+	return func(l *LeoRSCodec) {
+		l.encCache.maxEntries = maxEntries
+	}
 }
 
 func (l *LeoRSCodec) Encode(data [][]byte) ([][]byte, error) {
+	parity := allocShares(len(data), len(data[0]))
+	if err := l.EncodeInto(data, parity); err != nil {
+		return nil, err
+	}
+	return parity, nil
+}
+
+func (l *LeoRSCodec) EncodeInto(data [][]byte, parityOut [][]byte) error {
 	dataLen := len(data)
+	if len(parityOut) != dataLen {
+		return fmt.Errorf("%w: parityOut has %d shares but data has %d shares", ErrShareCountMismatch, len(parityOut), dataLen)
+	}
 	enc, err := l.loadOrInitEncoder(dataLen)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	shares := make([][]byte, dataLen*2)
 	copy(shares, data)
-	for i := dataLen; i < len(shares); i++ {
-		shares[i] = make([]byte, len(data[0]))
-	}
+	copy(shares[dataLen:], parityOut)
 
-	if err := enc.Encode(shares); err != nil {
+	return enc.Encode(shares)
+}
+
+// EncodeBatch encodes every axis in axes with a single encoder lookup and
+// returns their parity shares in a single allocation.
+func (l *LeoRSCodec) EncodeBatch(axes [][][]byte) ([][][]byte, error) {
+	parity := allocBatchParity(axes)
+	if err := l.EncodeBatchInto(axes, parity); err != nil {
 		return nil, err
 	}
-	return shares[dataLen:], nil
+	return parity, nil
+}
+
+// EncodeBatchInto encodes every axis in axes into parityOut with a single
+// encoder lookup, reusing the same work slice for every axis.
+func (l *LeoRSCodec) EncodeBatchInto(axes [][][]byte, parityOut [][][]byte) error {
+	if len(axes) == 0 {
+		return nil
+	}
+	enc, err := l.loadOrInitEncoder(len(axes[0]))
+	if err != nil {
+		return err
+	}
+	return encodeBatchWith(enc, axes, parityOut)
+}
+
+// encodeBatchWith encodes every axis in axes into parityOut with enc, which
+// must be an encoder for len(axes[0]) data shares.
+func encodeBatchWith(enc reedsolomon.Encoder, axes [][][]byte, parityOut [][][]byte) error {
+	if len(parityOut) != len(axes) {
+		return fmt.Errorf("%w: got parity for %d axes but %d axes", ErrShareCountMismatch, len(parityOut), len(axes))
+	}
+	dataLen := len(axes[0])
+	shares := make([][]byte, dataLen*2)
+	for i, data := range axes {
+		if len(data) != dataLen || len(parityOut[i]) != dataLen {
+			return fmt.Errorf("%w: axis %d has %d shares and %d parity shares, expected %d", ErrShareCountMismatch, i, len(data), len(parityOut[i]), dataLen)
+		}
+		copy(shares, data)
+		copy(shares[dataLen:], parityOut[i])
+		if err := enc.Encode(shares); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Verify returns true if parity are the parity shares of original.
+func (l *LeoRSCodec) Verify(original, parity [][]byte) (bool, error) {
+	dataLen := len(original)
+	if len(parity) != dataLen {
+		return false, fmt.Errorf("%w: got %d parity shares for %d original shares", ErrShareCountMismatch, len(parity), dataLen)
+	}
+	enc, err := l.loadOrInitEncoder(dataLen)
+	if err != nil {
+		return false, err
+	}
+
+	shares := make([][]byte, dataLen*2)
+	copy(shares, original)
+	copy(shares[dataLen:], parity)
+
+	return enc.Verify(shares)
 }
 
 func (l *LeoRSCodec) Decode(data [][]byte) ([][]byte, error) {
 	half := len(data) / 2
 	enc, err := l.loadOrInitEncoder(half)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", ErrCodecInternal, err)
 	}
 	err = enc.Reconstruct(data)
-	return data, err
+	return data, wrapDecodeError(err)
+}
+
+// wrapDecodeError wraps an error returned by reedsolomon while decoding in
+// ErrTooFewShares, ErrShardSizeMismatch or ErrCodecInternal.
+func wrapDecodeError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, reedsolomon.ErrTooFewShards), errors.Is(err, reedsolomon.ErrShardNoData):
+		// ErrShardNoData is returned if all shares are missing
+		return fmt.Errorf("%w: %v", ErrTooFewShares, err)
+	case errors.Is(err, reedsolomon.ErrShardSize):
+		return fmt.Errorf("%w: %v", ErrShardSizeMismatch, err)
+	default:
+		return fmt.Errorf("%w: %v", ErrCodecInternal, err)
+	}
 }
 
 func (l *LeoRSCodec) loadOrInitEncoder(dataLen int) (reedsolomon.Encoder, error) {
-	enc, ok := l.encCache.Load(dataLen)
-	if !ok {
-		var err error
-		enc, err = reedsolomon.New(dataLen, dataLen, reedsolomon.WithLeopardGF(true))
-		if err != nil {
-			return nil, err
+	return l.encCache.loadOrInit(dataLen, func(dataLen int) (reedsolomon.Encoder, error) {
+		opts := append([]reedsolomon.Option{reedsolomon.WithLeopardGF(true)}, l.encOpts...)
+		return reedsolomon.New(dataLen, dataLen, opts...)
+	})
+}
+
+// WarmUp instantiates and caches the encoders for the given data lengths
+// (i.e. ODS widths) ahead of time, so that the first extension or repair of
+// a square of that width does not incur the initialization cost.
+func (l *LeoRSCodec) WarmUp(dataLens ...int) error {
+	for _, dataLen := range dataLens {
+		if _, err := l.loadOrInitEncoder(dataLen); err != nil {
+			return fmt.Errorf("warming up encoder for data length %d: %w", dataLen, err)
 		}
-		l.encCache.Store(dataLen, enc)
 	}
-	return enc.(reedsolomon.Encoder), nil
+	return nil
+}
+
+// ClearCache removes all cached encoders.
+func (l *LeoRSCodec) ClearCache() {
+	l.encCache.clear()
+}
+
+// CacheStats returns statistics about the encoder cache.
+func (l *LeoRSCodec) CacheStats() EncoderCacheStats {
+	return l.encCache.getStats()
 }
 
 // MaxChunks returns the max number of shares this codec supports in a 2D
 // original data square.
 func (l *LeoRSCodec) MaxChunks() int {
+	// The max number of shares in a 2D original data square is 32768 * 32768.
+	maxODSWidth := l.MaxAxisShares()
+	return maxODSWidth * maxODSWidth
+}
+
+// MaxAxisShares returns the max number of original shares per row or column
+// this codec supports.
+func (l *LeoRSCodec) MaxAxisShares() int {
 	// klauspost/reedsolomon supports an EDS width of 65536. See:
 	// https://github.com/klauspost/reedsolomon/blob/523164698be98f1603cf1235f5a1de17728b2091/leopard.go#L42C31-L42C36
 	maxEDSWidth := 65536
 	// An EDS width of 65536 is an ODS width of 32768.
-	maxODSWidth := maxEDSWidth / 2
-	// The max number of shares in a 2D original data square is 32768 * 32768.
-	return maxODSWidth * maxODSWidth
+	return maxEDSWidth / 2
 }
 
 func (l *LeoRSCodec) Name() string {
 	return Leopard
 }
 
+// Version returns the version of the share layout of the codec.
+func (l *LeoRSCodec) Version() int {
+	return 1
+}
+
+// SupportedChunkSizes returns the min and max supported share size and the
+// number of bytes supported share sizes must be a multiple of. A max of zero
+// means there is no maximum.
+func (l *LeoRSCodec) SupportedChunkSizes() (minSize, maxSize, multiple int) {
+	// See ValidateChunkSize.
+	return 64, 0, 64
+}
+
 // ValidateChunkSize returns an error if this codec does not support
 // shareSize. Returns nil if shareSize is supported.
 func (l *LeoRSCodec) ValidateChunkSize(shareSize int) error {
 	// See https://github.com/catid/leopard/blob/22ddc7804998d31c8f1a2617ee720e063b1fa6cd/README.md?plain=1#L27
 	// See https://github.com/klauspost/reedsolomon/blob/fd3e6910a7e457563469172968f456ad9b7696b6/README.md?plain=1#L403
 	if shareSize%64 != 0 {
-		return fmt.Errorf("shareSize %v must be a multiple of 64 bytes", shareSize)
+		return fmt.Errorf("%w: shareSize %v must be a multiple of 64 bytes", ErrInvalidShareSize, shareSize)
 	}
 	return nil
 }
 
-func NewLeoRSCodec() *LeoRSCodec {
-	return &LeoRSCodec{}
+func NewLeoRSCodec(opts ...LeoRSCodecOption) *LeoRSCodec {
+	l := &LeoRSCodec{}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// NewLeoRSCodecWithCache returns a LeoRSCodec that caches at most maxEntries
+// encoders, evicting the least recently used encoder when the cache is full.
+// A maxEntries of zero means the cache is unbounded.
+func NewLeoRSCodecWithCache(maxEntries int) *LeoRSCodec {
+	return NewLeoRSCodec(WithEncoderCacheSize(maxEntries))
 }
@@ -0,0 +1,94 @@
+package rsmt2d
+
+import "time"
+
+// CodecMetrics receives measurements of the encodings and decodings performed
+// by a codec wrapped with InstrumentedCodec. Implementations must be safe for
+// concurrent use and should return quickly, as measurements are delivered
+// synchronously.
+type CodecMetrics interface {
+	// ObserveEncode is called after each Encode or EncodeInto with the name
+	// of the codec, the number of original bytes encoded, the duration of the
+	// encoding and the error it returned, if any.
+	ObserveEncode(codec string, bytes int, duration time.Duration, err error)
+	// ObserveDecode is called after each Decode with the name of the codec,
+	// the number of bytes present in the input, the duration of the decoding
+	// and the error it returned, if any.
+	ObserveDecode(codec string, bytes int, duration time.Duration, err error)
+}
+
+// InstrumentedCodec returns a codec that wraps codec and reports the count,
+// number of bytes processed and duration of its encodings and decodings to
+// metrics. The returned codec has the same name, version and supported widths
+// as codec so that squares using it can still be unmarshalled.
+func InstrumentedCodec(codec Codec, metrics CodecMetrics) Codec {
+	return &instrumentedCodec{Codec: codec, metrics: metrics}
+}
+
+var (
+	_ VersionedCodec = &instrumentedCodec{}
+	_ WidthValidator = &instrumentedCodec{}
+	_ Verifier       = &instrumentedCodec{}
+)
+
+type instrumentedCodec struct {
+	Codec
+	metrics CodecMetrics
+}
+
+// Unwrap returns the wrapped codec.
+func (c *instrumentedCodec) Unwrap() Codec {
+	return c.Codec
+}
+
+// Version returns the version of the wrapped codec.
+func (c *instrumentedCodec) Version() int {
+	return codecVersion(c.Codec)
+}
+
+// Verify verifies parity with the wrapped codec, see VerifyEncoding.
+func (c *instrumentedCodec) Verify(original, parity [][]byte) (bool, error) {
+	return VerifyEncoding(c.Codec, original, parity)
+}
+
+// ValidateWidth validates odsWidth with the wrapped codec if it implements
+// WidthValidator.
+func (c *instrumentedCodec) ValidateWidth(odsWidth uint) error {
+	if validator, ok := c.Codec.(WidthValidator); ok {
+		return validator.ValidateWidth(odsWidth)
+	}
+	return nil
+}
+
+func (c *instrumentedCodec) Encode(data [][]byte) ([][]byte, error) {
+	start := time.Now()
+	parity, err := c.Codec.Encode(data)
+	c.metrics.ObserveEncode(c.Name(), sharesSize(data), time.Since(start), err)
+	return parity, err
+}
+
+func (c *instrumentedCodec) EncodeInto(data [][]byte, parityOut [][]byte) error {
+	start := time.Now()
+	err := c.Codec.EncodeInto(data, parityOut)
+	c.metrics.ObserveEncode(c.Name(), sharesSize(data), time.Since(start), err)
+	return err
+}
+
+func (c *instrumentedCodec) Decode(data [][]byte) ([][]byte, error) {
+	// the size has to be computed before decoding as data is decoded in place
+	size := sharesSize(data)
+	start := time.Now()
+	decoded, err := c.Codec.Decode(data)
+	c.metrics.ObserveDecode(c.Name(), size, time.Since(start), err)
+	return decoded, err
+}
+
+// sharesSize returns the total size of shares in bytes. Missing shares are
+// counted as empty.
+func sharesSize(shares [][]byte) int {
+	size := 0
+	for _, share := range shares {
+		size += len(share)
+	}
+	return size
+}
@@ -0,0 +1,100 @@
+package rsmt2d
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingMetrics struct {
+	mu                         sync.Mutex
+	encodes, decodes           int
+	encodedBytes, decodedBytes int
+	decodeErrs                 int
+}
+
+func (m *countingMetrics) ObserveEncode(_ string, bytes int, _ time.Duration, _ error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.encodes++
+	m.encodedBytes += bytes
+}
+
+func (m *countingMetrics) ObserveDecode(_ string, bytes int, _ time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.decodes++
+	m.decodedBytes += bytes
+	if err != nil {
+		m.decodeErrs++
+	}
+}
+
+func TestInstrumentedCodec(t *testing.T) {
+	metrics := &countingMetrics{}
+	codec := InstrumentedCodec(NewLeoRSCodec(), metrics)
+	assert.Equal(t, Leopard, codec.Name())
+
+	eds, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), codec, NewDefaultTree)
+	require.NoError(t, err)
+	// 4 rows and 8 columns are encoded
+	assert.Equal(t, 12, metrics.encodes)
+	assert.Equal(t, 12*4*shareSize, metrics.encodedBytes)
+	assert.Equal(t, 0, metrics.decodes)
+
+	shares := eds.Row(0)
+	shares[0], shares[1] = nil, nil
+	_, err = codec.Decode(shares)
+	require.NoError(t, err)
+	assert.Equal(t, 1, metrics.decodes)
+	assert.Equal(t, 6*shareSize, metrics.decodedBytes)
+
+	_, err = codec.Decode(make([][]byte, 8))
+	assert.ErrorIs(t, err, ErrTooFewShares)
+	assert.Equal(t, 2, metrics.decodes)
+	assert.Equal(t, 1, metrics.decodeErrs)
+}
+
+// versionTwoCodec is a Leopard codec with version 2.
+type versionTwoCodec struct {
+	*LeoRSCodec
+}
+
+func (c *versionTwoCodec) Name() string {
+	return "versionTwoCodec"
+}
+
+func (c *versionTwoCodec) Version() int {
+	return 2
+}
+
+func TestInstrumentedCodecForwardsOptionalInterfaces(t *testing.T) {
+	t.Run("Version", func(t *testing.T) {
+		inner := &versionTwoCodec{NewLeoRSCodec()}
+		require.NoError(t, RegisterCodec(inner.Name(), inner))
+		defer DeregisterCodec(inner.Name())
+
+		codec := InstrumentedCodec(inner, &countingMetrics{})
+		assert.Equal(t, 2, codecVersion(codec))
+		assert.Equal(t, defaultCodecVersion, codecVersion(InstrumentedCodec(NewRSGF8Codec(), &countingMetrics{})))
+
+		// squares of the wrapped codec can be unmarshalled with the inner codec
+		eds, err := ComputeExtendedDataSquare(generateRandData(4, shareSize), codec, NewDefaultTree)
+		require.NoError(t, err)
+		edsBytes, err := json.Marshal(eds)
+		require.NoError(t, err)
+
+		var unmarshalled ExtendedDataSquare
+		require.NoError(t, json.Unmarshal(edsBytes, &unmarshalled))
+		assert.True(t, unmarshalled.Equals(eds))
+	})
+	t.Run("ValidateWidth", func(t *testing.T) {
+		codec := InstrumentedCodec(&oddWidthsCodec{NewLeoRSCodec()}, &countingMetrics{})
+		assert.ErrorIs(t, ValidateWidth(codec, 2), ErrUnsupportedWidth)
+		assert.NoError(t, ValidateWidth(codec, 3))
+	})
+}
@@ -0,0 +1,328 @@
+// Package nmtwrapper adapts a namespaced Merkle tree to the rsmt2d Tree
+// interface.
+//
+// The wrapper has been adapted from the celestia-app source file available at
+// https://github.com/celestiaorg/celestia-app/blob/bab6c0d0befe677ab8c2f4b83561c08affc7203e/pkg/wrapper/nmt_wrapper.go.
+package nmtwrapper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+	"github.com/celestiaorg/rsmt2d"
+)
+
+var (
+	_ rsmt2d.Tree               = &ErasuredNamespacedMerkleTree{}
+	_ rsmt2d.NamespaceTree      = &ErasuredNamespacedMerkleTree{}
+	_ rsmt2d.Releasable         = &ErasuredNamespacedMerkleTree{}
+	_ rsmt2d.ConsumableTree     = &ErasuredNamespacedMerkleTree{}
+	_ rsmt2d.SquareAwareTree    = &ErasuredNamespacedMerkleTree{}
+	_ rsmt2d.NamespaceQueryTree = &ErasuredNamespacedMerkleTree{}
+	_ rsmt2d.ProvingTree        = &ErasuredNamespacedMerkleTree{}
+	_ rsmt2d.VerifyingTree      = &ErasuredNamespacedMerkleTree{}
+)
+
+// ParityNamespaceByte is repeated to build the namespace assigned to every
+// share outside of the original data square.
+const ParityNamespaceByte = 0xFF
+
+// TreeName is the name the ErasuredNamespacedMerkleTree is registered under
+// with rsmt2d.RegisterTreeFactory. Squares serialized with
+// rsmt2d.WithTree(TreeName, TreeParams{...}) are unmarshalled with a
+// Constructor created from the TreeParams.
+const TreeName = "nmt"
+
+// TreeParams are the parameters of the trees of a serialized square.
+type TreeParams struct {
+	// SquareSize is the width of the original data square, see
+	// NewConstructor. Zero means that it is taken from the square.
+	SquareSize uint64 `json:"square_size"`
+	// NamespaceSize is the size of the namespaces of the shares. It is
+	// required.
+	NamespaceSize int `json:"namespace_size"`
+}
+
+func init() {
+	rsmt2d.MustRegisterTree(TreeName, newTreeConstructor)
+}
+
+// newTreeConstructor is the rsmt2d.TreeFactory of the
+// ErasuredNamespacedMerkleTree.
+func newTreeConstructor(params json.RawMessage) (rsmt2d.TreeConstructorFn, error) {
+	var p struct {
+		SquareSize    uint64 `json:"square_size"`
+		NamespaceSize *int   `json:"namespace_size"`
+	}
+	if params == nil {
+		return nil, fmt.Errorf("%w: missing parameters", rsmt2d.ErrInvalidTreeParams)
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("%w: %v", rsmt2d.ErrInvalidTreeParams, err)
+	}
+	if p.NamespaceSize == nil {
+		return nil, fmt.Errorf("%w: missing namespace_size", rsmt2d.ErrInvalidTreeParams)
+	}
+	if *p.NamespaceSize <= 0 || *p.NamespaceSize > namespace.IDMaxSize {
+		return nil, fmt.Errorf("%w: namespace_size %d is not in [1, %d]", rsmt2d.ErrInvalidTreeParams, *p.NamespaceSize, namespace.IDMaxSize)
+	}
+	return NewConstructor(p.SquareSize, nmt.NamespaceIDSize(*p.NamespaceSize)).NewTree, nil
+}
+
+// ErasuredNamespacedMerkleTree wraps a NamespaceMerkleTree to conform to the
+// rsmt2d.Tree interface while also providing the correct namespaces to the
+// underlying tree. Shares in the first quadrant keep the namespace prefixed
+// to their data, and every other share is pushed with the parity namespace.
+// This allows for the namespaces to be included in the erasure data, while
+// also keeping the nmt library sufficiently general.
+type ErasuredNamespacedMerkleTree struct {
+	squareSize uint64 // note: this refers to the width of the original square before erasure-coded
+	options    []nmt.Option
+	tree       nmtTree
+	// axisIndex is the index of the axis (row or column) that this tree is
+	// on. It is used to help determine which quadrant each leaf belongs to.
+	axisIndex uint64
+	// shareIndex is the index of the next share pushed to the tree, in the
+	// range 0 <= shareIndex < 2*squareSize.
+	shareIndex      uint64
+	namespaceSize   int
+	parityNamespace []byte
+	// minNamespace and maxNamespace are the smallest and largest namespaces
+	// pushed to the first quadrant, i.e. the namespace range of the tree
+	// when the parity namespace is ignored.
+	minNamespace, maxNamespace []byte
+	// pool is the pool the tree is returned to by Release, if any.
+	pool *sync.Pool
+}
+
+// nmtTree wraps the methods of the underlying NamespaceMerkleTree that are
+// used by ErasuredNamespacedMerkleTree.
+type nmtTree interface {
+	Root() ([]byte, error)
+	Push(namespacedData namespace.PrefixedData) error
+	ProveRange(start, end int) (nmt.Proof, error)
+	ProveNamespace(nID namespace.ID) (nmt.Proof, error)
+}
+
+// NewErasuredNamespacedMerkleTree creates a new ErasuredNamespacedMerkleTree
+// with an underlying NMT configured by options and with
+// ignoreMaxNamespace=true. axisIndex is the index of the row or column that
+// this tree is committing to. squareSize must be greater than zero.
+func NewErasuredNamespacedMerkleTree(squareSize uint64, axisIndex uint, options ...nmt.Option) *ErasuredNamespacedMerkleTree {
+	if squareSize == 0 {
+		panic("cannot create a ErasuredNamespacedMerkleTree of squareSize == 0")
+	}
+	w := &ErasuredNamespacedMerkleTree{}
+	w.init(squareSize, axisIndex, options)
+	return w
+}
+
+// init (re)initializes w for the given axis, reusing the parity namespace
+// buffer when its size is unchanged.
+func (w *ErasuredNamespacedMerkleTree) init(squareSize uint64, axisIndex uint, options []nmt.Option) {
+	// read the options to extract the namespace size
+	opts := &nmt.Options{}
+	for _, setter := range options {
+		setter(opts)
+	}
+	options = append(options[:len(options):len(options)], nmt.IgnoreMaxNamespace(true))
+
+	w.squareSize = squareSize
+	w.options = options
+	w.tree = nmt.New(sha256.New(), options...)
+	w.axisIndex = uint64(axisIndex)
+	w.shareIndex = 0
+	w.minNamespace, w.maxNamespace = nil, nil
+	w.namespaceSize = int(opts.NamespaceIDSize)
+	if len(w.parityNamespace) != w.namespaceSize {
+		w.parityNamespace = bytes.Repeat([]byte{ParityNamespaceByte}, w.namespaceSize)
+	}
+}
+
+// Constructor creates ErasuredNamespacedMerkleTrees for a fixed original
+// square size and set of nmt options.
+type Constructor struct {
+	squareSize uint64
+	opts       []nmt.Option
+	pool       *sync.Pool
+}
+
+// NewConstructor returns a Constructor for squares whose original data square
+// is squareSize wide. A squareSize of zero means that the width is taken from
+// the square the trees are created for, see SetSquareInfo.
+func NewConstructor(squareSize uint64, opts ...nmt.Option) *Constructor {
+	return &Constructor{squareSize: squareSize, opts: opts}
+}
+
+// NewPooledConstructor is like NewConstructor, but the trees it creates are
+// handed back with Release once their root has been read, which rsmt2d does
+// for the trees it uses to compute roots. Released trees are reused by later
+// calls to NewTree, which saves re-allocating the wrapper and its parity
+// namespace; the underlying NMT is always created afresh.
+func NewPooledConstructor(squareSize uint64, opts ...nmt.Option) *Constructor {
+	c := NewConstructor(squareSize, opts...)
+	c.pool = &sync.Pool{}
+	return c
+}
+
+// NewTree creates a new rsmt2d.Tree for the given axis index. Its signature
+// matches rsmt2d.TreeConstructorFn.
+func (c *Constructor) NewTree(_ rsmt2d.Axis, axisIndex uint) rsmt2d.Tree {
+	var w *ErasuredNamespacedMerkleTree
+	if c.pool != nil {
+		w, _ = c.pool.Get().(*ErasuredNamespacedMerkleTree)
+	}
+	if w == nil {
+		w = &ErasuredNamespacedMerkleTree{}
+	}
+	w.init(c.squareSize, axisIndex, c.opts)
+	w.pool = c.pool
+	return w
+}
+
+// Release returns tree to the pool of the Constructor that created it. It is
+// a no-op for trees from a Constructor without a pool. The tree must not be
+// used after it has been released.
+func (c *Constructor) Release(tree rsmt2d.Tree) {
+	if w, ok := tree.(*ErasuredNamespacedMerkleTree); ok && w.pool == c.pool {
+		w.Release()
+	}
+}
+
+// Release returns the tree to the pool of the Constructor that created it.
+// It is a no-op for trees that were not created by a pooled Constructor. The
+// tree must not be used after it has been released.
+func (w *ErasuredNamespacedMerkleTree) Release() {
+	pool := w.pool
+	if pool == nil {
+		return
+	}
+	w.pool = nil
+	w.tree = nil
+	pool.Put(w)
+}
+
+// SetSquareInfo fulfills the rsmt2d.SquareAwareTree interface by taking the
+// width of the original data square from the extended data square the tree
+// is created for. It must be called before the first Push.
+func (w *ErasuredNamespacedMerkleTree) SetSquareInfo(info rsmt2d.SquareInfo) {
+	w.squareSize = uint64(info.Width / 2)
+}
+
+// Push adds the provided data to the underlying NamespaceMerkleTree, and
+// automatically uses the first namespaceSize bytes as the namespace unless
+// the data is pushed outside of the original data square, in which case the
+// parity namespace is used. Fulfills the rsmt2d.Tree interface.
+func (w *ErasuredNamespacedMerkleTree) Push(data []byte) error {
+	if w.axisIndex+1 > 2*w.squareSize || w.shareIndex+1 > 2*w.squareSize {
+		return fmt.Errorf("pushed past predetermined square size: boundary at %d index at %d %d", 2*w.squareSize, w.axisIndex, w.shareIndex)
+	}
+	nID := rsmt2d.Share(data).Namespace(w.namespaceSize)
+	if nID == nil {
+		return fmt.Errorf("data is too short to contain namespace ID")
+	}
+	nidAndData := make([]byte, w.namespaceSize+len(data))
+	copy(nidAndData[w.namespaceSize:], data)
+	// use the parity namespace if the cell is not in Q0 of the extended data square
+	if w.isQuadrantZero() {
+		copy(nidAndData[:w.namespaceSize], nID)
+		if w.minNamespace == nil {
+			w.minNamespace = nidAndData[:w.namespaceSize]
+		}
+		w.maxNamespace = nidAndData[:w.namespaceSize]
+	} else {
+		copy(nidAndData[:w.namespaceSize], w.parityNamespace)
+	}
+	if err := w.tree.Push(nidAndData); err != nil {
+		return err
+	}
+	w.shareIndex++
+	return nil
+}
+
+// Root fulfills the rsmt2d.Tree interface by returning the root of the
+// underlying NamespaceMerkleTree.
+func (w *ErasuredNamespacedMerkleTree) Root() ([]byte, error) {
+	return w.tree.Root()
+}
+
+// ConsumeRoot fulfills the rsmt2d.ConsumableTree interface. It returns the
+// same root as Root and then drops the underlying NamespaceMerkleTree, so that
+// its copies of the leaves can be collected before the tree is released. The
+// tree must not be used afterwards, except to release it.
+func (w *ErasuredNamespacedMerkleTree) ConsumeRoot() ([]byte, error) {
+	root, err := w.tree.Root()
+	w.tree = nil
+	return root, err
+}
+
+// ProveRange fulfills the rsmt2d.NamespaceTree interface by returning the
+// underlying NamespaceMerkleTree proof for the leaves in [start, end).
+func (w *ErasuredNamespacedMerkleTree) ProveRange(start, end int) (rsmt2d.NamespaceProof, error) {
+	proof, err := w.tree.ProveRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// ProveNamespace fulfills the rsmt2d.NamespaceQueryTree interface by
+// returning the underlying NamespaceMerkleTree proof for namespace nID, or a
+// nil proof if nID is outside of the namespace range of the first quadrant
+// leaves.
+func (w *ErasuredNamespacedMerkleTree) ProveNamespace(nID []byte) (rsmt2d.NamespaceProof, error) {
+	if w.minNamespace == nil || bytes.Compare(nID, w.minNamespace) < 0 || bytes.Compare(nID, w.maxNamespace) > 0 {
+		return nil, nil
+	}
+	proof, err := w.tree.ProveNamespace(nID)
+	if err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// Prove fulfills the rsmt2d.ProvingTree interface by returning the root of
+// the underlying NamespaceMerkleTree and the nodes of its inclusion proof for
+// the leaf at leafIdx, so that squares using the tree can be sampled with
+// rsmt2d.ExtendedDataSquare.Sample.
+func (w *ErasuredNamespacedMerkleTree) Prove(leafIdx int) ([]byte, [][]byte, error) {
+	root, err := w.tree.Root()
+	if err != nil {
+		return nil, nil, err
+	}
+	proof, err := w.tree.ProveRange(leafIdx, leafIdx+1)
+	if err != nil {
+		return nil, nil, err
+	}
+	return root, proof.Nodes(), nil
+}
+
+// VerifyProof fulfills the rsmt2d.VerifyingTree interface by verifying the
+// proof nodes returned by Prove. The leaf is verified under the namespace it
+// is pushed with, i.e. its own namespace in the first quadrant and the parity
+// namespace elsewhere, which depends on the axis index of the tree.
+func (w *ErasuredNamespacedMerkleTree) VerifyProof(root []byte, leaf []byte, proof [][]byte, leafIdx int, numLeaves int) bool {
+	if leafIdx < 0 || uint64(numLeaves) != 2*w.squareSize || leafIdx >= numLeaves {
+		return false
+	}
+	nID := rsmt2d.Share(leaf).Namespace(w.namespaceSize)
+	if nID == nil {
+		return false
+	}
+	if uint64(leafIdx) >= w.squareSize || w.axisIndex >= w.squareSize {
+		nID = w.parityNamespace
+	}
+	p := nmt.NewInclusionProof(leafIdx, leafIdx+1, proof, true)
+	return p.VerifyInclusion(sha256.New(), nID, [][]byte{leaf}, root)
+}
+
+// isQuadrantZero returns true if the current share index and axis index are
+// both in the original data square.
+func (w *ErasuredNamespacedMerkleTree) isQuadrantZero() bool {
+	return w.shareIndex < w.squareSize && w.axisIndex < w.squareSize
+}
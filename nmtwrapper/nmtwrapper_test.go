@@ -0,0 +1,216 @@
+package nmtwrapper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"testing"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/rsmt2d"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	namespaceSize = 8
+	shareSize     = 64
+)
+
+// sortedShares returns count shares whose namespaces are in ascending order.
+func sortedShares(count int) [][]byte {
+	shares := make([][]byte, count)
+	for i := range shares {
+		shares[i] = bytes.Repeat([]byte{byte(i + 1)}, shareSize)
+	}
+	return shares
+}
+
+func TestPushUsesParityNamespace(t *testing.T) {
+	const squareSize = 2
+	shares := sortedShares(2 * squareSize)
+
+	tests := []struct {
+		name      string
+		axisIndex uint
+		// original is the number of leading shares pushed with their own namespace
+		original int
+	}{
+		{"original row", 0, squareSize},
+		{"parity row", squareSize, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := NewErasuredNamespacedMerkleTree(squareSize, tt.axisIndex, nmt.NamespaceIDSize(namespaceSize))
+			want := nmt.New(sha256.New(), nmt.NamespaceIDSize(namespaceSize), nmt.IgnoreMaxNamespace(true))
+			for i, share := range shares {
+				require.NoError(t, tree.Push(share))
+				ns := bytes.Repeat([]byte{ParityNamespaceByte}, namespaceSize)
+				if i < tt.original {
+					ns = share[:namespaceSize]
+				}
+				require.NoError(t, want.Push(append(ns, share...)))
+			}
+			assert.Error(t, tree.Push(shares[0]), "pushing past the square should fail")
+
+			got, err := tree.Root()
+			require.NoError(t, err)
+			wantRoot, err := want.Root()
+			require.NoError(t, err)
+			assert.Equal(t, wantRoot, got)
+		})
+	}
+}
+
+func TestPushRejectsShortData(t *testing.T) {
+	tree := NewErasuredNamespacedMerkleTree(2, 0, nmt.NamespaceIDSize(namespaceSize))
+	assert.Error(t, tree.Push(make([]byte, namespaceSize-1)))
+}
+
+func TestPooledConstructor(t *testing.T) {
+	const squareSize = 2
+	shares := sortedShares(squareSize * squareSize)
+
+	eds, err := rsmt2d.ComputeExtendedDataSquare(shares, rsmt2d.NewLeoRSCodec(), NewConstructor(squareSize, nmt.NamespaceIDSize(namespaceSize)).NewTree)
+	require.NoError(t, err)
+	want, err := eds.RowRoots()
+	require.NoError(t, err)
+
+	c := NewPooledConstructor(squareSize, nmt.NamespaceIDSize(namespaceSize))
+	// compute every root twice so that released trees are reused
+	for round := 0; round < 2; round++ {
+		for i := uint(0); i < 2*squareSize; i++ {
+			tree := c.NewTree(rsmt2d.Row, i)
+			for _, share := range eds.Row(i) {
+				require.NoError(t, tree.Push(share))
+			}
+			root, err := tree.Root()
+			require.NoError(t, err)
+			assert.Equal(t, want[i], root)
+			c.Release(tree)
+		}
+	}
+
+	// releasing a tree from an unpooled constructor is a no-op
+	tree := NewConstructor(squareSize).NewTree(rsmt2d.Row, 0)
+	c.Release(tree)
+	tree.(*ErasuredNamespacedMerkleTree).Release()
+}
+
+func TestConsumeRoot(t *testing.T) {
+	shares := sortedShares(4)
+	trees := make([]*ErasuredNamespacedMerkleTree, 2)
+	for i := range trees {
+		trees[i] = NewErasuredNamespacedMerkleTree(2, 0, nmt.NamespaceIDSize(namespaceSize))
+		for _, share := range shares {
+			require.NoError(t, trees[i].Push(share))
+		}
+	}
+	want, err := trees[0].Root()
+	require.NoError(t, err)
+	got, err := trees[1].ConsumeRoot()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestConstructorTakesSquareSizeFromSquare(t *testing.T) {
+	shares := sortedShares(4)
+	want, err := rsmt2d.ComputeExtendedDataSquare(shares, rsmt2d.NewLeoRSCodec(), NewConstructor(2, nmt.NamespaceIDSize(namespaceSize)).NewTree)
+	require.NoError(t, err)
+	wantRoots, err := want.RowRoots()
+	require.NoError(t, err)
+
+	got, err := rsmt2d.ComputeExtendedDataSquare(shares, rsmt2d.NewLeoRSCodec(), NewConstructor(0, nmt.NamespaceIDSize(namespaceSize)).NewTree)
+	require.NoError(t, err)
+	gotRoots, err := got.RowRoots()
+	require.NoError(t, err)
+	assert.Equal(t, wantRoots, gotRoots)
+}
+
+func TestSharesByNamespace(t *testing.T) {
+	// rows of the original data have the namespaces [1, 2] and [2, 4]
+	shares := make([][]byte, 4)
+	for i, ns := range []byte{1, 2, 2, 4} {
+		shares[i] = bytes.Repeat([]byte{ns}, shareSize)
+	}
+	eds, err := rsmt2d.ComputeExtendedDataSquare(shares, rsmt2d.NewLeoRSCodec(), NewConstructor(2, nmt.NamespaceIDSize(namespaceSize)).NewTree)
+	require.NoError(t, err)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+
+	namespace := func(ns byte) []byte { return bytes.Repeat([]byte{ns}, namespaceSize) }
+
+	rows, err := eds.SharesByNamespace(namespace(2))
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	for i, row := range rows {
+		assert.Equal(t, uint(i), row.Index)
+		assert.Equal(t, [][]byte{shares[1+i]}, row.Shares)
+		proof, ok := row.Proof.(nmt.Proof)
+		require.True(t, ok)
+		assert.True(t, proof.VerifyInclusion(sha256.New(), namespace(2), row.Shares, rowRoots[row.Index]))
+	}
+
+	rows, err = eds.SharesByNamespace(namespace(3))
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, uint(1), rows[0].Index)
+	assert.Empty(t, rows[0].Shares)
+	assert.True(t, rows[0].Proof.IsOfAbsence())
+
+	for _, ns := range []byte{0, 5} {
+		rows, err = eds.SharesByNamespace(namespace(ns))
+		require.NoError(t, err)
+		assert.Empty(t, rows)
+	}
+}
+
+func TestSample(t *testing.T) {
+	treeFn := NewConstructor(2, nmt.NamespaceIDSize(namespaceSize)).NewTree
+	eds, err := rsmt2d.ComputeExtendedDataSquare(sortedShares(4), rsmt2d.NewLeoRSCodec(), treeFn)
+	require.NoError(t, err)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+
+	// cover shares in and outside of the first quadrant
+	for _, cell := range []rsmt2d.CellIndex{{Row: 0, Col: 1}, {Row: 1, Col: 3}, {Row: 3, Col: 0}, {Row: 2, Col: 2}} {
+		sample, err := eds.Sample(cell.Row, cell.Col)
+		require.NoError(t, err)
+		assert.Equal(t, eds.GetCell(cell.Row, cell.Col), sample.Share())
+		assert.NoError(t, rsmt2d.VerifySample(rowRoots[cell.Row], sample, treeFn), "sample %s", cell)
+
+		sample.Proof.Share = bytes.Repeat([]byte{9}, shareSize)
+		assert.ErrorIs(t, rsmt2d.VerifySample(rowRoots[cell.Row], sample, treeFn), rsmt2d.ErrInvalidShareProof, "sample %s", cell)
+	}
+}
+
+func TestTreeParamsRoundTrip(t *testing.T) {
+	const squareSize = 2
+	treeFn := NewConstructor(squareSize, nmt.NamespaceIDSize(namespaceSize)).NewTree
+	want, err := rsmt2d.ComputeExtendedDataSquare(sortedShares(squareSize*squareSize), rsmt2d.NewLeoRSCodec(), treeFn)
+	require.NoError(t, err)
+	wantRoots, err := want.RowRoots()
+	require.NoError(t, err)
+
+	edsBytes, err := want.MarshalJSONWith(rsmt2d.WithTree(TreeName, TreeParams{SquareSize: squareSize, NamespaceSize: namespaceSize}))
+	require.NoError(t, err)
+	var eds rsmt2d.ExtendedDataSquare
+	require.NoError(t, json.Unmarshal(edsBytes, &eds))
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	assert.Equal(t, wantRoots, rowRoots)
+
+	invalid := map[string]any{
+		"missing parameters":     nil,
+		"missing namespace size": map[string]any{"square_size": squareSize},
+		"invalid namespace size": TreeParams{NamespaceSize: 256},
+	}
+	for name, params := range invalid {
+		t.Run(name, func(t *testing.T) {
+			edsBytes, err := want.MarshalJSONWith(rsmt2d.WithTree(TreeName, params))
+			require.NoError(t, err)
+			var eds rsmt2d.ExtendedDataSquare
+			assert.ErrorIs(t, json.Unmarshal(edsBytes, &eds), rsmt2d.ErrInvalidTreeParams)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package nmtwrapper
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/rsmt2d"
+)
+
+// RepairRowsForNamespace repairs only the rows of an extended data square
+// that can contain shares of namespace nsID and returns those shares in
+// row-major order. rowRoots are the roots of all rows of the square, computed
+// with a Constructor whose namespace size is len(nsID), and availableShares
+// holds the shares that are available, by their coordinates in the square.
+//
+// A row is repaired if it is in the original data square and the namespace
+// range of its root includes nsID. Each such row is decoded with codec from
+// its available shares and verified against its root. Returns an error
+// wrapping rsmt2d.ErrTooFewShares if a row has fewer than half of its shares,
+// or rsmt2d.ErrRootMismatch if a decoded row does not match its root.
+func RepairRowsForNamespace(
+	nsID []byte,
+	rowRoots [][]byte,
+	availableShares map[rsmt2d.CellIndex][]byte,
+	codec rsmt2d.Codec,
+) ([][]byte, error) {
+	width := uint(len(rowRoots))
+	if width == 0 || width%2 != 0 {
+		return nil, fmt.Errorf("got %d row roots, expected a positive even number", width)
+	}
+	if len(nsID) == 0 {
+		return nil, fmt.Errorf("namespace must not be empty")
+	}
+	rows := make(map[uint][][]byte)
+	for cell, share := range availableShares {
+		if cell.Row >= width || cell.Col >= width {
+			return nil, fmt.Errorf("%w: share %s for width %d", rsmt2d.ErrOutOfBounds, cell, width)
+		}
+		if rows[cell.Row] == nil {
+			rows[cell.Row] = make([][]byte, width)
+		}
+		rows[cell.Row][cell.Col] = share
+	}
+
+	treeFn := NewConstructor(uint64(width/2), nmt.NamespaceIDSize(len(nsID))).NewTree
+	var shares [][]byte
+	for rowIdx := uint(0); rowIdx < width/2; rowIdx++ {
+		inRange, err := rootIncludesNamespace(rowRoots[rowIdx], nsID)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", rowIdx, err)
+		}
+		if !inRange {
+			continue
+		}
+		available := rows[rowIdx]
+		if available == nil {
+			available = make([][]byte, width)
+		}
+		row, err := rsmt2d.RepairRow(available, rowIdx, rowRoots[rowIdx], codec, treeFn)
+		if err != nil {
+			return nil, err
+		}
+		for _, share := range row[:width/2] {
+			if bytes.Equal(rsmt2d.Share(share).Namespace(len(nsID)), nsID) {
+				shares = append(shares, share)
+			}
+		}
+	}
+	return shares, nil
+}
+
+// rootIncludesNamespace returns true if nsID is within the namespace range of
+// the NMT root, which starts with its minimum and maximum namespaces.
+func rootIncludesNamespace(root, nsID []byte) (bool, error) {
+	size := len(nsID)
+	if len(root) < 2*size {
+		return false, fmt.Errorf("root of %d bytes is too short for namespaces of %d bytes", len(root), size)
+	}
+	minNs, maxNs := root[:size], root[size:2*size]
+	return bytes.Compare(nsID, minNs) >= 0 && bytes.Compare(nsID, maxNs) <= 0, nil
+}
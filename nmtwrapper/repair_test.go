@@ -0,0 +1,73 @@
+package nmtwrapper
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/rsmt2d"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairRowsForNamespace(t *testing.T) {
+	// rows of the original data have the namespaces [1, 2] and [2, 4]
+	shares := make([][]byte, 4)
+	for i, ns := range []byte{1, 2, 2, 4} {
+		shares[i] = bytes.Repeat([]byte{ns}, shareSize)
+	}
+	codec := rsmt2d.NewLeoRSCodec()
+	eds, err := rsmt2d.ComputeExtendedDataSquare(shares, codec, NewConstructor(2, nmt.NamespaceIDSize(namespaceSize)).NewTree)
+	require.NoError(t, err)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+
+	namespace := func(ns byte) []byte { return bytes.Repeat([]byte{ns}, namespaceSize) }
+	// parityHalves returns the parity halves of the given rows
+	parityHalves := func(rows ...uint) map[rsmt2d.CellIndex][]byte {
+		available := make(map[rsmt2d.CellIndex][]byte)
+		for _, row := range rows {
+			for col := uint(2); col < 4; col++ {
+				available[rsmt2d.CellIndex{Row: row, Col: col}] = eds.GetCell(row, col)
+			}
+		}
+		return available
+	}
+
+	got, err := RepairRowsForNamespace(namespace(2), rowRoots, parityHalves(0, 1), codec)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{shares[1], shares[2]}, got)
+
+	t.Run("rows outside of the namespace are not needed", func(t *testing.T) {
+		got, err := RepairRowsForNamespace(namespace(1), rowRoots, parityHalves(0), codec)
+		require.NoError(t, err)
+		assert.Equal(t, [][]byte{shares[0]}, got)
+	})
+
+	t.Run("absent namespace", func(t *testing.T) {
+		got, err := RepairRowsForNamespace(namespace(3), rowRoots, parityHalves(0, 1), codec)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("too few shares", func(t *testing.T) {
+		available := parityHalves(0, 1)
+		delete(available, rsmt2d.CellIndex{Row: 0, Col: 2})
+		_, err := RepairRowsForNamespace(namespace(1), rowRoots, available, codec)
+		assert.ErrorIs(t, err, rsmt2d.ErrTooFewShares)
+	})
+
+	t.Run("corrupted share", func(t *testing.T) {
+		available := parityHalves(0, 1)
+		available[rsmt2d.CellIndex{Row: 1, Col: 3}] = bytes.Repeat([]byte{9}, shareSize)
+		_, err := RepairRowsForNamespace(namespace(4), rowRoots, available, codec)
+		assert.ErrorIs(t, err, rsmt2d.ErrRootMismatch)
+	})
+
+	t.Run("out of bounds", func(t *testing.T) {
+		available := parityHalves(0, 1)
+		available[rsmt2d.CellIndex{Row: 4, Col: 0}] = shares[0]
+		_, err := RepairRowsForNamespace(namespace(1), rowRoots, available, codec)
+		assert.ErrorIs(t, err, rsmt2d.ErrOutOfBounds)
+	})
+}
@@ -3,6 +3,8 @@ package rsmt2d
 // The contents of this file have been adapted from the source file available at https://github.com/celestiaorg/celestia-app/blob/bab6c0d0befe677ab8c2f4b83561c08affc7203e/pkg/wrapper/nmt_wrapper.go,
 // solely for the purpose of testing rsmt2d expected behavior when integrated with a NamespaceMerkleTree.
 // Please note that this file has undergone several modifications and may not match the original file exactly.
+// Applications should use the public nmtwrapper package instead; this copy remains because the
+// package's internal tests cannot import nmtwrapper without an import cycle.
 
 import (
 	"bytes"
@@ -15,7 +17,8 @@ import (
 
 // Fulfills the Tree interface and TreeConstructorFn function
 var (
-	_ Tree = &erasuredNamespacedMerkleTree{}
+	_ Tree          = &erasuredNamespacedMerkleTree{}
+	_ NamespaceTree = &erasuredNamespacedMerkleTree{}
 )
 
 // erasuredNamespacedMerkleTree wraps NamespaceMerkleTree to conform to the
@@ -48,6 +51,7 @@ type erasuredNamespacedMerkleTree struct {
 type nmtTree interface {
 	Root() ([]byte, error)
 	Push(namespacedData namespace.PrefixedData) error
+	ProveRange(start, end int) (nmt.Proof, error)
 }
 
 // newErasuredNamespacedMerkleTree creates a new erasuredNamespacedMerkleTree
@@ -132,6 +136,16 @@ func (w *erasuredNamespacedMerkleTree) Root() ([]byte, error) {
 	return root, nil
 }
 
+// ProveRange fulfills the rsmt2d.NamespaceTree interface by returning the
+// underlying NamespaceMerkleTree proof for the leaves in [start, end).
+func (w *erasuredNamespacedMerkleTree) ProveRange(start, end int) (NamespaceProof, error) {
+	proof, err := w.tree.ProveRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
 // incrementShareIndex increments the share index by one.
 func (w *erasuredNamespacedMerkleTree) incrementShareIndex() {
 	w.shareIndex++
@@ -0,0 +1,94 @@
+package rsmt2d
+
+import "sync"
+
+// EDSPool is a pool of extended data squares that allows the row-major and
+// column-major slices of share references of a square to be reused, e.g. by
+// pipelines that process one square per block. The shares themselves are not
+// reused, as a square does not own shares set via SetCell or passed to
+// ComputeExtendedDataSquare. Squares are pooled by width and share size.
+// EDSPool is safe for concurrent use.
+type EDSPool struct {
+	codec         Codec
+	treeCreatorFn TreeConstructorFn
+
+	mu    sync.Mutex
+	pools map[edsPoolKey]*sync.Pool
+}
+
+type edsPoolKey struct {
+	width     uint
+	shareSize uint
+}
+
+// NewEDSPool returns a new EDSPool whose squares use codec and treeCreatorFn.
+func NewEDSPool(codec Codec, treeCreatorFn TreeConstructorFn) *EDSPool {
+	return &EDSPool{
+		codec:         codec,
+		treeCreatorFn: treeCreatorFn,
+		pools:         make(map[edsPoolKey]*sync.Pool),
+	}
+}
+
+// Get returns an extended data square with a width of edsWidth in which all
+// shares are nil. The square is either taken from the pool or newly
+// allocated if the pool is empty.
+func (p *EDSPool) Get(edsWidth uint, shareSize uint) (*ExtendedDataSquare, error) {
+	if eds, ok := p.pool(edsWidth, shareSize).Get().(*ExtendedDataSquare); ok {
+		return eds, nil
+	}
+	return NewExtendedDataSquare(p.codec, p.treeCreatorFn, edsWidth, shareSize)
+}
+
+// Put resets eds and returns it to the pool. eds may have been created with
+// a different codec or tree constructor than the pool's, in which case it is
+// switched to the pool's. eds must not be used after it has been returned to
+// the pool.
+func (p *EDSPool) Put(eds *ExtendedDataSquare) {
+	eds.Reset()
+	eds.codec = p.codec
+	eds.createTreeFn = p.treeCreatorFn
+	eds.originalDataWidth = eds.width / 2
+	p.pool(eds.width, eds.shareSize).Put(eds)
+}
+
+// pool returns the pool for squares of the given width and share size.
+func (p *EDSPool) pool(edsWidth uint, shareSize uint) *sync.Pool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := edsPoolKey{width: edsWidth, shareSize: shareSize}
+	pool, ok := p.pools[key]
+	if !ok {
+		pool = &sync.Pool{}
+		p.pools[key] = pool
+	}
+	return pool
+}
+
+// TreePool is a pool of DefaultTrees. Its NewTree method can be used as a
+// TreeConstructorFn; root computation and repair release every tree once its
+// root has been read, so the trees are reused instead of being allocated
+// afresh for each row and column. Custom Tree implementations can be pooled
+// the same way by implementing Releasable. TreePool is safe for concurrent
+// use.
+type TreePool struct {
+	pool sync.Pool
+}
+
+// NewTreePool returns a new, empty TreePool.
+func NewTreePool() *TreePool {
+	return &TreePool{}
+}
+
+// NewTree returns a DefaultTree, either taken from the pool or newly
+// allocated if the pool is empty.
+func (p *TreePool) NewTree(axis Axis, index uint) Tree {
+	if d, ok := p.pool.Get().(*DefaultTree); ok {
+		d.pool = p
+		return d
+	}
+	d := NewDefaultTree(axis, index).(*DefaultTree)
+	d.pool = p
+	return d
+}
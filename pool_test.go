@@ -0,0 +1,108 @@
+package rsmt2d
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReset(t *testing.T) {
+	eds := createExampleEds(t, shareSize)
+	_, err := eds.RowRoots()
+	require.NoError(t, err)
+
+	eds.Reset()
+	assert.Equal(t, uint(4), eds.Width())
+	for _, share := range eds.Flattened() {
+		assert.Nil(t, share)
+	}
+	for i := uint(0); i < eds.Width(); i++ {
+		assert.Equal(t, make([][]byte, eds.Width()), eds.Col(i))
+	}
+	_, err = eds.RowRoots()
+	assert.Error(t, err)
+
+	require.NoError(t, eds.SetCell(0, 0, ones))
+	assert.Equal(t, ones, eds.GetCell(0, 0))
+}
+
+func TestEDSPool(t *testing.T) {
+	pool := NewEDSPool(NewLeoRSCodec(), NewDefaultTree)
+
+	eds, err := pool.Get(4, shareSize)
+	require.NoError(t, err)
+	assert.Equal(t, uint(4), eds.Width())
+	require.NoError(t, eds.SetCell(1, 1, ones))
+
+	pool.Put(eds)
+
+	for i := 0; i < 2; i++ {
+		eds, err = pool.Get(4, shareSize)
+		require.NoError(t, err)
+		assert.Equal(t, uint(4), eds.Width())
+		assert.Nil(t, eds.GetCell(1, 1))
+	}
+
+	eds, err = pool.Get(8, shareSize)
+	require.NoError(t, err)
+	assert.Equal(t, uint(8), eds.Width())
+
+	_, err = pool.Get(3, shareSize)
+	assert.Error(t, err)
+
+	t.Run("switches squares of other codecs to the pool's codec", func(t *testing.T) {
+		pool := NewEDSPool(NewLeoRSCodec(), NewDefaultTree)
+		other, err := ComputeExtendedDataSquare(generateRandData(4, 64), NewRSGF8Codec(), NewDefaultTree)
+		require.NoError(t, err)
+		pool.Put(other)
+
+		eds, err := pool.Get(4, 64)
+		require.NoError(t, err)
+		assert.Equal(t, Leopard, eds.codec.Name())
+	})
+}
+
+// releaseCountingTree counts the trees released by rsmt2d.
+type releaseCountingTree struct {
+	Tree
+	released *atomic.Int64
+}
+
+func (r releaseCountingTree) Release() {
+	r.released.Add(1)
+}
+
+func TestTreePool(t *testing.T) {
+	want := createExampleEds(t, shareSize)
+	wantRowRoots, err := want.RowRoots()
+	require.NoError(t, err)
+	wantColRoots, err := want.ColRoots()
+	require.NoError(t, err)
+
+	pool := NewTreePool()
+	// compute the roots twice so that released trees are reused
+	for i := 0; i < 2; i++ {
+		eds, err := ComputeExtendedDataSquare(want.FlattenedODS(), NewLeoRSCodec(), pool.NewTree)
+		require.NoError(t, err)
+		rowRoots, err := eds.RowRoots()
+		require.NoError(t, err)
+		assert.Equal(t, wantRowRoots, rowRoots)
+		colRoots, err := eds.ColRoots()
+		require.NoError(t, err)
+		assert.Equal(t, wantColRoots, colRoots)
+	}
+
+	t.Run("releases trees after computing roots", func(t *testing.T) {
+		var released atomic.Int64
+		treeFn := func(axis Axis, index uint) Tree {
+			return releaseCountingTree{Tree: NewDefaultTree(axis, index), released: &released}
+		}
+		eds, err := ComputeExtendedDataSquare(want.FlattenedODS(), NewLeoRSCodec(), treeFn)
+		require.NoError(t, err)
+		_, err = eds.RowRoots()
+		require.NoError(t, err)
+		assert.Equal(t, int64(2*eds.Width()), released.Load())
+	})
+}
@@ -0,0 +1,335 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// ErrNotNamespaceTree is returned when a namespace proof is requested from an
+// extended data square whose Tree implementation is not namespace-aware.
+var ErrNotNamespaceTree = errors.New("tree does not implement NamespaceTree")
+
+// ErrNotNamespaceQueryTree is returned when the shares of a namespace are
+// requested from an extended data square whose Tree implementation cannot
+// look up namespaces.
+var ErrNotNamespaceQueryTree = errors.New("tree does not implement NamespaceQueryTree")
+
+// ErrNotProvingTree is returned when an inclusion proof is requested from an
+// extended data square whose Tree implementation cannot generate one.
+var ErrNotProvingTree = errors.New("tree does not implement ProvingTree")
+
+// ErrNotSubtreeRootTree is returned when subtree roots are requested from an
+// extended data square whose Tree implementation cannot compute them.
+var ErrNotSubtreeRootTree = errors.New("tree does not implement SubtreeRootTree")
+
+// ErrNotRangeProvingTree is returned when a range proof is requested from an
+// extended data square whose Tree implementation cannot generate one.
+var ErrNotRangeProvingTree = errors.New("tree does not implement RangeProvingTree")
+
+// ErrNotRangeVerifyingTree is returned by VerifyShareRangeProof when the Tree
+// implementation cannot verify range proofs.
+var ErrNotRangeVerifyingTree = errors.New("tree does not implement RangeVerifyingTree")
+
+// ErrInvalidShareProof is returned by VerifyShareProof when a ShareProof does
+// not prove the inclusion of its share, and by VerifyShareRangeProof when a
+// ShareRangeProof does not prove the inclusion of its shares.
+var ErrInvalidShareProof = errors.New("invalid share proof")
+
+// ProveNamespaceRange returns a namespace proof for the shares in [start, end)
+// of the row or column at axisIdx. The proof is generated by the underlying
+// Tree, which must implement NamespaceTree. Returns an error if the axis is
+// incomplete (i.e. some shares are nil).
+func (eds *ExtendedDataSquare) ProveNamespaceRange(axis Axis, axisIdx uint, start, end uint) (NamespaceProof, error) {
+	if axisIdx >= eds.width {
+		return nil, fmt.Errorf("%w: %s index %d for width %d", ErrOutOfBounds, axis, axisIdx, eds.width)
+	}
+	if start >= end || end > eds.width {
+		return nil, fmt.Errorf("invalid range [%d, %d) for width %d", start, end, eds.width)
+	}
+
+	newTree := eds.newTree(axis, axisIdx)
+	defer releaseTree(newTree)
+	tree, ok := newTree.(NamespaceTree)
+	if !ok {
+		return nil, ErrNotNamespaceTree
+	}
+	if err := eds.pushAxis(tree, axis, axisIdx); err != nil {
+		return nil, err
+	}
+	return tree.ProveRange(int(start), int(end))
+}
+
+// NamespacedRow holds the shares of a namespace in a single row of the
+// original data, along with the proof of their inclusion in the row root.
+// If the row's namespace range covers the namespace but none of its shares
+// belongs to it, Shares is empty and Proof is a proof of absence.
+type NamespacedRow struct {
+	// Index is the index of the row.
+	Index uint
+	// Shares are the shares of the namespace, in order.
+	Shares [][]byte
+	// Proof is the namespace proof generated by the underlying Tree.
+	Proof NamespaceProof
+}
+
+// SharesByNamespace returns the shares of namespace nID in every row of the
+// original data whose namespace range covers nID. The underlying Tree must
+// implement NamespaceQueryTree. Returns an error if any row of the original
+// data is incomplete (i.e. some shares are nil).
+func (eds *ExtendedDataSquare) SharesByNamespace(nID []byte) ([]NamespacedRow, error) {
+	var rows []NamespacedRow
+	for rowIdx := uint(0); rowIdx < eds.width/2; rowIdx++ {
+		proof, err := eds.proveNamespace(rowIdx, nID)
+		if err != nil {
+			return nil, err
+		}
+		if proof == nil {
+			continue
+		}
+		row := NamespacedRow{Index: rowIdx, Proof: proof}
+		if !proof.IsOfAbsence() {
+			row.Shares = deepCopy(eds.row(rowIdx)[proof.Start():proof.End()])
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// proveNamespace returns the proof of namespace nID in row rowIdx, see
+// SharesByNamespace. The tree is released afterwards.
+func (eds *ExtendedDataSquare) proveNamespace(rowIdx uint, nID []byte) (NamespaceProof, error) {
+	newTree := eds.newTree(Row, rowIdx)
+	defer releaseTree(newTree)
+	tree, ok := newTree.(NamespaceQueryTree)
+	if !ok {
+		return nil, ErrNotNamespaceQueryTree
+	}
+	if err := eds.pushAxis(tree, Row, rowIdx); err != nil {
+		return nil, err
+	}
+	return tree.ProveNamespace(nID)
+}
+
+// ShareProof is an inclusion proof of a share against the root of its row or
+// column.
+type ShareProof struct {
+	// Share is the proven share.
+	Share []byte
+	// Axis is the axis whose root the proof is against.
+	Axis Axis
+	// Root is the root of the row or column.
+	Root []byte
+	// Proof is the Merkle path generated by the underlying ProvingTree.
+	Proof [][]byte
+	// Index is the index of the share within the row or column.
+	Index uint
+	// NumLeaves is the number of shares in the row or column.
+	NumLeaves uint
+}
+
+// VerifyShareProof returns nil if proof proves the inclusion of its share
+// under root, the root of the row or column axisIdx along proof.Axis. The
+// proof is verified with a tree created by treeCreatorFn, which must
+// implement VerifyingTree. Otherwise, an error wrapping ErrInvalidShareProof
+// is returned.
+func VerifyShareProof(proof ShareProof, root []byte, axisIdx uint, treeCreatorFn TreeConstructorFn) error {
+	if !bytes.Equal(proof.Root, root) {
+		return fmt.Errorf("%w: root %x does not match %x", ErrInvalidShareProof, proof.Root, root)
+	}
+	if proof.Index >= proof.NumLeaves || axisIdx >= proof.NumLeaves {
+		return fmt.Errorf("%w: index %d of %s %d is out of bounds for %d leaves", ErrInvalidShareProof, proof.Index, proof.Axis, axisIdx, proof.NumLeaves)
+	}
+	info := SquareInfo{Width: proof.NumLeaves, ShareSize: uint(len(proof.Share))}
+	tree, ok := newSquareTree(treeCreatorFn, proof.Axis, axisIdx, info).(VerifyingTree)
+	if !ok {
+		return ErrNotVerifyingTree
+	}
+	defer releaseTree(tree)
+	if !tree.VerifyProof(root, proof.Share, proof.Proof, int(proof.Index), int(proof.NumLeaves)) {
+		return fmt.Errorf("%w: invalid inclusion proof for share %d of %s %d", ErrInvalidShareProof, proof.Index, proof.Axis, axisIdx)
+	}
+	return nil
+}
+
+// ShareRangeProof is a single inclusion proof of consecutive shares of a row
+// against the root of the row.
+type ShareRangeProof struct {
+	// Shares are the proven shares, in order.
+	Shares [][]byte
+	// Root is the root of the row.
+	Root []byte
+	// Proof is the range proof generated by the underlying RangeProvingTree.
+	Proof [][]byte
+	// Start is the index of the first proven share within the row.
+	Start uint
+	// End is the index following the last proven share within the row.
+	End uint
+	// NumLeaves is the number of shares in the row.
+	NumLeaves uint
+}
+
+// ProveShareRange returns a single inclusion proof of the shares in
+// [start, end) of the row at rowIdx against the root of the row, which is
+// far smaller than a ShareProof for each share. The proof is generated by the
+// underlying Tree, which must implement RangeProvingTree. Namespace-aware
+// trees prove ranges with ProveNamespaceRange instead. Returns an error if
+// the row is incomplete (i.e. some shares are nil).
+func (eds *ExtendedDataSquare) ProveShareRange(rowIdx, start, end uint) (ShareRangeProof, error) {
+	if rowIdx >= eds.width {
+		return ShareRangeProof{}, fmt.Errorf("%w: row index %d for width %d", ErrOutOfBounds, rowIdx, eds.width)
+	}
+	if start >= end || end > eds.width {
+		return ShareRangeProof{}, fmt.Errorf("invalid range [%d, %d) for width %d", start, end, eds.width)
+	}
+	newTree := eds.newTree(Row, rowIdx)
+	defer releaseTree(newTree)
+	tree, ok := newTree.(RangeProvingTree)
+	if !ok {
+		return ShareRangeProof{}, ErrNotRangeProvingTree
+	}
+	if err := eds.pushAxis(tree, Row, rowIdx); err != nil {
+		return ShareRangeProof{}, err
+	}
+	root, proof, err := tree.ProveLeafRange(int(start), int(end))
+	if err != nil {
+		return ShareRangeProof{}, err
+	}
+	return ShareRangeProof{
+		Shares:    deepCopy(eds.row(rowIdx)[start:end]),
+		Root:      root,
+		Proof:     proof,
+		Start:     start,
+		End:       end,
+		NumLeaves: eds.width,
+	}, nil
+}
+
+// VerifyShareRangeProof returns nil if proof proves the inclusion of its
+// shares under root, the root of row rowIdx. The proof is verified with a
+// tree created by treeCreatorFn, which must implement RangeVerifyingTree.
+// Otherwise, an error wrapping ErrInvalidShareProof is returned.
+func VerifyShareRangeProof(proof ShareRangeProof, root []byte, rowIdx uint, treeCreatorFn TreeConstructorFn) error {
+	if !bytes.Equal(proof.Root, root) {
+		return fmt.Errorf("%w: root %x does not match %x", ErrInvalidShareProof, proof.Root, root)
+	}
+	if proof.Start >= proof.End || proof.End > proof.NumLeaves || rowIdx >= proof.NumLeaves {
+		return fmt.Errorf("%w: range [%d, %d) of row %d is out of bounds for %d leaves", ErrInvalidShareProof, proof.Start, proof.End, rowIdx, proof.NumLeaves)
+	}
+	if uint(len(proof.Shares)) != proof.End-proof.Start {
+		return fmt.Errorf("%w: got %d shares for range [%d, %d)", ErrInvalidShareProof, len(proof.Shares), proof.Start, proof.End)
+	}
+	info := SquareInfo{Width: proof.NumLeaves, ShareSize: uint(getShareSize(proof.Shares))}
+	tree, ok := newSquareTree(treeCreatorFn, Row, rowIdx, info).(RangeVerifyingTree)
+	if !ok {
+		return ErrNotRangeVerifyingTree
+	}
+	defer releaseTree(tree)
+	if !tree.VerifyLeafRange(root, proof.Shares, proof.Proof, int(proof.Start), int(proof.End)) {
+		return fmt.Errorf("%w: invalid range proof for shares [%d, %d) of row %d", ErrInvalidShareProof, proof.Start, proof.End, rowIdx)
+	}
+	return nil
+}
+
+// RowProof returns the root of the row at rowIdx along with the inclusion
+// proof for the share at colIdx. The proof is generated by the underlying
+// Tree, which must implement ProvingTree. Returns an error if the row is
+// incomplete (i.e. some shares are nil).
+func (eds *ExtendedDataSquare) RowProof(rowIdx, colIdx uint) ([]byte, [][]byte, error) {
+	proof, err := eds.proveShare(Row, rowIdx, colIdx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return proof.Root, proof.Proof, nil
+}
+
+// ProveShare returns the inclusion proof of the share at (rowIdx, colIdx)
+// against the root of row rowIdx. The underlying Tree must implement
+// ProvingTree. Returns an error if the row is incomplete (i.e. some shares
+// are nil).
+func (eds *ExtendedDataSquare) ProveShare(rowIdx, colIdx uint) (ShareProof, error) {
+	return eds.proveShare(Row, rowIdx, colIdx)
+}
+
+// ProveColShare is like ProveShare, but proves the share against the root of
+// column colIdx.
+func (eds *ExtendedDataSquare) ProveColShare(rowIdx, colIdx uint) (ShareProof, error) {
+	return eds.proveShare(Col, rowIdx, colIdx)
+}
+
+// proveShare returns the inclusion proof of the share at (rowIdx, colIdx)
+// against the root of its row or column, depending on axis.
+func (eds *ExtendedDataSquare) proveShare(axis Axis, rowIdx, colIdx uint) (ShareProof, error) {
+	if rowIdx >= eds.width || colIdx >= eds.width {
+		return ShareProof{}, fmt.Errorf("%w: cell (%d, %d) for width %d", ErrOutOfBounds, rowIdx, colIdx, eds.width)
+	}
+	axisIdx, index := rowIdx, colIdx
+	if axis == Col {
+		axisIdx, index = colIdx, rowIdx
+	}
+	newTree := eds.newTree(axis, axisIdx)
+	defer releaseTree(newTree)
+	tree, ok := newTree.(ProvingTree)
+	if !ok {
+		return ShareProof{}, ErrNotProvingTree
+	}
+	if err := eds.pushAxis(tree, axis, axisIdx); err != nil {
+		return ShareProof{}, err
+	}
+	root, proof, err := tree.Prove(int(index))
+	if err != nil {
+		return ShareProof{}, err
+	}
+	return ShareProof{
+		Share:     eds.GetCell(rowIdx, colIdx),
+		Axis:      axis,
+		Root:      root,
+		Proof:     proof,
+		Index:     index,
+		NumLeaves: eds.width,
+	}, nil
+}
+
+// RowSubtreeRoots returns the roots of the consecutive subtrees of
+// subtreeWidth shares each of the row at rowIdx. With a subtreeWidth of half
+// the width of the square, these are the roots of the original and parity
+// halves of the row. The roots are computed by the underlying Tree, which must
+// implement SubtreeRootTree. Returns an error if the row is incomplete (i.e.
+// some shares are nil).
+func (eds *ExtendedDataSquare) RowSubtreeRoots(rowIdx uint, subtreeWidth uint) ([][]byte, error) {
+	if rowIdx >= eds.width {
+		return nil, fmt.Errorf("%w: row index %d for width %d", ErrOutOfBounds, rowIdx, eds.width)
+	}
+	newTree := eds.newTree(Row, rowIdx)
+	defer releaseTree(newTree)
+	tree, ok := newTree.(SubtreeRootTree)
+	if !ok {
+		return nil, ErrNotSubtreeRootTree
+	}
+	if err := eds.pushAxis(tree, Row, rowIdx); err != nil {
+		return nil, err
+	}
+	return tree.SubtreeRoots(int(subtreeWidth))
+}
+
+// pushAxis pushes every share of the row or column at axisIdx to tree.
+func (eds *ExtendedDataSquare) pushAxis(tree Tree, axis Axis, axisIdx uint) error {
+	var shares [][]byte
+	switch axis {
+	case Row:
+		shares = eds.row(axisIdx)
+	case Col:
+		shares = eds.col(axisIdx)
+	default:
+		return fmt.Errorf("invalid axis type: %d", axis)
+	}
+	if !isComplete(shares) {
+		return fmt.Errorf("%w: can not push %s %d to tree", ErrIncompleteAxis, axis, axisIdx)
+	}
+	for _, d := range shares {
+		if err := tree.Push(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,322 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"sync/atomic"
+	"testing"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProveNamespaceRange(t *testing.T) {
+	const odsWidth = 4
+	const namespaceSize = 8
+
+	treeFn := newErasuredNamespacedMerkleTreeConstructor(odsWidth, nmt.NamespaceIDSize(namespaceSize))
+	eds, err := ComputeExtendedDataSquare(genRandSortedDS(odsWidth, shareSize, namespaceSize), NewLeoRSCodec(), treeFn)
+	require.NoError(t, err)
+
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+
+	t.Run("row", func(t *testing.T) {
+		proof, err := eds.ProveNamespaceRange(Row, 1, 2, 3)
+		require.NoError(t, err)
+		assert.Equal(t, 2, proof.Start())
+		assert.Equal(t, 3, proof.End())
+
+		share := eds.GetCell(1, 2)
+		nmtProof, ok := proof.(nmt.Proof)
+		require.True(t, ok)
+		assert.True(t, nmtProof.VerifyInclusion(sha256.New(), share[:namespaceSize], [][]byte{share}, rowRoots[1]))
+	})
+
+	t.Run("col", func(t *testing.T) {
+		proof, err := eds.ProveNamespaceRange(Col, 3, 0, 1)
+		require.NoError(t, err)
+
+		share := eds.GetCell(0, 3)
+		nmtProof, ok := proof.(nmt.Proof)
+		require.True(t, ok)
+		assert.True(t, nmtProof.VerifyInclusion(sha256.New(), share[:namespaceSize], [][]byte{share}, colRoots[3]))
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		_, err := eds.ProveNamespaceRange(Row, 0, 3, 3)
+		assert.Error(t, err)
+		_, err = eds.ProveNamespaceRange(Row, 0, 0, 2*odsWidth+1)
+		assert.Error(t, err)
+		_, err = eds.ProveNamespaceRange(Col, 2*odsWidth, 0, 1)
+		assert.Error(t, err)
+	})
+
+	t.Run("incomplete axis", func(t *testing.T) {
+		incomplete, err := ImportExtendedDataSquare(eds.Flattened(), NewLeoRSCodec(), treeFn)
+		require.NoError(t, err)
+		incomplete.setCell(0, 0, nil)
+		_, err = incomplete.ProveNamespaceRange(Row, 0, 1, 2)
+		assert.Error(t, err)
+	})
+}
+
+func TestProveNamespaceRangeNotNamespaceTree(t *testing.T) {
+	eds := createExampleEds(t, shareSize)
+	_, err := eds.ProveNamespaceRange(Row, 0, 0, 1)
+	assert.ErrorIs(t, err, ErrNotNamespaceTree)
+}
+
+func TestRowProof(t *testing.T) {
+	eds := createExampleEds(t, shareSize)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+
+	for rowIdx := uint(0); rowIdx < eds.Width(); rowIdx++ {
+		for colIdx := uint(0); colIdx < eds.Width(); colIdx++ {
+			root, proof, err := eds.RowProof(rowIdx, colIdx)
+			require.NoError(t, err)
+			assert.Equal(t, rowRoots[rowIdx], root)
+			_, want, _, _, err := computeRowProof(eds.dataSquare, rowIdx, colIdx)
+			require.NoError(t, err)
+			assert.Equal(t, want, proof)
+		}
+	}
+
+	t.Run("out of bounds", func(t *testing.T) {
+		_, _, err := eds.RowProof(0, eds.Width())
+		assert.Error(t, err)
+	})
+	t.Run("incomplete row", func(t *testing.T) {
+		incomplete, err := ImportExtendedDataSquare(eds.Flattened(), NewLeoRSCodec(), NewDefaultTree)
+		require.NoError(t, err)
+		incomplete.setCell(0, 0, nil)
+		_, _, err = incomplete.RowProof(0, 1)
+		assert.Error(t, err)
+	})
+	t.Run("not a proving tree", func(t *testing.T) {
+		eds := createTestEdsWithNMT(t, NewLeoRSCodec(), shareSize, 8, 1, 2, 3, 4)
+		_, _, err := eds.RowProof(0, 0)
+		assert.ErrorIs(t, err, ErrNotProvingTree)
+	})
+}
+
+func TestRowSubtreeRoots(t *testing.T) {
+	eds := createExampleEds(t, shareSize)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+
+	for rowIdx := uint(0); rowIdx < eds.Width(); rowIdx++ {
+		roots, err := eds.RowSubtreeRoots(rowIdx, eds.Width())
+		require.NoError(t, err)
+		assert.Equal(t, [][]byte{rowRoots[rowIdx]}, roots)
+
+		roots, err = eds.RowSubtreeRoots(rowIdx, eds.Width()/2)
+		require.NoError(t, err)
+		require.Len(t, roots, 2)
+		row := eds.Row(rowIdx)
+		for half, shares := range [][][]byte{row[:eds.Width()/2], row[eds.Width()/2:]} {
+			tree := NewDefaultTree(Row, rowIdx)
+			for _, share := range shares {
+				require.NoError(t, tree.Push(share))
+			}
+			want, err := tree.Root()
+			require.NoError(t, err)
+			assert.Equal(t, want, roots[half])
+		}
+	}
+
+	t.Run("invalid subtree width", func(t *testing.T) {
+		for _, width := range []uint{0, 3, 2 * eds.Width()} {
+			_, err := eds.RowSubtreeRoots(0, width)
+			assert.Error(t, err)
+		}
+		_, err := eds.RowSubtreeRoots(eds.Width(), 1)
+		assert.Error(t, err)
+	})
+	t.Run("not a subtree root tree", func(t *testing.T) {
+		eds := createTestEdsWithNMT(t, NewLeoRSCodec(), shareSize, 8, 1, 2, 3, 4)
+		_, err := eds.RowSubtreeRoots(0, 2)
+		assert.ErrorIs(t, err, ErrNotSubtreeRootTree)
+	})
+}
+
+func TestProveShare(t *testing.T) {
+	eds := createExampleEds(t, shareSize)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+
+	for rowIdx := uint(0); rowIdx < eds.Width(); rowIdx++ {
+		for colIdx := uint(0); colIdx < eds.Width(); colIdx++ {
+			proof, err := eds.ProveShare(rowIdx, colIdx)
+			require.NoError(t, err)
+			_, wantProof, wantIndex, wantNumLeaves, err := computeRowProof(eds.dataSquare, rowIdx, colIdx)
+			require.NoError(t, err)
+			assert.Equal(t, ShareProof{
+				Share:     eds.GetCell(rowIdx, colIdx),
+				Axis:      Row,
+				Root:      rowRoots[rowIdx],
+				Proof:     wantProof,
+				Index:     wantIndex,
+				NumLeaves: wantNumLeaves,
+			}, proof)
+
+			proof, err = eds.ProveColShare(rowIdx, colIdx)
+			require.NoError(t, err)
+			assert.Equal(t, Col, proof.Axis)
+			assert.Equal(t, colRoots[colIdx], proof.Root)
+			assert.Equal(t, rowIdx, proof.Index)
+			assert.Equal(t, eds.GetCell(rowIdx, colIdx), proof.Share)
+		}
+	}
+
+	_, err = eds.ProveColShare(eds.Width(), 0)
+	assert.Error(t, err)
+}
+
+func TestVerifyShareProof(t *testing.T) {
+	eds := createExampleEds(t, shareSize)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+
+	rowProof, err := eds.ProveShare(1, 2)
+	require.NoError(t, err)
+	assert.NoError(t, VerifyShareProof(rowProof, rowRoots[1], 1, NewDefaultTree))
+	colProof, err := eds.ProveColShare(1, 2)
+	require.NoError(t, err)
+	assert.NoError(t, VerifyShareProof(colProof, colRoots[2], 2, NewDefaultTree))
+
+	wrongShare := rowProof
+	wrongShare.Share = twos
+	wrongIndex := rowProof
+	wrongIndex.Index = 3
+	outOfBounds := rowProof
+	outOfBounds.Index = eds.Width()
+	for name, proof := range map[string]ShareProof{
+		"wrong share":   wrongShare,
+		"wrong index":   wrongIndex,
+		"out of bounds": outOfBounds,
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, VerifyShareProof(proof, rowRoots[1], 1, NewDefaultTree), ErrInvalidShareProof)
+		})
+	}
+	assert.ErrorIs(t, VerifyShareProof(rowProof, rowRoots[0], 1, NewDefaultTree), ErrInvalidShareProof)
+	assert.ErrorIs(t, VerifyShareProof(rowProof, rowRoots[1], 1, func(axis Axis, index uint) Tree {
+		// hide the optional interfaces of the default tree
+		return struct{ Tree }{NewDefaultTree(axis, index)}
+	}), ErrNotVerifyingTree)
+}
+
+func TestProveShareRange(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(generateRandData(64, shareSize), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+
+	const rowIdx = 3
+	for start := uint(0); start < eds.Width(); start++ {
+		for end := start + 1; end <= eds.Width(); end++ {
+			proof, err := eds.ProveShareRange(rowIdx, start, end)
+			require.NoError(t, err)
+			assert.Equal(t, eds.Row(rowIdx)[start:end], proof.Shares)
+			assert.Equal(t, rowRoots[rowIdx], proof.Root)
+			assert.NoError(t, VerifyShareRangeProof(proof, rowRoots[rowIdx], rowIdx, NewDefaultTree), "range [%d, %d)", start, end)
+		}
+	}
+
+	proof, err := eds.ProveShareRange(rowIdx, 2, 6)
+	require.NoError(t, err)
+	assert.Less(t, len(proof.Proof), 4*4, "the range proof should be smaller than individual share proofs")
+
+	wrongShare := proof
+	wrongShare.Shares = deepCopy(proof.Shares)
+	wrongShare.Shares[1][0]++
+	wrongRange := proof
+	wrongRange.Start, wrongRange.End = 3, 7
+	missingShare := proof
+	missingShare.Shares = proof.Shares[1:]
+	outOfBounds := proof
+	outOfBounds.End = eds.Width() + 1
+	for name, proof := range map[string]ShareRangeProof{
+		"wrong share":   wrongShare,
+		"wrong range":   wrongRange,
+		"missing share": missingShare,
+		"out of bounds": outOfBounds,
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, VerifyShareRangeProof(proof, rowRoots[rowIdx], rowIdx, NewDefaultTree), ErrInvalidShareProof)
+		})
+	}
+	assert.ErrorIs(t, VerifyShareRangeProof(proof, rowRoots[0], rowIdx, NewDefaultTree), ErrInvalidShareProof)
+
+	// hideOptional hides the optional interfaces of the default tree
+	hideOptional := func(axis Axis, index uint) Tree {
+		return struct{ Tree }{NewDefaultTree(axis, index)}
+	}
+	assert.ErrorIs(t, VerifyShareRangeProof(proof, rowRoots[rowIdx], rowIdx, hideOptional), ErrNotRangeVerifyingTree)
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		_, err := eds.ProveShareRange(eds.Width(), 0, 1)
+		assert.ErrorIs(t, err, ErrOutOfBounds)
+		_, err = eds.ProveShareRange(0, 2, 2)
+		assert.Error(t, err)
+		_, err = eds.ProveShareRange(0, 0, eds.Width()+1)
+		assert.Error(t, err)
+
+		other, err := ComputeExtendedDataSquare(generateRandData(4, shareSize), NewLeoRSCodec(), hideOptional)
+		require.NoError(t, err)
+		_, err = other.ProveShareRange(0, 0, 1)
+		assert.ErrorIs(t, err, ErrNotRangeProvingTree)
+	})
+}
+
+func TestSharesByNamespaceNotNamespaceQueryTree(t *testing.T) {
+	eds := createExampleEds(t, shareSize)
+	_, err := eds.SharesByNamespace(bytes.Repeat([]byte{1}, 8))
+	assert.ErrorIs(t, err, ErrNotNamespaceQueryTree)
+}
+
+// releaseCountingDefaultTree is like releaseCountingTree, but keeps the
+// optional interfaces of DefaultTree.
+type releaseCountingDefaultTree struct {
+	*DefaultTree
+	released *atomic.Int64
+}
+
+func (t *releaseCountingDefaultTree) Release() {
+	t.released.Add(1)
+}
+
+func TestProofsReleaseTrees(t *testing.T) {
+	var created, released atomic.Int64
+	treeFn := func(axis Axis, index uint) Tree {
+		created.Add(1)
+		return &releaseCountingDefaultTree{NewDefaultTree(axis, index).(*DefaultTree), &released}
+	}
+	eds, err := ComputeExtendedDataSquare(generateRandData(4, shareSize), NewLeoRSCodec(), treeFn)
+	require.NoError(t, err)
+
+	_, err = eds.ProveShare(1, 2)
+	require.NoError(t, err)
+	_, err = eds.ProveColShare(1, 2)
+	require.NoError(t, err)
+	_, err = eds.ProveShareRange(1, 0, 2)
+	require.NoError(t, err)
+	_, err = eds.RowSubtreeRoots(1, 2)
+	require.NoError(t, err)
+	_, err = eds.ProveNamespaceRange(Row, 1, 0, 2)
+	require.ErrorIs(t, err, ErrNotNamespaceTree)
+	_, err = eds.SharesByNamespace(bytes.Repeat([]byte{1}, 8))
+	require.ErrorIs(t, err, ErrNotNamespaceQueryTree)
+
+	assert.NotZero(t, created.Load())
+	assert.Equal(t, created.Load(), released.Load())
+}
@@ -0,0 +1,101 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"errors"
+)
+
+// CellIndex identifies a cell of an extended data square by its row and column
+// index.
+type CellIndex struct {
+	Row, Col uint
+}
+
+// RepairWithQuarantine attempts to repair an incomplete extended data square
+// like Repair. Unlike Repair, if a row or column fails verification, the
+// shares that caused the failure are quarantined: they are removed from the
+// EDS, treated as missing and the repair is retried without them. This allows
+// recovering from a small number of locally corrupted shares (e.g. a corrupted
+// disk sector) without declaring the square Byzantine.
+//
+// A share is only quarantined if the row or column containing it can be
+// decoded without it into shares matching the expected Merkle root. If that is
+// not possible, the ErrByzantineData returned by Repair is returned.
+//
+// The quarantined shares are returned in the order in which they were found,
+// including when an error is returned.
+func (eds *ExtendedDataSquare) RepairWithQuarantine(
+	rowRoots [][]byte,
+	colRoots [][]byte,
+) ([]CellIndex, error) {
+	var suspects []CellIndex
+	for {
+		err := eds.Repair(rowRoots, colRoots)
+		var byzErr *ErrByzantineData
+		if !errors.As(err, &byzErr) {
+			return suspects, err
+		}
+
+		var roots [][]byte
+		if byzErr.Axis == Row {
+			roots = rowRoots
+		} else {
+			roots = colRoots
+		}
+		found := eds.findSuspects(byzErr.Axis, byzErr.Index, roots[byzErr.Index])
+		if len(found) == 0 {
+			return suspects, err
+		}
+
+		for _, cell := range found {
+			eds.clearCell(cell.Row, cell.Col)
+		}
+		suspects = append(suspects, found...)
+	}
+}
+
+// findSuspects tries to decode the axis at axisIdx with each of its present
+// shares left out in turn. Once a decoding matches root, the present shares
+// which differ from the decoded shares are returned. Returns nil if no
+// decoding matches root.
+func (eds *ExtendedDataSquare) findSuspects(axis Axis, axisIdx uint, root []byte) []CellIndex {
+	var shares [][]byte
+	if axis == Row {
+		shares = eds.row(axisIdx)
+	} else {
+		shares = eds.col(axisIdx)
+	}
+
+	for skipIdx := range shares {
+		if shares[skipIdx] == nil {
+			continue
+		}
+
+		candidate := make([][]byte, len(shares))
+		copy(candidate, shares)
+		candidate[skipIdx] = nil
+
+		rebuiltShares, isDecoded, err := rebuildShares(eds.codec, candidate)
+		if err != nil || !isDecoded {
+			continue
+		}
+		rebuiltRoot, err := eds.computeSharesRoot(rebuiltShares, axis, axisIdx)
+		if err != nil || !bytes.Equal(rebuiltRoot, root) {
+			continue
+		}
+
+		var suspects []CellIndex
+		for i, share := range shares {
+			if share == nil || bytes.Equal(share, rebuiltShares[i]) {
+				continue
+			}
+			if axis == Row {
+				suspects = append(suspects, CellIndex{Row: axisIdx, Col: uint(i)})
+			} else {
+				suspects = append(suspects, CellIndex{Row: uint(i), Col: axisIdx})
+			}
+		}
+		return suspects
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairWithQuarantine(t *testing.T) {
+	codec := NewLeoRSCodec()
+	original, err := ComputeExtendedDataSquare(genRandDS(4, shareSize), codec, NewDefaultTree)
+	require.NoError(t, err)
+	rowRoots, err := original.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := original.ColRoots()
+	require.NoError(t, err)
+
+	corrupt := func(t *testing.T) *ExtendedDataSquare {
+		shares := original.Flattened()
+		// remove some shares so that a repair is required
+		shares[0], shares[9], shares[18] = nil, nil, nil
+		// corrupt a single share
+		shares[5] = bytes.Repeat([]byte{42}, shareSize)
+		eds, err := ImportExtendedDataSquare(shares, codec, NewDefaultTree)
+		require.NoError(t, err)
+		return eds
+	}
+
+	t.Run("Repair fails on the corrupted share", func(t *testing.T) {
+		eds := corrupt(t)
+		err := eds.Repair(rowRoots, colRoots)
+		var byzErr *ErrByzantineData
+		assert.ErrorAs(t, err, &byzErr)
+	})
+
+	t.Run("RepairWithQuarantine quarantines the corrupted share", func(t *testing.T) {
+		eds := corrupt(t)
+		suspects, err := eds.RepairWithQuarantine(rowRoots, colRoots)
+		require.NoError(t, err)
+		assert.Equal(t, []CellIndex{{Row: 0, Col: 5}}, suspects)
+		assert.True(t, eds.Equals(original))
+	})
+
+	t.Run("returns ErrByzantineData for incorrectly encoded data", func(t *testing.T) {
+		shares := original.Flattened()
+		// corrupt a share and commit to it so no decoding matches the roots
+		shares[5] = bytes.Repeat([]byte{42}, shareSize)
+		byzantine, err := ImportExtendedDataSquare(shares, codec, NewDefaultTree)
+		require.NoError(t, err)
+		byzRowRoots, err := byzantine.RowRoots()
+		require.NoError(t, err)
+		byzColRoots, err := byzantine.ColRoots()
+		require.NoError(t, err)
+
+		suspects, err := byzantine.RepairWithQuarantine(byzRowRoots, byzColRoots)
+		var byzErr *ErrByzantineData
+		assert.ErrorAs(t, err, &byzErr)
+		assert.Empty(t, suspects)
+	})
+}
@@ -0,0 +1,90 @@
+package rsmt2d
+
+import "sync"
+
+// ReadOnlyEDS is a read-only view of an extended data square that can be
+// shared by many goroutines. Unlike the getters of ExtendedDataSquare, the
+// getters of the view do not copy the shares or roots they return, which
+// therefore must not be modified. The square must not be modified while the
+// view is in use.
+type ReadOnlyEDS struct {
+	eds *ExtendedDataSquare
+
+	rootsOnce sync.Once
+	rowRoots  [][]byte
+	colRoots  [][]byte
+	rootsErr  error
+}
+
+// ReadOnly returns a read-only view of the extended data square.
+func (eds *ExtendedDataSquare) ReadOnly() *ReadOnlyEDS {
+	return &ReadOnlyEDS{eds: eds}
+}
+
+// Width returns the width of the square.
+func (v *ReadOnlyEDS) Width() uint {
+	return v.eds.Width()
+}
+
+// OdsWidth returns the width of the original data square.
+func (v *ReadOnlyEDS) OdsWidth() uint {
+	return v.eds.OdsWidth()
+}
+
+// GetCell returns the share at (rowIdx, colIdx), or nil if it is missing.
+func (v *ReadOnlyEDS) GetCell(rowIdx uint, colIdx uint) []byte {
+	return v.eds.rowSlice(rowIdx, colIdx, 1)[0]
+}
+
+// Row returns the shares of row rowIdx.
+func (v *ReadOnlyEDS) Row(rowIdx uint) [][]byte {
+	return v.eds.row(rowIdx)
+}
+
+// Col returns the shares of column colIdx.
+func (v *ReadOnlyEDS) Col(colIdx uint) [][]byte {
+	return v.eds.col(colIdx)
+}
+
+// Flattened returns the shares of the square in row-major order. Only the
+// returned slice is allocated, the shares are not copied.
+func (v *ReadOnlyEDS) Flattened() [][]byte {
+	return v.eds.dataSquare.Flattened()
+}
+
+// RowRoots returns the Merkle roots of all the rows in the square. Returns an
+// error if the square is incomplete (i.e. some shares are nil).
+func (v *ReadOnlyEDS) RowRoots() ([][]byte, error) {
+	v.computeRoots()
+	return v.rowRoots, v.rootsErr
+}
+
+// ColRoots returns the Merkle roots of all the columns in the square. Returns
+// an error if the square is incomplete (i.e. some shares are nil).
+func (v *ReadOnlyEDS) ColRoots() ([][]byte, error) {
+	v.computeRoots()
+	return v.colRoots, v.rootsErr
+}
+
+// computeRoots computes the roots of the square once, so that concurrent
+// callers do not race on the cache of the square.
+func (v *ReadOnlyEDS) computeRoots() {
+	v.rootsOnce.Do(func() {
+		if v.rootsErr = v.eds.computeRoots(); v.rootsErr != nil {
+			return
+		}
+		v.rowRoots, v.colRoots = v.eds.rowRoots, v.eds.colRoots
+	})
+}
+
+// ProveShare returns the inclusion proof of the share at (rowIdx, colIdx)
+// against the root of row rowIdx, see ExtendedDataSquare.ProveShare.
+func (v *ReadOnlyEDS) ProveShare(rowIdx, colIdx uint) (ShareProof, error) {
+	return v.eds.ProveShare(rowIdx, colIdx)
+}
+
+// ProveColShare returns the inclusion proof of the share at (rowIdx, colIdx)
+// against the root of column colIdx, see ExtendedDataSquare.ProveColShare.
+func (v *ReadOnlyEDS) ProveColShare(rowIdx, colIdx uint) (ShareProof, error) {
+	return v.eds.ProveColShare(rowIdx, colIdx)
+}
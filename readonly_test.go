@@ -0,0 +1,68 @@
+package rsmt2d
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnly(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(generateRandData(4, shareSize), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	view := eds.ReadOnly()
+
+	assert.Equal(t, eds.Width(), view.Width())
+	assert.Equal(t, eds.OdsWidth(), view.OdsWidth())
+	assert.Equal(t, eds.Flattened(), view.Flattened())
+	for i := uint(0); i < eds.Width(); i++ {
+		assert.Equal(t, eds.Row(i), view.Row(i))
+		assert.Equal(t, eds.Col(i), view.Col(i))
+		assert.Equal(t, eds.GetCell(i, 1), view.GetCell(i, 1))
+	}
+
+	// the view returns the shares of the square instead of copies
+	assert.Same(t, &eds.row(1)[2][0], &view.Row(1)[2][0])
+	assert.Same(t, &eds.row(1)[2][0], &view.GetCell(1, 2)[0])
+
+	wantRowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	wantColRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := uint(0); i < eds.Width(); i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rowRoots, err := view.RowRoots()
+			assert.NoError(t, err)
+			assert.Equal(t, wantRowRoots, rowRoots)
+			colRoots, err := view.ColRoots()
+			assert.NoError(t, err)
+			assert.Equal(t, wantColRoots, colRoots)
+
+			proof, err := view.ProveShare(i, i)
+			assert.NoError(t, err)
+			assert.Equal(t, wantRowRoots[i], proof.Root)
+			proof, err = view.ProveColShare(i, i)
+			assert.NoError(t, err)
+			assert.Equal(t, wantColRoots[i], proof.Root)
+		}()
+	}
+	wg.Wait()
+
+	t.Run("incomplete square", func(t *testing.T) {
+		clone, err := eds.Clone()
+		require.NoError(t, err)
+		clone.clearCell(0, 0)
+		view := clone.ReadOnly()
+		assert.Nil(t, view.GetCell(0, 0))
+		_, err = view.RowRoots()
+		assert.ErrorIs(t, err, ErrIncompleteAxis)
+		_, err = view.ColRoots()
+		assert.ErrorIs(t, err, ErrIncompleteAxis)
+	})
+}
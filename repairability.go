@@ -0,0 +1,100 @@
+package rsmt2d
+
+import "fmt"
+
+// RepairabilityReport describes how the crossword solver used by Repair would
+// behave for a given pattern of present and missing shares.
+type RepairabilityReport struct {
+	// Repairable is true if Repair would be able to complete the square,
+	// assuming no Byzantine data.
+	Repairable bool
+	// Iterations is the number of passes over all rows and columns the solver
+	// performs before the square is complete or no further progress can be
+	// made.
+	Iterations int
+	// BottleneckRows and BottleneckCols contain the indices of the rows and
+	// columns that limit the repair. If the square is repairable, they are
+	// the axes repaired during the final iteration. Otherwise, they are the
+	// axes that remain incomplete because they have too few shares present.
+	BottleneckRows []uint
+	BottleneckCols []uint
+}
+
+// AnalyzeRepairability reports whether an extended data square with the given
+// pattern of present shares could be repaired, without requiring the share
+// data. present[rowIdx][colIdx] must be true if the share at (rowIdx, colIdx)
+// is present. This allows cheap analysis of erasure patterns, e.g. to
+// pre-validate a set of samples.
+func AnalyzeRepairability(present [][]bool) (RepairabilityReport, error) {
+	width := uint(len(present))
+	if err := validateEdsWidth(width); err != nil {
+		return RepairabilityReport{}, err
+	}
+	bm := newBitMatrix(width)
+	for rowIdx, row := range present {
+		if uint(len(row)) != width {
+			return RepairabilityReport{}, fmt.Errorf("row %d has length %d, expected %d", rowIdx, len(row), width)
+		}
+		for colIdx, isPresent := range row {
+			if isPresent {
+				bm.set(uint(rowIdx), uint(colIdx))
+			}
+		}
+	}
+	return analyzeRepairability(bm), nil
+}
+
+// analyzeRepairability simulates solveCrossword on the presence matrix bm.
+// bm is modified in place.
+func analyzeRepairability(bm bitMatrix) RepairabilityReport {
+	width := bm.width
+	// an axis can be decoded if at least half of its shares are present
+	threshold := width / 2
+
+	var report RepairabilityReport
+	for {
+		report.Iterations++
+		solved := true
+		progressMade := false
+		var solvedRows, solvedCols []uint
+
+		for i := uint(0); i < width; i++ {
+			if count := bm.rowCount(i); count < width {
+				if count >= threshold {
+					bm.setRow(i)
+					solvedRows = append(solvedRows, i)
+					progressMade = true
+				} else {
+					solved = false
+				}
+			}
+			if count := bm.colCount(i); count < width {
+				if count >= threshold {
+					bm.setCol(i)
+					solvedCols = append(solvedCols, i)
+					progressMade = true
+				} else {
+					solved = false
+				}
+			}
+		}
+
+		if solved {
+			report.Repairable = true
+			report.BottleneckRows = solvedRows
+			report.BottleneckCols = solvedCols
+			return report
+		}
+		if !progressMade {
+			for i := uint(0); i < width; i++ {
+				if bm.rowCount(i) < width {
+					report.BottleneckRows = append(report.BottleneckRows, i)
+				}
+				if bm.colCount(i) < width {
+					report.BottleneckCols = append(report.BottleneckCols, i)
+				}
+			}
+			return report
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package rsmt2d
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// presenceFromFlattened returns a presence pattern for a flattened square of
+// the given width in which the cells at missing are absent.
+func presenceFromFlattened(width int, missing ...int) [][]bool {
+	present := make([][]bool, width)
+	for i := range present {
+		present[i] = make([]bool, width)
+		for j := range present[i] {
+			present[i][j] = true
+		}
+	}
+	for _, idx := range missing {
+		present[idx/width][idx%width] = false
+	}
+	return present
+}
+
+func TestAnalyzeRepairability(t *testing.T) {
+	t.Run("complete square", func(t *testing.T) {
+		report, err := AnalyzeRepairability(presenceFromFlattened(4))
+		require.NoError(t, err)
+		assert.True(t, report.Repairable)
+		assert.Equal(t, 1, report.Iterations)
+		assert.Empty(t, report.BottleneckRows)
+		assert.Empty(t, report.BottleneckCols)
+	})
+
+	t.Run("repairable square", func(t *testing.T) {
+		// same pattern as TestEdsRepairRoundtripSimple
+		present := presenceFromFlattened(4, 0, 2, 3, 4, 5, 6, 7, 8, 9, 10, 12, 13)
+		report, err := AnalyzeRepairability(present)
+		require.NoError(t, err)
+		assert.True(t, report.Repairable)
+		assert.Equal(t, 2, report.Iterations)
+	})
+
+	t.Run("unrepairable square", func(t *testing.T) {
+		// same pattern as TestEdsRepairTwice
+		present := presenceFromFlattened(4, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 12, 13)
+		report, err := AnalyzeRepairability(present)
+		require.NoError(t, err)
+		assert.False(t, report.Repairable)
+		assert.Equal(t, 2, report.Iterations)
+		assert.Equal(t, []uint{0, 1, 2}, report.BottleneckRows)
+		assert.Equal(t, []uint{0, 1, 2}, report.BottleneckCols)
+	})
+
+	t.Run("matches Repair", func(t *testing.T) {
+		eds := createExampleEds(t, shareSize)
+		rowRoots, err := eds.RowRoots()
+		require.NoError(t, err)
+		colRoots, err := eds.ColRoots()
+		require.NoError(t, err)
+
+		missing := []int{0, 2, 3, 4, 5, 6, 7, 8, 9, 10, 12, 13}
+		shares := eds.Flattened()
+		for _, idx := range missing {
+			shares[idx] = nil
+		}
+		report, err := AnalyzeRepairability(presenceFromFlattened(4, missing...))
+		require.NoError(t, err)
+		require.True(t, report.Repairable)
+
+		imported, err := ImportExtendedDataSquare(shares, NewLeoRSCodec(), NewDefaultTree)
+		require.NoError(t, err)
+		assert.NoError(t, imported.Repair(rowRoots, colRoots))
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := AnalyzeRepairability(presenceFromFlattened(3))
+		assert.Error(t, err)
+
+		present := presenceFromFlattened(4)
+		present[1] = present[1][:3]
+		_, err = AnalyzeRepairability(present)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,150 @@
+package rsmt2d
+
+import (
+	"fmt"
+
+	"github.com/klauspost/reedsolomon"
+)
+
+// RSGF8 is a Reed-Solomon codec over GF(2^8) using a Vandermonde-derived
+// encoding matrix, as implemented by
+// https://github.com/klauspost/reedsolomon. Unlike Leopard, it supports
+// shares of any non-zero size, at the cost of slower encoding and a maximum
+// extended data square width of 256.
+const RSGF8 = "RSGF8"
+
+var (
+	_ Verifier     = &RSGF8Codec{}
+	_ BatchEncoder = &RSGF8Codec{}
+)
+
+func init() {
+	registerCodec(RSGF8, NewRSGF8Codec())
+}
+
+type RSGF8Codec struct {
+	// Cache the encoders of various sizes to not have to re-instantiate those
+	// as it is costly.
+	encCache encoderCache
+}
+
+func (c *RSGF8Codec) Encode(data [][]byte) ([][]byte, error) {
+	parity := allocShares(len(data), len(data[0]))
+	if err := c.EncodeInto(data, parity); err != nil {
+		return nil, err
+	}
+	return parity, nil
+}
+
+func (c *RSGF8Codec) EncodeInto(data [][]byte, parityOut [][]byte) error {
+	dataLen := len(data)
+	if len(parityOut) != dataLen {
+		return fmt.Errorf("%w: parityOut has %d shares but data has %d shares", ErrShareCountMismatch, len(parityOut), dataLen)
+	}
+	enc, err := c.loadOrInitEncoder(dataLen)
+	if err != nil {
+		return err
+	}
+
+	shares := make([][]byte, dataLen*2)
+	copy(shares, data)
+	copy(shares[dataLen:], parityOut)
+
+	return enc.Encode(shares)
+}
+
+// EncodeBatch encodes every axis in axes with a single encoder lookup and
+// returns their parity shares in a single allocation.
+func (c *RSGF8Codec) EncodeBatch(axes [][][]byte) ([][][]byte, error) {
+	parity := allocBatchParity(axes)
+	if err := c.EncodeBatchInto(axes, parity); err != nil {
+		return nil, err
+	}
+	return parity, nil
+}
+
+// EncodeBatchInto encodes every axis in axes into parityOut with a single
+// encoder lookup, reusing the same work slice for every axis.
+func (c *RSGF8Codec) EncodeBatchInto(axes [][][]byte, parityOut [][][]byte) error {
+	if len(axes) == 0 {
+		return nil
+	}
+	enc, err := c.loadOrInitEncoder(len(axes[0]))
+	if err != nil {
+		return err
+	}
+	return encodeBatchWith(enc, axes, parityOut)
+}
+
+// Verify returns true if parity are the parity shares of original.
+func (c *RSGF8Codec) Verify(original, parity [][]byte) (bool, error) {
+	dataLen := len(original)
+	if len(parity) != dataLen {
+		return false, fmt.Errorf("%w: got %d parity shares for %d original shares", ErrShareCountMismatch, len(parity), dataLen)
+	}
+	enc, err := c.loadOrInitEncoder(dataLen)
+	if err != nil {
+		return false, err
+	}
+
+	shares := make([][]byte, dataLen*2)
+	copy(shares, original)
+	copy(shares[dataLen:], parity)
+
+	return enc.Verify(shares)
+}
+
+func (c *RSGF8Codec) Decode(data [][]byte) ([][]byte, error) {
+	half := len(data) / 2
+	enc, err := c.loadOrInitEncoder(half)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCodecInternal, err)
+	}
+	err = enc.Reconstruct(data)
+	return data, wrapDecodeError(err)
+}
+
+func (c *RSGF8Codec) loadOrInitEncoder(dataLen int) (reedsolomon.Encoder, error) {
+	return c.encCache.loadOrInit(dataLen, func(dataLen int) (reedsolomon.Encoder, error) {
+		return reedsolomon.New(dataLen, dataLen)
+	})
+}
+
+// MaxChunks returns the max number of shares this codec supports in a 2D
+// original data square.
+func (c *RSGF8Codec) MaxChunks() int {
+	maxODSWidth := c.MaxAxisShares()
+	return maxODSWidth * maxODSWidth
+}
+
+// MaxAxisShares returns the max number of original shares per row or column
+// this codec supports.
+func (c *RSGF8Codec) MaxAxisShares() int {
+	// GF(2^8) supports at most 256 shares per row or column of the EDS, which
+	// is an ODS width of 128.
+	return 256 / 2
+}
+
+func (c *RSGF8Codec) Name() string {
+	return RSGF8
+}
+
+// SupportedChunkSizes returns the min and max supported share size and the
+// number of bytes supported share sizes must be a multiple of. A max of zero
+// means there is no maximum.
+func (c *RSGF8Codec) SupportedChunkSizes() (minSize, maxSize, multiple int) {
+	return 1, 0, 1
+}
+
+// ValidateChunkSize returns an error if this codec does not support
+// shareSize. Returns nil if shareSize is supported.
+func (c *RSGF8Codec) ValidateChunkSize(shareSize int) error {
+	if shareSize <= 0 {
+		return fmt.Errorf("%w: shareSize %v must be positive", ErrInvalidShareSize, shareSize)
+	}
+	return nil
+}
+
+func NewRSGF8Codec() *RSGF8Codec {
+	return &RSGF8Codec{}
+}
@@ -0,0 +1,87 @@
+package rsmt2d
+
+import "fmt"
+
+// Sample is a share of an extended data square together with the proof of
+// its inclusion in its row, as downloaded by data availability sampling
+// light clients.
+type Sample struct {
+	// Row and Col are the coordinates of the share in the square.
+	Row, Col uint
+	// Proof is the inclusion proof of the share against the root of row
+	// Row. Proof.Share is the sampled share.
+	Proof ShareProof
+}
+
+// Share returns the sampled share.
+func (s Sample) Share() []byte {
+	return s.Proof.Share
+}
+
+// Sample returns the share at (rowIdx, colIdx) along with its inclusion proof
+// against the root of its row. The underlying Tree must implement
+// ProvingTree. Returns an error if the row is incomplete (i.e. some shares
+// are nil).
+func (eds *ExtendedDataSquare) Sample(rowIdx, colIdx uint) (Sample, error) {
+	proof, err := eds.ProveShare(rowIdx, colIdx)
+	if err != nil {
+		return Sample{}, err
+	}
+	return Sample{Row: rowIdx, Col: colIdx, Proof: proof}, nil
+}
+
+// VerifySample returns nil if s proves the inclusion of its share at its
+// coordinates under root, the root of row s.Row. The proof is verified with
+// a tree created by treeCreatorFn, see VerifyShareProof. Otherwise, an error
+// wrapping ErrInvalidShareProof is returned.
+func VerifySample(root []byte, s Sample, treeCreatorFn TreeConstructorFn) error {
+	if s.Proof.Axis != Row || s.Proof.Index != s.Col {
+		return fmt.Errorf("%w: the proof is for index %d of a %s, not for column %d of a row", ErrInvalidShareProof, s.Proof.Index, s.Proof.Axis, s.Col)
+	}
+	return VerifyShareProof(s.Proof, root, s.Row, treeCreatorFn)
+}
+
+// ReconstructFromSamples verifies the proof of every sample against the root
+// of its row, inserts the shares of the samples into an empty square and
+// repairs it, see Repair. The samples must cover enough cells to repair the
+// square. Returns an error wrapping ErrInvalidShareProof if a sample does not
+// verify, so that no unverified share ends up in the square.
+func ReconstructFromSamples(
+	samples []Sample,
+	rowRoots [][]byte,
+	colRoots [][]byte,
+	codec Codec,
+	treeCreatorFn TreeConstructorFn,
+) (*ExtendedDataSquare, error) {
+	width := uint(len(rowRoots))
+	if uint(len(colRoots)) != width {
+		return nil, fmt.Errorf("got %d column roots for %d row roots", len(colRoots), width)
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("%w: no samples", ErrUnrepairableDataSquare)
+	}
+	eds, err := NewExtendedDataSquare(codec, treeCreatorFn, width, uint(len(samples[0].Share())))
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range samples {
+		if s.Row >= width || s.Col >= width {
+			return nil, fmt.Errorf("%w: sample (%d, %d) for width %d", ErrOutOfBounds, s.Row, s.Col, width)
+		}
+		if err := VerifySample(rowRoots[s.Row], s, treeCreatorFn); err != nil {
+			return nil, fmt.Errorf("sample (%d, %d): %w", s.Row, s.Col, err)
+		}
+		if share := eds.GetCell(s.Row, s.Col); share != nil {
+			// the share of a duplicate sample is verified against the same
+			// root, so it is the same share
+			continue
+		}
+		if err := eds.SetCell(s.Row, s.Col, s.Share()); err != nil {
+			return nil, err
+		}
+	}
+	if err := eds.Repair(rowRoots, colRoots); err != nil {
+		return nil, err
+	}
+	return eds, nil
+}
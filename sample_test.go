@@ -0,0 +1,94 @@
+package rsmt2d
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSample(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+
+	for rowIdx := uint(0); rowIdx < eds.Width(); rowIdx++ {
+		for colIdx := uint(0); colIdx < eds.Width(); colIdx++ {
+			s, err := eds.Sample(rowIdx, colIdx)
+			require.NoError(t, err)
+			assert.Equal(t, eds.GetCell(rowIdx, colIdx), s.Share())
+			assert.NoError(t, VerifySample(rowRoots[rowIdx], s, NewDefaultTree))
+		}
+	}
+
+	s, err := eds.Sample(2, 5)
+	require.NoError(t, err)
+	wrongCol := s
+	wrongCol.Col = 4
+	wrongRow := s
+	wrongRow.Row = 3
+	wrongShare := s
+	wrongShare.Proof.Share = eds.GetCell(2, 4)
+	colProof := s
+	colProof.Proof, err = eds.ProveColShare(2, 5)
+	require.NoError(t, err)
+	for name, s := range map[string]Sample{
+		"wrong column": wrongCol,
+		"wrong row":    wrongRow,
+		"wrong share":  wrongShare,
+		"column proof": colProof,
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, VerifySample(rowRoots[s.Row], s, NewDefaultTree), ErrInvalidShareProof)
+		})
+	}
+
+	_, err = eds.Sample(eds.Width(), 0)
+	assert.ErrorIs(t, err, ErrOutOfBounds)
+}
+
+func TestReconstructFromSamples(t *testing.T) {
+	want, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	rowRoots, err := want.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := want.ColRoots()
+	require.NoError(t, err)
+
+	// the left half of the square, with a duplicate
+	var samples []Sample
+	for rowIdx := uint(0); rowIdx < want.Width(); rowIdx++ {
+		for colIdx := uint(0); colIdx < want.OdsWidth(); colIdx++ {
+			s, err := want.Sample(rowIdx, colIdx)
+			require.NoError(t, err)
+			samples = append(samples, s)
+		}
+	}
+	samples = append(samples, samples[3])
+
+	eds, err := ReconstructFromSamples(samples, rowRoots, colRoots, NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	assert.True(t, want.Equals(eds))
+
+	t.Run("invalid sample", func(t *testing.T) {
+		invalid := append([]Sample(nil), samples...)
+		invalid[5].Proof.Share = want.GetCell(0, 0)
+		_, err := ReconstructFromSamples(invalid, rowRoots, colRoots, NewLeoRSCodec(), NewDefaultTree)
+		assert.ErrorIs(t, err, ErrInvalidShareProof)
+	})
+
+	t.Run("too few samples", func(t *testing.T) {
+		_, err := ReconstructFromSamples(samples[:5], rowRoots, colRoots, NewLeoRSCodec(), NewDefaultTree)
+		assert.ErrorIs(t, err, ErrUnrepairableDataSquare)
+		_, err = ReconstructFromSamples(nil, rowRoots, colRoots, NewLeoRSCodec(), NewDefaultTree)
+		assert.ErrorIs(t, err, ErrUnrepairableDataSquare)
+	})
+
+	t.Run("out of bounds", func(t *testing.T) {
+		invalid := append([]Sample(nil), samples...)
+		invalid[0].Row = want.Width()
+		_, err := ReconstructFromSamples(invalid, rowRoots, colRoots, NewLeoRSCodec(), NewDefaultTree)
+		assert.ErrorIs(t, err, ErrOutOfBounds)
+	})
+}
@@ -0,0 +1,72 @@
+package rsmt2d
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+// AvailabilityConfidence returns the probability that at least one of samples
+// distinct shares, chosen uniformly at random from an extended data square of
+// the given width, is missing if the square cannot be reconstructed. To
+// prevent reconstruction of a square of original width k, at least (k+1)^2 of
+// its shares must be withheld, see "Fraud and Data Availability Proofs"
+// (Al-Bassam et al., 2018), so that the probability that all samples are
+// available is at most the product over i < samples of
+// 1 - (k+1)^2/(width^2-i). Returns 0 if width is not a positive even number.
+func AvailabilityConfidence(samples, width uint) float64 {
+	if width == 0 || width%2 != 0 {
+		return 0
+	}
+	total := float64(width) * float64(width)
+	withheld := float64(width/2+1) * float64(width/2+1)
+	if float64(samples) > total-withheld {
+		// every set of samples includes a withheld share
+		return 1
+	}
+	allAvailable := 1.0
+	for i := uint(0); i < samples; i++ {
+		allAvailable *= 1 - withheld/(total-float64(i))
+	}
+	return 1 - allAvailable
+}
+
+// SamplingPlan returns the fewest distinct cells, chosen uniformly at random
+// from an extended data square of the given width with crypto/rand, that
+// detect an unrecoverable square with at least the given confidence, see
+// AvailabilityConfidence. Returns an error if width is not a positive even
+// number or confidence is not in [0, 1].
+func SamplingPlan(width uint, confidence float64) ([]CellIndex, error) {
+	if err := validateEdsWidth(width); err != nil {
+		return nil, err
+	}
+	if width == 0 {
+		return nil, fmt.Errorf("%w: width must be positive", ErrUnsupportedWidth)
+	}
+	if !(confidence >= 0 && confidence <= 1) {
+		return nil, fmt.Errorf("confidence %v is not in [0, 1]", confidence)
+	}
+
+	samples := uint(0)
+	for AvailabilityConfidence(samples, width) < confidence {
+		samples++
+	}
+
+	// rejection sampling, as samples is at most 3/4 of the cells
+	max := new(big.Int).SetUint64(uint64(width) * uint64(width))
+	chosen := make(map[uint]bool, samples)
+	plan := make([]CellIndex, 0, samples)
+	for uint(len(plan)) < samples {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return nil, err
+		}
+		i := uint(n.Uint64())
+		if chosen[i] {
+			continue
+		}
+		chosen[i] = true
+		plan = append(plan, CellIndex{Row: i / width, Col: i % width})
+	}
+	return plan, nil
+}
@@ -0,0 +1,58 @@
+package rsmt2d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAvailabilityConfidence(t *testing.T) {
+	// 9 of the 16 shares of a square of width 4 must be withheld
+	assert.Equal(t, 0.0, AvailabilityConfidence(0, 4))
+	assert.InDelta(t, 9.0/16, AvailabilityConfidence(1, 4), 1e-12)
+	assert.InDelta(t, 1-7.0/16*6.0/15, AvailabilityConfidence(2, 4), 1e-12)
+	assert.Equal(t, 1.0, AvailabilityConfidence(8, 4))
+	assert.Equal(t, 0.0, AvailabilityConfidence(1, 3))
+
+	// about a quarter of the shares of a large square must be withheld
+	assert.InDelta(t, 1-math.Pow(0.75, 16), AvailabilityConfidence(16, 512), 0.01)
+
+	for samples := uint(1); samples < 32; samples++ {
+		assert.Greater(t, AvailabilityConfidence(samples, 256), AvailabilityConfidence(samples-1, 256))
+	}
+}
+
+func TestSamplingPlan(t *testing.T) {
+	const width = 256
+	plan, err := SamplingPlan(width, 0.99)
+	require.NoError(t, err)
+	samples := uint(len(plan))
+	assert.GreaterOrEqual(t, AvailabilityConfidence(samples, width), 0.99)
+	assert.Less(t, AvailabilityConfidence(samples-1, width), 0.99)
+
+	seen := make(map[CellIndex]bool)
+	for _, c := range plan {
+		assert.Less(t, c.Row, uint(width))
+		assert.Less(t, c.Col, uint(width))
+		assert.False(t, seen[c], "cell %v is sampled twice", c)
+		seen[c] = true
+	}
+
+	plan, err = SamplingPlan(4, 1)
+	require.NoError(t, err)
+	assert.Len(t, plan, 8)
+	plan, err = SamplingPlan(4, 0)
+	require.NoError(t, err)
+	assert.Empty(t, plan)
+
+	for _, confidence := range []float64{-0.1, 1.1, math.NaN()} {
+		_, err = SamplingPlan(width, confidence)
+		assert.Error(t, err)
+	}
+	_, err = SamplingPlan(3, 0.5)
+	assert.ErrorIs(t, err, ErrUnsupportedWidth)
+	_, err = SamplingPlan(0, 0.5)
+	assert.ErrorIs(t, err, ErrUnsupportedWidth)
+}
@@ -0,0 +1,33 @@
+package rsmt2d
+
+// Share is a share of a square. In namespaced squares, such as the ones
+// committed to by a namespaced Merkle tree, every share of the original data
+// is prefixed with its namespace.
+type Share []byte
+
+// Namespace returns the namespace prefix of the share, given the size of
+// namespaces. Returns nil if the share is shorter than namespaceSize. The
+// returned slice aliases the share.
+func (s Share) Namespace(namespaceSize int) []byte {
+	if namespaceSize < 0 || len(s) < namespaceSize {
+		return nil
+	}
+	return s[:namespaceSize:namespaceSize]
+}
+
+// Data returns the share without its namespace prefix, given the size of
+// namespaces. Returns nil if the share is shorter than namespaceSize. The
+// returned slice aliases the share.
+func (s Share) Data(namespaceSize int) []byte {
+	if namespaceSize < 0 || len(s) < namespaceSize {
+		return nil
+	}
+	return s[namespaceSize:]
+}
+
+// ShareAt returns a copy of the share at (rowIdx, colIdx), or nil if it is
+// missing. Note that only the shares of the original data square are
+// prefixed with a namespace.
+func (eds *ExtendedDataSquare) ShareAt(rowIdx uint, colIdx uint) Share {
+	return eds.GetCell(rowIdx, colIdx)
+}
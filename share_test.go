@@ -0,0 +1,42 @@
+package rsmt2d
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShare(t *testing.T) {
+	share := Share{1, 2, 3, 4}
+	assert.Equal(t, []byte{1, 2}, share.Namespace(2))
+	assert.Equal(t, []byte{3, 4}, share.Data(2))
+	assert.Equal(t, []byte{}, share.Namespace(0))
+	assert.Equal(t, []byte{1, 2, 3, 4}, share.Data(0))
+	assert.Equal(t, []byte{1, 2, 3, 4}, share.Namespace(4))
+	assert.Empty(t, share.Data(4))
+	assert.Nil(t, share.Namespace(5))
+	assert.Nil(t, share.Data(5))
+
+	// appending to the namespace does not overwrite the data
+	ns := append(share.Namespace(2), 0)
+	assert.Equal(t, []byte{1, 2, 0}, ns)
+	assert.Equal(t, Share{1, 2, 3, 4}, share)
+}
+
+func TestShareAt(t *testing.T) {
+	const namespaceSize = 8
+	eds, err := ComputeExtendedDataSquare(genRandSortedDS(2, shareSize, namespaceSize), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+
+	share := eds.ShareAt(1, 0)
+	assert.Equal(t, Share(eds.GetCell(1, 0)), share)
+	assert.Equal(t, eds.GetCell(1, 0)[:namespaceSize], share.Namespace(namespaceSize))
+
+	// the share is a copy
+	share[0]++
+	assert.NotEqual(t, Share(eds.GetCell(1, 0)), share)
+
+	eds.clearCell(0, 0)
+	assert.Nil(t, eds.ShareAt(0, 0))
+}
@@ -0,0 +1,306 @@
+// Package simulate simulates the repair of extended data squares by light
+// nodes that fetch shares one at a time from a possibly misbehaving block
+// producer, to tune sampling parameters and test repair against corrupted or
+// withheld data.
+//
+// Every trial of a simulation encodes a random original data square, lets a
+// CorruptionModel decide which square the producer commits to and which of
+// its shares are withheld, and then fetches the shares in the order of a
+// SamplingStrategy into an empty square, repairing it whenever enough shares
+// have been fetched, until the square is repaired, its repair fails with
+// rsmt2d.ErrByzantineData, or no share is left to fetch.
+package simulate
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/celestiaorg/rsmt2d"
+)
+
+// Scenario is the behavior of a block producer in a trial.
+type Scenario struct {
+	// Square is the complete square the producer commits to, i.e. whose
+	// roots are published. It is the original square unless the producer
+	// corrupted it.
+	Square *rsmt2d.ExtendedDataSquare
+	// Withheld are the cells of Square that the producer does not serve.
+	Withheld map[rsmt2d.CellIndex]bool
+}
+
+// CorruptionModel returns the Scenario of a trial for the honestly encoded
+// square original, drawing any randomness from rnd.
+type CorruptionModel func(original *rsmt2d.ExtendedDataSquare, rnd *rand.Rand) (Scenario, error)
+
+// Honest serves the original square in full.
+func Honest() CorruptionModel {
+	return func(original *rsmt2d.ExtendedDataSquare, _ *rand.Rand) (Scenario, error) {
+		return Scenario{Square: original}, nil
+	}
+}
+
+// WithholdRandom withholds n distinct cells chosen uniformly at random.
+func WithholdRandom(n int) CorruptionModel {
+	return func(original *rsmt2d.ExtendedDataSquare, rnd *rand.Rand) (Scenario, error) {
+		width := int(original.Width())
+		if n > width*width {
+			return Scenario{}, fmt.Errorf("cannot withhold %d of %d cells", n, width*width)
+		}
+		withheld := make(map[rsmt2d.CellIndex]bool, n)
+		for _, i := range rnd.Perm(width * width)[:n] {
+			withheld[cellOf(i, width)] = true
+		}
+		return Scenario{Square: original, Withheld: withheld}, nil
+	}
+}
+
+// WithholdMinimal withholds the top left (k+1)x(k+1) cells of a square of
+// original width k, the smallest pattern that makes a square unrecoverable.
+func WithholdMinimal() CorruptionModel {
+	return func(original *rsmt2d.ExtendedDataSquare, _ *rand.Rand) (Scenario, error) {
+		k := original.OdsWidth()
+		withheld := make(map[rsmt2d.CellIndex]bool, (k+1)*(k+1))
+		for rowIdx := uint(0); rowIdx <= k; rowIdx++ {
+			for colIdx := uint(0); colIdx <= k; colIdx++ {
+				withheld[rsmt2d.CellIndex{Row: rowIdx, Col: colIdx}] = true
+			}
+		}
+		return Scenario{Square: original, Withheld: withheld}, nil
+	}
+}
+
+// CorruptRandomShare replaces a random share of the square with random bytes
+// before the producer commits to it, so that the committed square is not
+// correctly encoded. The first namespaceSize bytes of the share are kept, so
+// that namespaced trees still accept it.
+func CorruptRandomShare(codec rsmt2d.Codec, treeCreatorFn rsmt2d.TreeConstructorFn, namespaceSize int) CorruptionModel {
+	return func(original *rsmt2d.ExtendedDataSquare, rnd *rand.Rand) (Scenario, error) {
+		shares := original.Flattened()
+		i := rnd.Intn(len(shares))
+		corrupted := make([]byte, len(shares[i]))
+		rnd.Read(corrupted)
+		copy(corrupted, shares[i][:namespaceSize])
+		shares[i] = corrupted
+		square, err := rsmt2d.ImportExtendedDataSquare(shares, codec, treeCreatorFn)
+		if err != nil {
+			return Scenario{}, err
+		}
+		return Scenario{Square: square}, nil
+	}
+}
+
+// SamplingStrategy returns the order in which the cells of a square of the
+// given width are fetched, drawing any randomness from rnd. Cells that are
+// not returned are never fetched.
+type SamplingStrategy func(width uint, rnd *rand.Rand) []rsmt2d.CellIndex
+
+// RandomOrder fetches all cells in a uniformly random order.
+func RandomOrder() SamplingStrategy {
+	return func(width uint, rnd *rand.Rand) []rsmt2d.CellIndex {
+		order := make([]rsmt2d.CellIndex, 0, width*width)
+		for _, i := range rnd.Perm(int(width * width)) {
+			order = append(order, cellOf(i, int(width)))
+		}
+		return order
+	}
+}
+
+// RowMajorOrder fetches all cells row by row.
+func RowMajorOrder() SamplingStrategy {
+	return func(width uint, _ *rand.Rand) []rsmt2d.CellIndex {
+		order := make([]rsmt2d.CellIndex, 0, width*width)
+		for i := 0; i < int(width*width); i++ {
+			order = append(order, cellOf(i, int(width)))
+		}
+		return order
+	}
+}
+
+func cellOf(i int, width int) rsmt2d.CellIndex {
+	return rsmt2d.CellIndex{Row: uint(i / width), Col: uint(i % width)}
+}
+
+// Outcome is the result of a trial.
+type Outcome int
+
+const (
+	// Repaired means that the square was repaired.
+	Repaired Outcome = iota
+	// Byzantine means that the repair failed with rsmt2d.ErrByzantineData.
+	Byzantine
+	// Unrepairable means that no share was left to fetch before the square
+	// could be repaired.
+	Unrepairable
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Repaired:
+		return "repaired"
+	case Byzantine:
+		return "byzantine"
+	case Unrepairable:
+		return "unrepairable"
+	default:
+		return fmt.Sprintf("Outcome(%d)", int(o))
+	}
+}
+
+// Config configures a simulation.
+type Config struct {
+	// Codec and TreeCreatorFn are used to encode and repair the squares.
+	Codec         rsmt2d.Codec
+	TreeCreatorFn rsmt2d.TreeConstructorFn
+	// OdsWidth and ShareSize are the dimensions of the original data
+	// squares.
+	OdsWidth  uint
+	ShareSize uint
+	// Trials is the number of squares simulated.
+	Trials int
+	// Corruption is the behavior of the block producer. Defaults to Honest.
+	Corruption CorruptionModel
+	// Strategy is the order in which shares are fetched. Defaults to
+	// RandomOrder.
+	Strategy SamplingStrategy
+	// Seed seeds the randomness of the simulation, so that simulations can
+	// be reproduced.
+	Seed int64
+}
+
+// trialResult is the result of a single trial.
+type trialResult struct {
+	outcome Outcome
+	// fetched is the number of shares fetched until the outcome.
+	fetched int
+	// firstWithheld is the number of cells requested until and including
+	// the first withheld one, or zero if no withheld cell was requested.
+	firstWithheld int
+}
+
+// Stats summarizes the results of a simulation.
+type Stats struct {
+	Trials int
+	// Outcomes counts the trials by outcome.
+	Outcomes map[Outcome]int
+	// MeanFetched and MaxFetched are the mean and maximum number of shares
+	// fetched per trial.
+	MeanFetched float64
+	MaxFetched  int
+	// Detected is the number of trials in which a withheld cell was
+	// requested, and MeanFirstWithheld the mean number of cells requested
+	// until the first withheld one in these trials.
+	Detected          int
+	MeanFirstWithheld float64
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("%d trials: %d repaired, %d byzantine, %d unrepairable; fetched %.1f shares on average (max %d); "+
+		"withholding detected in %d trials after %.1f requests on average",
+		s.Trials, s.Outcomes[Repaired], s.Outcomes[Byzantine], s.Outcomes[Unrepairable],
+		s.MeanFetched, s.MaxFetched, s.Detected, s.MeanFirstWithheld)
+}
+
+// Run runs cfg.Trials trials and summarizes their results.
+func Run(cfg Config) (Stats, error) {
+	if cfg.Corruption == nil {
+		cfg.Corruption = Honest()
+	}
+	if cfg.Strategy == nil {
+		cfg.Strategy = RandomOrder()
+	}
+	rnd := rand.New(rand.NewSource(cfg.Seed))
+
+	stats := Stats{Trials: cfg.Trials, Outcomes: make(map[Outcome]int)}
+	fetched, firstWithheld := 0, 0
+	for i := 0; i < cfg.Trials; i++ {
+		result, err := runTrial(cfg, rnd)
+		if err != nil {
+			return Stats{}, fmt.Errorf("trial %d: %w", i, err)
+		}
+		stats.Outcomes[result.outcome]++
+		fetched += result.fetched
+		if result.fetched > stats.MaxFetched {
+			stats.MaxFetched = result.fetched
+		}
+		if result.firstWithheld != 0 {
+			stats.Detected++
+			firstWithheld += result.firstWithheld
+		}
+	}
+	if cfg.Trials != 0 {
+		stats.MeanFetched = float64(fetched) / float64(cfg.Trials)
+	}
+	if stats.Detected != 0 {
+		stats.MeanFirstWithheld = float64(firstWithheld) / float64(stats.Detected)
+	}
+	return stats, nil
+}
+
+// runTrial simulates a single square.
+func runTrial(cfg Config, rnd *rand.Rand) (trialResult, error) {
+	ods := make([][]byte, cfg.OdsWidth*cfg.OdsWidth)
+	for i := range ods {
+		ods[i] = make([]byte, cfg.ShareSize)
+		rnd.Read(ods[i])
+	}
+	original, err := rsmt2d.ComputeExtendedDataSquare(ods, cfg.Codec, cfg.TreeCreatorFn)
+	if err != nil {
+		return trialResult{}, err
+	}
+	scenario, err := cfg.Corruption(original, rnd)
+	if err != nil {
+		return trialResult{}, err
+	}
+	rowRoots, err := scenario.Square.RowRoots()
+	if err != nil {
+		return trialResult{}, err
+	}
+	colRoots, err := scenario.Square.ColRoots()
+	if err != nil {
+		return trialResult{}, err
+	}
+
+	width := scenario.Square.Width()
+	square, err := rsmt2d.NewExtendedDataSquare(cfg.Codec, cfg.TreeCreatorFn, width, cfg.ShareSize)
+	if err != nil {
+		return trialResult{}, err
+	}
+	var result trialResult
+	for requested, c := range cfg.Strategy(width, rnd) {
+		if scenario.Withheld[c] {
+			if result.firstWithheld == 0 {
+				result.firstWithheld = requested + 1
+			}
+			continue
+		}
+		// skip shares that were already repaired
+		if square.GetCell(c.Row, c.Col) != nil {
+			continue
+		}
+		if err := square.SetCell(c.Row, c.Col, scenario.Square.GetCell(c.Row, c.Col)); err != nil {
+			return trialResult{}, err
+		}
+		result.fetched++
+		// a square cannot be repaired from fewer shares than the original
+		// data
+		if uint(result.fetched) < cfg.OdsWidth*cfg.OdsWidth {
+			continue
+		}
+
+		err := square.Repair(rowRoots, colRoots)
+		var byzErr *rsmt2d.ErrByzantineData
+		switch {
+		case err == nil:
+			result.outcome = Repaired
+			return result, nil
+		case errors.As(err, &byzErr):
+			result.outcome = Byzantine
+			return result, nil
+		case !errors.Is(err, rsmt2d.ErrUnrepairableDataSquare):
+			return trialResult{}, err
+		}
+	}
+	result.outcome = Unrepairable
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package simulate
+
+import (
+	"testing"
+
+	"github.com/celestiaorg/rsmt2d"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	cfg := Config{
+		Codec:         rsmt2d.NewLeoRSCodec(),
+		TreeCreatorFn: rsmt2d.NewDefaultTree,
+		OdsWidth:      4,
+		ShareSize:     64,
+		Trials:        10,
+		Seed:          1,
+	}
+
+	t.Run("honest", func(t *testing.T) {
+		stats, err := Run(cfg)
+		require.NoError(t, err)
+		assert.Equal(t, map[Outcome]int{Repaired: cfg.Trials}, stats.Outcomes)
+		assert.GreaterOrEqual(t, stats.MeanFetched, float64(cfg.OdsWidth*cfg.OdsWidth))
+		assert.LessOrEqual(t, stats.MaxFetched, int(4*cfg.OdsWidth*cfg.OdsWidth))
+		assert.Zero(t, stats.Detected)
+
+		again, err := Run(cfg)
+		require.NoError(t, err)
+		assert.Equal(t, stats, again, "simulations with the same seed must be reproducible")
+	})
+
+	t.Run("row-major order repairs from the original data", func(t *testing.T) {
+		cfg := cfg
+		cfg.Strategy = RowMajorOrder()
+		stats, err := Run(cfg)
+		require.NoError(t, err)
+		assert.Equal(t, map[Outcome]int{Repaired: cfg.Trials}, stats.Outcomes)
+	})
+
+	t.Run("minimal withholding is unrepairable and detected", func(t *testing.T) {
+		cfg := cfg
+		cfg.Corruption = WithholdMinimal()
+		stats, err := Run(cfg)
+		require.NoError(t, err)
+		assert.Equal(t, map[Outcome]int{Unrepairable: cfg.Trials}, stats.Outcomes)
+		assert.Equal(t, cfg.Trials, stats.Detected)
+		// the shares of the rows and columns that could be repaired are not
+		// fetched
+		assert.LessOrEqual(t, stats.MaxFetched, 8*8-5*5)
+	})
+
+	t.Run("withholding fewer shares is repairable", func(t *testing.T) {
+		cfg := cfg
+		cfg.Corruption = WithholdRandom(int(cfg.OdsWidth))
+		stats, err := Run(cfg)
+		require.NoError(t, err)
+		assert.Equal(t, cfg.Trials, stats.Outcomes[Repaired])
+	})
+
+	t.Run("corrupted shares are byzantine", func(t *testing.T) {
+		cfg := cfg
+		cfg.Corruption = CorruptRandomShare(cfg.Codec, cfg.TreeCreatorFn, 0)
+		stats, err := Run(cfg)
+		require.NoError(t, err)
+		assert.Equal(t, cfg.Trials, stats.Outcomes[Byzantine], stats.String())
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		cfg := cfg
+		cfg.Corruption = WithholdRandom(1000)
+		_, err := Run(cfg)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,229 @@
+package rsmt2d
+
+import (
+	"context"
+	"errors"
+)
+
+// CrosswordSquare is the storage of a square that can be repaired by a
+// Solver. It allows the crossword solving logic to be reused with storage
+// backends other than ExtendedDataSquare.
+type CrosswordSquare interface {
+	// Width returns the width of the square.
+	Width() uint
+	// GetAxis returns the shares of the row or column at axisIdx. Missing
+	// shares must be nil. The Solver does not modify the returned slice.
+	GetAxis(axis Axis, axisIdx uint) [][]byte
+	// SetShare sets the missing share at (rowIdx, colIdx).
+	SetShare(rowIdx uint, colIdx uint, share []byte) error
+	// VerifyAxisRoot returns an ErrByzantineData if the complete shares of the
+	// row or column at axisIdx do not match its expected Merkle root. The
+	// Solver populates the Shares of the returned ErrByzantineData.
+	VerifyAxisRoot(axis Axis, axisIdx uint, shares [][]byte) error
+	// Codec returns the codec used to decode and verify rows and columns.
+	Codec() Codec
+}
+
+// Solver iteratively repairs a CrosswordSquare by decoding the rows and
+// columns that have sufficient shares available until the square is complete.
+type Solver struct {
+	square CrosswordSquare
+	// onProgress and stats are set by Repair, see WithProgress and
+	// WithRepairStats.
+	onProgress func(repairedShares, totalMissing int)
+	stats      *RepairStats
+	// repaired and missing are the number of shares repaired so far and the
+	// number of shares missing when solving started.
+	repaired, missing int
+}
+
+// NewSolver returns a new Solver that repairs square.
+func NewSolver(square CrosswordSquare) *Solver {
+	return &Solver{square: square}
+}
+
+// Solve attempts to iteratively repair the square. Every repaired row or
+// column, as well as every row or column completed by it, is verified against
+// its expected root and its encoding before the repaired shares are set.
+//
+// Returns ErrUnrepairableDataSquare if no further progress can be made before
+// the square is complete, or an ErrByzantineData if a row or column fails
+// verification. Decoding errors wrapping ErrShardSizeMismatch or
+// ErrCodecInternal are returned as is, while other decoding errors are treated
+// as insufficient data.
+func (s *Solver) Solve() error {
+	return s.SolveWithContext(context.Background())
+}
+
+// SolveWithContext is like Solve but aborts and returns ctx.Err() if ctx is
+// done before the square is solved. The context is checked before each row
+// and column is solved.
+func (s *Solver) SolveWithContext(ctx context.Context) error {
+	width := s.square.Width()
+	if s.stats == nil {
+		s.stats = &RepairStats{}
+	}
+	if s.onProgress != nil {
+		s.missing = countMissing(s.square)
+	}
+	// Keep repeating until the square is solved
+	for {
+		s.stats.Iterations++
+		// Track if the entire square is completely solved
+		solved := true
+		// Track if a single iteration of this loop made progress
+		progressMade := false
+
+		// Loop through every row and column, attempt to rebuild each row or column if incomplete
+		for i := uint(0); i < width; i++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			solvedRow, progressMadeRow, err := s.solveAxis(Row, i)
+			if err != nil {
+				return err
+			}
+			solvedCol, progressMadeCol, err := s.solveAxis(Col, i)
+			if err != nil {
+				return err
+			}
+
+			solved = solved && solvedRow && solvedCol
+			progressMade = progressMade || progressMadeRow || progressMadeCol
+		}
+
+		if solved {
+			break
+		}
+		if !progressMade {
+			return ErrUnrepairableDataSquare
+		}
+	}
+
+	return nil
+}
+
+// solveAxes repeatedly attempts to repair the given rows and columns until
+// they are complete or no further progress can be made.
+func (s *Solver) solveAxes(axes []AxisIndex) error {
+	if s.stats == nil {
+		s.stats = &RepairStats{}
+	}
+	for {
+		solved, progressMade := true, false
+		for _, a := range axes {
+			solvedAxis, progressMadeAxis, err := s.solveAxis(a.Axis, a.Index)
+			if err != nil {
+				return err
+			}
+			solved = solved && solvedAxis
+			progressMade = progressMade || progressMadeAxis
+		}
+		if solved || !progressMade {
+			return nil
+		}
+	}
+}
+
+// solveAxis attempts to repair a single row or column.
+// Returns
+// - if the axis is solved (i.e. complete)
+// - if the axis was previously unsolved and now solved
+// - an error if the repair is unsuccessful
+func (s *Solver) solveAxis(axis Axis, axisIdx uint) (bool, bool, error) {
+	isComplete := noMissingData(s.square.GetAxis(axis, axisIdx), noShareInsertion)
+	if isComplete {
+		return true, false, nil
+	}
+
+	// Prepare shares
+	shares := make([][]byte, s.square.Width())
+	copy(shares, s.square.GetAxis(axis, axisIdx))
+
+	// Attempt rebuild the axis
+	rebuiltShares, isDecoded, err := rebuildShares(s.square.Codec(), shares)
+	if !isDecoded {
+		return false, false, err
+	}
+	emitEvent(Event{Type: EventAxisDecoded, Width: s.square.Width(), Axis: axis, Index: axisIdx})
+
+	// Check that rebuilt shares matches appropriate root
+	err = s.square.VerifyAxisRoot(axis, axisIdx, rebuiltShares)
+	if err != nil {
+		var byzErr *ErrByzantineData
+		if errors.As(err, &byzErr) {
+			byzErr.Shares = shares
+		}
+		return false, false, err
+	}
+
+	// Check that newly completed orthogonal vectors match their new merkle roots
+	orthogonal := Col
+	if axis == Col {
+		orthogonal = Row
+	}
+	for orthIdx := uint(0); orthIdx < s.square.Width(); orthIdx++ {
+		orthShares := s.square.GetAxis(orthogonal, orthIdx)
+		if orthShares[axisIdx] != nil {
+			continue // not newly completed
+		}
+		if noMissingData(orthShares, int(axisIdx)) { // completed
+			completed := make([][]byte, len(orthShares))
+			copy(completed, orthShares)
+			completed[axisIdx] = rebuiltShares[orthIdx]
+
+			err := s.square.VerifyAxisRoot(orthogonal, orthIdx, completed)
+			if err != nil {
+				var byzErr *ErrByzantineData
+				if errors.As(err, &byzErr) {
+					byzErr.Shares = shares
+				}
+				return false, false, err
+			}
+
+			if VerifyAxisEncoding(completed, s.square.Codec()) != nil {
+				return false, false, &ErrByzantineData{orthogonal, orthIdx, orthShares, nil}
+			}
+		}
+	}
+
+	// Insert rebuilt shares into square.
+	for i, share := range rebuiltShares {
+		if s.square.GetAxis(axis, axisIdx)[i] != nil {
+			continue
+		}
+		rowIdx, colIdx := axisIdx, uint(i)
+		if axis == Col {
+			rowIdx, colIdx = uint(i), axisIdx
+		}
+		err := s.square.SetShare(rowIdx, colIdx, share)
+		if err != nil {
+			return false, false, err
+		}
+		s.repaired++
+		s.stats.SharesDecoded++
+	}
+
+	if axis == Row {
+		s.stats.RowsSolved++
+	} else {
+		s.stats.ColsSolved++
+	}
+	if s.onProgress != nil {
+		s.onProgress(s.repaired, s.missing)
+	}
+	return true, true, nil
+}
+
+// countMissing returns the number of missing shares in square.
+func countMissing(square CrosswordSquare) int {
+	missing := 0
+	for rowIdx := uint(0); rowIdx < square.Width(); rowIdx++ {
+		for _, share := range square.GetAxis(Row, rowIdx) {
+			if share == nil {
+				missing++
+			}
+		}
+	}
+	return missing
+}
@@ -0,0 +1,155 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapSquare is a CrosswordSquare backed by a map, used to verify that the
+// Solver does not depend on dataSquare storage.
+type mapSquare struct {
+	width    uint
+	shares   map[CellIndex][]byte
+	rowRoots [][]byte
+	colRoots [][]byte
+	codec    Codec
+}
+
+func (m *mapSquare) Width() uint {
+	return m.width
+}
+
+func (m *mapSquare) GetAxis(axis Axis, axisIdx uint) [][]byte {
+	shares := make([][]byte, m.width)
+	for i := uint(0); i < m.width; i++ {
+		if axis == Row {
+			shares[i] = m.shares[CellIndex{Row: axisIdx, Col: i}]
+		} else {
+			shares[i] = m.shares[CellIndex{Row: i, Col: axisIdx}]
+		}
+	}
+	return shares
+}
+
+func (m *mapSquare) SetShare(rowIdx uint, colIdx uint, share []byte) error {
+	m.shares[CellIndex{Row: rowIdx, Col: colIdx}] = share
+	return nil
+}
+
+func (m *mapSquare) VerifyAxisRoot(axis Axis, axisIdx uint, shares [][]byte) error {
+	tree := NewDefaultTree(axis, axisIdx)
+	for _, share := range shares {
+		if err := tree.Push(share); err != nil {
+			return err
+		}
+	}
+	root, err := tree.Root()
+	if err != nil {
+		return err
+	}
+	roots := m.rowRoots
+	if axis == Col {
+		roots = m.colRoots
+	}
+	if !bytes.Equal(root, roots[axisIdx]) {
+		return &ErrByzantineData{axis, axisIdx, nil, nil}
+	}
+	return nil
+}
+
+func (m *mapSquare) Codec() Codec {
+	return m.codec
+}
+
+func newMapSquare(t *testing.T, eds *ExtendedDataSquare, missing ...CellIndex) *mapSquare {
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+
+	square := &mapSquare{
+		width:    eds.Width(),
+		shares:   make(map[CellIndex][]byte),
+		rowRoots: rowRoots,
+		colRoots: colRoots,
+		codec:    eds.codec,
+	}
+	for rowIdx := uint(0); rowIdx < eds.Width(); rowIdx++ {
+		for colIdx := uint(0); colIdx < eds.Width(); colIdx++ {
+			square.shares[CellIndex{rowIdx, colIdx}] = eds.GetCell(rowIdx, colIdx)
+		}
+	}
+	for _, cell := range missing {
+		delete(square.shares, cell)
+	}
+	return square
+}
+
+func TestSolver(t *testing.T) {
+	eds := createExampleEds(t, shareSize)
+
+	t.Run("repairs the square", func(t *testing.T) {
+		square := newMapSquare(t, eds,
+			CellIndex{0, 0}, CellIndex{0, 2}, CellIndex{0, 3},
+			CellIndex{1, 0}, CellIndex{1, 1}, CellIndex{1, 2}, CellIndex{1, 3},
+			CellIndex{2, 0}, CellIndex{2, 1}, CellIndex{2, 2},
+			CellIndex{3, 0}, CellIndex{3, 1},
+		)
+		require.NoError(t, NewSolver(square).Solve())
+		for rowIdx := uint(0); rowIdx < eds.Width(); rowIdx++ {
+			assert.Equal(t, eds.Row(rowIdx), square.GetAxis(Row, rowIdx))
+		}
+	})
+
+	t.Run("returns ErrUnrepairableDataSquare", func(t *testing.T) {
+		square := newMapSquare(t, eds,
+			CellIndex{0, 0}, CellIndex{0, 1}, CellIndex{0, 2}, CellIndex{0, 3},
+			CellIndex{1, 0}, CellIndex{1, 1}, CellIndex{1, 2}, CellIndex{1, 3},
+			CellIndex{2, 0}, CellIndex{2, 1}, CellIndex{2, 2},
+			CellIndex{3, 0}, CellIndex{3, 1},
+		)
+		err := NewSolver(square).Solve()
+		assert.True(t, errors.Is(err, ErrUnrepairableDataSquare))
+	})
+
+	t.Run("returns ErrByzantineData", func(t *testing.T) {
+		square := newMapSquare(t, eds, CellIndex{0, 0}, CellIndex{0, 1})
+		corrupted := bytes.Repeat([]byte{66}, shareSize)
+		square.shares[CellIndex{0, 2}] = corrupted
+
+		err := NewSolver(square).Solve()
+		var byzErr *ErrByzantineData
+		require.ErrorAs(t, err, &byzErr)
+		assert.Equal(t, Row, byzErr.Axis)
+		assert.Equal(t, uint(0), byzErr.Index)
+		assert.Contains(t, byzErr.Shares, corrupted)
+	})
+	t.Run("aborts when the context is done", func(t *testing.T) {
+		square := newMapSquare(t, eds, CellIndex{0, 0})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := NewSolver(square).SolveWithContext(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, square.GetAxis(Row, 0)[0])
+	})
+	t.Run("returns codec faults", func(t *testing.T) {
+		square := newMapSquare(t, eds, CellIndex{0, 0})
+		square.codec = &faultyCodec{NewLeoRSCodec()}
+		err := NewSolver(square).Solve()
+		assert.ErrorIs(t, err, ErrCodecInternal)
+	})
+}
+
+// faultyCodec is a codec whose decoding always fails with ErrCodecInternal.
+type faultyCodec struct {
+	*LeoRSCodec
+}
+
+func (c *faultyCodec) Decode(_ [][]byte) ([][]byte, error) {
+	return nil, ErrCodecInternal
+}
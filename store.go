@@ -0,0 +1,28 @@
+package rsmt2d
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrSquareNotFound is returned by EDSStore.Get if the store has no square
+// with the requested data root.
+var ErrSquareNotFound = errors.New("square not found")
+
+// EDSStore persists extended data squares by their data root, see DataRoot.
+// Implementations must be safe for concurrent use. See the fsstore package
+// for a reference implementation on the filesystem.
+type EDSStore interface {
+	// Put stores eds under dataRoot, replacing any square already stored
+	// under it. The store does not verify that dataRoot is the data root of
+	// eds.
+	Put(ctx context.Context, dataRoot []byte, eds *ExtendedDataSquare) error
+	// Get returns the square stored under dataRoot. Returns an error
+	// wrapping ErrSquareNotFound if there is none.
+	Get(ctx context.Context, dataRoot []byte) (*ExtendedDataSquare, error)
+	// Has reports whether a square is stored under dataRoot.
+	Has(ctx context.Context, dataRoot []byte) (bool, error)
+	// Delete removes the square stored under dataRoot. Deleting a square
+	// that is not stored is not an error.
+	Delete(ctx context.Context, dataRoot []byte) error
+}
@@ -0,0 +1,223 @@
+package rsmt2d
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+var (
+	_ io.WriterTo   = &ExtendedDataSquare{}
+	_ io.ReaderFrom = &ExtendedDataSquare{}
+)
+
+// WriteTo fulfills the io.WriterTo interface by streaming the extended data
+// square to w, shares included, without copying them first. Missing shares
+// are supported. The square can be read back with ReadFrom. The stream
+// consists of
+//
+//   - the length of the codec name as a single byte, followed by the name,
+//   - the codec version, the width of the square and the share size, each as
+//     a big-endian uint32,
+//   - a bit matrix of the present shares in row-major order, as
+//     little-endian uint64 words,
+//   - the present shares in row-major order.
+func (eds *ExtendedDataSquare) WriteTo(w io.Writer) (int64, error) {
+	name := eds.codec.Name()
+	if len(name) > math.MaxUint8 {
+		return 0, fmt.Errorf("codec name %q is too long to be streamed", name)
+	}
+
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+
+	header := make([]byte, 0, 1+len(name)+12)
+	header = append(header, byte(len(name)))
+	header = append(header, name...)
+	header = binary.BigEndian.AppendUint32(header, uint32(codecVersion(eds.codec)))
+	header = binary.BigEndian.AppendUint32(header, uint32(eds.width))
+	header = binary.BigEndian.AppendUint32(header, uint32(eds.shareSize))
+	if _, err := bw.Write(header); err != nil {
+		return cw.n, err
+	}
+
+	present := newBitMatrix(eds.width)
+	for rowIdx := uint(0); rowIdx < eds.width; rowIdx++ {
+		for colIdx, share := range eds.row(rowIdx) {
+			if share != nil {
+				present.set(rowIdx, uint(colIdx))
+			}
+		}
+	}
+	var word [8]byte
+	for _, bits := range present.words {
+		binary.LittleEndian.PutUint64(word[:], bits)
+		if _, err := bw.Write(word[:]); err != nil {
+			return cw.n, err
+		}
+	}
+
+	for rowIdx := uint(0); rowIdx < eds.width; rowIdx++ {
+		for _, share := range eds.row(rowIdx) {
+			if _, err := bw.Write(share); err != nil {
+				return cw.n, err
+			}
+		}
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// ReadFrom fulfills the io.ReaderFrom interface by reading an extended data
+// square streamed by WriteTo from r. The shares are read into a single
+// buffer. Like UnmarshalJSON, the codec must be registered, the square
+// uses the default tree, and DefaultMaxImportWidth and DefaultMaxImportBytes
+// are honored before anything is read past the header. The buffers for the
+// presence bits and the shares grow as their bytes arrive, so a header that
+// claims a larger square than r holds can not cause an allocation much
+// larger than the bytes read, but as these limits are unlimited by default,
+// they must be set to bound the size of the squares that are read. Reading
+// stops at the end of the square.
+func (eds *ExtendedDataSquare) ReadFrom(r io.Reader) (int64, error) {
+	return eds.readFrom(r, defaultDecoder())
+}
+
+// readFrom is like ReadFrom, but resolves the codec and tree with dec.
+func (eds *ExtendedDataSquare) readFrom(r io.Reader, dec squareDecoder) (int64, error) {
+	cr := &countingReader{r: r}
+	err := eds.readStream(cr, func(size int) ([]byte, error) {
+		return readGrowing(cr, size)
+	}, dec)
+	return cr.n, err
+}
+
+// readStream reads an extended data square streamed by WriteTo from r into
+// eds. The header is read from r, and the presence bits and shares with read,
+// which must return the next size bytes of the stream, or an error if the
+// stream holds fewer bytes. The present shares are sliced from the buffer it
+// returns. The codec and tree of the square are resolved with dec.
+func (eds *ExtendedDataSquare) readStream(r io.Reader, read func(size int) ([]byte, error), dec squareDecoder) error {
+	var nameLen [1]byte
+	if _, err := io.ReadFull(r, nameLen[:]); err != nil {
+		return err
+	}
+	header := make([]byte, int(nameLen[0])+12)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return err
+	}
+	id := codecID{
+		Name:    string(header[:nameLen[0]]),
+		Version: int(binary.BigEndian.Uint32(header[nameLen[0]:])),
+	}
+	width := uint(binary.BigEndian.Uint32(header[nameLen[0]+4:]))
+	shareSize := uint(binary.BigEndian.Uint32(header[nameLen[0]+8:]))
+
+	codec, err := dec.resolveCodec(id)
+	if err != nil {
+		return err
+	}
+	if err := validateEdsWidth(width); err != nil {
+		return err
+	}
+	if err := ValidateWidth(codec, width/2); err != nil {
+		return err
+	}
+	cfg := newImportConfig(dec.opts...)
+	if err := cfg.checkLimits(int(width*width), int(shareSize)); err != nil {
+		return err
+	}
+	if err := codec.ValidateChunkSize(int(shareSize)); err != nil {
+		return err
+	}
+
+	// the presence bits are read before the bit matrix is allocated, so that
+	// its size is bounded by the bytes actually in the stream
+	words, err := read(8 * int((width*width+63)/64))
+	if err != nil {
+		return err
+	}
+	present := newBitMatrix(width)
+	for i := range present.words {
+		present.words[i] = binary.LittleEndian.Uint64(words[8*i:])
+	}
+	count := 0
+	for rowIdx := uint(0); rowIdx < width; rowIdx++ {
+		count += int(present.rowCount(rowIdx))
+	}
+
+	buf, err := read(count * int(shareSize))
+	if err != nil {
+		return err
+	}
+	data := make([][]byte, width*width)
+	for i := range data {
+		if present.get(CoordinatesOf(uint(i), width)) {
+			data[i], buf = buf[:shareSize:shareSize], buf[shareSize:]
+		}
+	}
+
+	ds, err := newDataSquare(data, dec.tree(), shareSize)
+	if err != nil {
+		return err
+	}
+	decoded := ExtendedDataSquare{dataSquare: ds, codec: codec, originalDataWidth: width / 2}
+	if cfg.parityCheck {
+		if err := decoded.checkParity(cfg.paritySamples); err != nil {
+			return err
+		}
+	}
+	*eds = decoded
+	return nil
+}
+
+// readGrowing reads exactly size bytes from r. Instead of allocating size
+// bytes up front, the buffer starts small and doubles as the bytes arrive, so
+// that a stream holding fewer bytes than claimed fails after allocating at
+// most about twice the bytes it holds. Like io.ReadFull, it returns io.EOF if
+// no bytes were read and io.ErrUnexpectedEOF if only some were.
+func readGrowing(r io.Reader, size int) ([]byte, error) {
+	const initialSize = 64 << 10
+	buf := make([]byte, 0, min(size, initialSize))
+	for len(buf) < size {
+		if len(buf) == cap(buf) {
+			grown := make([]byte, len(buf), min(size, 2*cap(buf)))
+			copy(grown, buf)
+			buf = grown
+		}
+		n, err := io.ReadFull(r, buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if err == io.EOF && len(buf) > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,79 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteToReadFrom(t *testing.T) {
+	want, err := ComputeExtendedDataSquare(generateRandData(16, shareSize), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	want.clearCell(1, 2)
+	want.clearCell(7, 7)
+
+	var buf bytes.Buffer
+	n, err := want.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+	// one header, the presence bits and the present shares
+	assert.Equal(t, 1+len(Leopard)+12+8+62*shareSize, buf.Len())
+
+	var eds ExtendedDataSquare
+	n, err = eds.ReadFrom(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+	assert.True(t, want.Equals(&eds))
+	assert.Equal(t, want.MissingCells(), eds.MissingCells())
+
+	t.Run("truncated input", func(t *testing.T) {
+		for _, size := range []int{0, 3, 1 + len(Leopard) + 12, buf.Len() - 1} {
+			var eds ExtendedDataSquare
+			_, err := eds.ReadFrom(bytes.NewReader(buf.Bytes()[:size]))
+			assert.Error(t, err, "size %d", size)
+		}
+	})
+
+	t.Run("header claiming a large square", func(t *testing.T) {
+		header := largeSquareHeader(65536)
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		var eds ExtendedDataSquare
+		_, err := eds.ReadFrom(bytes.NewReader(header))
+		runtime.ReadMemStats(&after)
+		assert.ErrorIs(t, err, io.EOF)
+		// the presence bits of the claimed square alone take 512 MiB
+		assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20))
+	})
+
+	t.Run("honors the default limits", func(t *testing.T) {
+		DefaultMaxImportWidth = want.Width() - 1
+		defer func() { DefaultMaxImportWidth = 0 }()
+
+		var eds ExtendedDataSquare
+		_, err := eds.ReadFrom(bytes.NewReader(buf.Bytes()))
+		assert.ErrorIs(t, err, ErrSquareTooLarge)
+	})
+
+	t.Run("unregistered codec", func(t *testing.T) {
+		corrupted := append([]byte(nil), buf.Bytes()...)
+		corrupted[1]++
+		var eds ExtendedDataSquare
+		_, err := eds.ReadFrom(bytes.NewReader(corrupted))
+		assert.ErrorIs(t, err, ErrCodecNotRegistered)
+	})
+}
+
+// largeSquareHeader returns the header of a streamed square of the given
+// width, without any presence bits or shares following it.
+func largeSquareHeader(width uint32) []byte {
+	header := append([]byte{byte(len(Leopard))}, Leopard...)
+	header = binary.BigEndian.AppendUint32(header, uint32(codecVersion(NewLeoRSCodec())))
+	header = binary.BigEndian.AppendUint32(header, width)
+	return binary.BigEndian.AppendUint32(header, shareSize)
+}
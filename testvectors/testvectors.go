@@ -0,0 +1,108 @@
+// Package testvectors provides canonical original data squares and their
+// expected extended data squares for the codecs of rsmt2d, so that alternative
+// implementations can check that they are encoding-compatible with this one.
+// The vectors are stored as JSON in vectors.json.
+package testvectors
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/celestiaorg/rsmt2d"
+)
+
+// ErrNoVectors is returned by RunConformance if there are no test vectors for
+// the codec.
+var ErrNoVectors = errors.New("no test vectors for codec")
+
+//go:embed vectors.json
+var vectorsJSON []byte
+
+// Vector is a test vector of a codec.
+type Vector struct {
+	// Name describes the vector.
+	Name string `json:"name"`
+	// Codec is the name of the codec the vector was generated with.
+	Codec string `json:"codec"`
+	// ODS is the flattened original data square.
+	ODS [][]byte `json:"ods"`
+	// EDS is the expected flattened extended data square of ODS.
+	EDS [][]byte `json:"eds"`
+}
+
+// Vectors returns all test vectors.
+func Vectors() ([]Vector, error) {
+	var vectors []Vector
+	if err := json.Unmarshal(vectorsJSON, &vectors); err != nil {
+		return nil, fmt.Errorf("unmarshalling test vectors: %w", err)
+	}
+	return vectors, nil
+}
+
+// JSON returns the test vectors as JSON, e.g. to write them to a file for
+// implementations in other languages.
+func JSON() []byte {
+	return bytes.Clone(vectorsJSON)
+}
+
+// RunConformance checks that codec extends and decodes the original data
+// squares of all test vectors of the codec with the same name as expected.
+// Returns ErrNoVectors if there are no test vectors for the codec.
+func RunConformance(codec rsmt2d.Codec) error {
+	vectors, err := Vectors()
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, vector := range vectors {
+		if vector.Codec != codec.Name() {
+			continue
+		}
+		found = true
+		if err := checkVector(codec, vector); err != nil {
+			return fmt.Errorf("vector %s: %w", vector.Name, err)
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrNoVectors, codec.Name())
+	}
+	return nil
+}
+
+// checkVector checks that codec extends vector.ODS to vector.EDS, and that
+// every row of vector.EDS can be decoded from its parity half.
+func checkVector(codec rsmt2d.Codec, vector Vector) error {
+	eds, err := rsmt2d.ComputeExtendedDataSquare(vector.ODS, codec, rsmt2d.NewDefaultTree)
+	if err != nil {
+		return fmt.Errorf("extending ODS: %w", err)
+	}
+	flattened := eds.Flattened()
+	if len(flattened) != len(vector.EDS) {
+		return fmt.Errorf("got %d shares, expected %d", len(flattened), len(vector.EDS))
+	}
+	for i := range flattened {
+		if !bytes.Equal(flattened[i], vector.EDS[i]) {
+			return fmt.Errorf("share %d of the EDS does not match", i)
+		}
+	}
+
+	width := int(eds.Width())
+	for rowIdx := 0; rowIdx < width; rowIdx++ {
+		row := make([][]byte, width)
+		copy(row[width/2:], vector.EDS[rowIdx*width+width/2:(rowIdx+1)*width])
+		decoded, err := codec.Decode(row)
+		if err != nil {
+			return fmt.Errorf("decoding row %d: %w", rowIdx, err)
+		}
+		for colIdx := 0; colIdx < width/2; colIdx++ {
+			if !bytes.Equal(decoded[colIdx], vector.EDS[rowIdx*width+colIdx]) {
+				return fmt.Errorf("decoded share (%d, %d) does not match", rowIdx, colIdx)
+			}
+		}
+	}
+	return nil
+}
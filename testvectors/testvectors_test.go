@@ -0,0 +1,101 @@
+package testvectors
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/celestiaorg/rsmt2d"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "regenerate vectors.json")
+
+// canonicalODS returns an original data square of width odsWidth whose share k
+// consists of bytes k*shareStep+1, k*shareStep+1+byteStep, ...
+func canonicalODS(odsWidth, shareSize int, shareStep, byteStep byte) [][]byte {
+	ods := make([][]byte, odsWidth*odsWidth)
+	for k := range ods {
+		ods[k] = make([]byte, shareSize)
+		for j := range ods[k] {
+			ods[k][j] = byte(k)*shareStep + 1 + byte(j)*byteStep
+		}
+	}
+	return ods
+}
+
+// generated lists the vectors in vectors.json, which are generated by
+// running the tests with -update.
+var generated = []struct {
+	codec     rsmt2d.Codec
+	odsWidth  int
+	shareSize int
+	shareStep byte
+	byteStep  byte
+}{
+	{rsmt2d.NewLeoRSCodec(), 1, 512, 1, 0},
+	{rsmt2d.NewLeoRSCodec(), 2, 512, 1, 0},
+	{rsmt2d.NewRSGF8Codec(), 2, 8, 1, 0},
+	{rsmt2d.NewRSGF8Codec(), 4, 8, 16, 1},
+	{rsmt2d.NewDuplicationCodec(), 2, 1, 1, 0},
+	{rsmt2d.NewFountainCodec(), 4, 8, 16, 1},
+}
+
+func TestVectors(t *testing.T) {
+	if *update {
+		var vectors []Vector
+		for _, g := range generated {
+			ods := canonicalODS(g.odsWidth, g.shareSize, g.shareStep, g.byteStep)
+			eds, err := rsmt2d.ComputeExtendedDataSquare(ods, g.codec, rsmt2d.NewDefaultTree)
+			require.NoError(t, err)
+			vectors = append(vectors, Vector{
+				Name:  fmt.Sprintf("%dx%d/%d", g.odsWidth, g.odsWidth, g.shareSize),
+				Codec: g.codec.Name(),
+				ODS:   ods,
+				EDS:   eds.Flattened(),
+			})
+		}
+		out, err := json.MarshalIndent(vectors, "", "  ")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile("vectors.json", append(out, '\n'), 0o644))
+		vectorsJSON = out
+	}
+
+	vectors, err := Vectors()
+	require.NoError(t, err)
+	assert.Len(t, vectors, len(generated))
+}
+
+func TestRunConformance(t *testing.T) {
+	for _, codec := range []rsmt2d.Codec{
+		rsmt2d.NewLeoRSCodec(), rsmt2d.NewRSGF8Codec(), rsmt2d.NewDuplicationCodec(), rsmt2d.NewFountainCodec(),
+	} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			assert.NoError(t, RunConformance(codec))
+		})
+	}
+
+	t.Run("fails for a codec with different parity", func(t *testing.T) {
+		// Duplication parity differs from RSGF8 parity
+		err := RunConformance(&renamedCodec{rsmt2d.NewDuplicationCodec(), rsmt2d.RSGF8})
+		assert.Error(t, err)
+	})
+
+	t.Run("returns ErrNoVectors for an unknown codec", func(t *testing.T) {
+		err := RunConformance(&renamedCodec{rsmt2d.NewRSGF8Codec(), "unknown"})
+		assert.ErrorIs(t, err, ErrNoVectors)
+	})
+}
+
+// renamedCodec is a codec with another name.
+type renamedCodec struct {
+	rsmt2d.Codec
+	name string
+}
+
+func (c *renamedCodec) Name() string {
+	return c.name
+}
@@ -1,7 +1,9 @@
 package rsmt2d
 
 import (
+	"bytes"
 	"crypto/sha256"
+	"fmt"
 
 	"github.com/celestiaorg/merkletree"
 )
@@ -10,6 +12,26 @@ import (
 // inside of rsmt2d.
 type TreeConstructorFn = func(axis Axis, index uint) Tree
 
+// SquareInfo describes the extended data square a Tree is created for.
+type SquareInfo struct {
+	// Width is the number of shares per row or column of the extended data
+	// square.
+	Width uint
+	// ShareSize is the size of each share in bytes.
+	ShareSize uint
+}
+
+// SquareAwareTree is an optional interface implemented by Tree
+// implementations that depend on the dimensions of the square, such as
+// namespaced Merkle trees that need to know where the original data ends.
+// rsmt2d calls SetSquareInfo on every tree it creates before pushing to it,
+// so that such trees do not need to be constructed with the dimensions
+// out-of-band.
+type SquareAwareTree interface {
+	Tree
+	SetSquareInfo(info SquareInfo)
+}
+
 // SquareIndex contains all information needed to identify the cell that is being
 // pushed
 type SquareIndex struct {
@@ -22,12 +44,25 @@ type Tree interface {
 	Root() ([]byte, error)
 }
 
-var _ Tree = &DefaultTree{}
+var (
+	_ Tree        = &DefaultTree{}
+	_ ProvingTree = &DefaultTree{}
+	_ Releasable  = &DefaultTree{}
+
+	_ SubtreeRootTree = &DefaultTree{}
+	_ ConsumableTree  = &DefaultTree{}
+	_ VerifyingTree   = &DefaultTree{}
+
+	_ RangeProvingTree   = &DefaultTree{}
+	_ RangeVerifyingTree = &DefaultTree{}
+)
 
 type DefaultTree struct {
 	*merkletree.Tree
 	leaves [][]byte
 	root   []byte
+	// pool is the pool the tree is returned to by Release, if any.
+	pool *TreePool
 }
 
 func NewDefaultTree(_ Axis, _ uint) Tree {
@@ -52,3 +87,248 @@ func (d *DefaultTree) Root() ([]byte, error) {
 	}
 	return d.root, nil
 }
+
+// ConsumeRoot returns the same root as Root, but drops the references to the
+// pushed leaves instead of caching the root. The tree must not be used
+// afterwards, except to release it.
+func (d *DefaultTree) ConsumeRoot() ([]byte, error) {
+	if d.root != nil {
+		return d.root, nil
+	}
+	for _, l := range d.leaves {
+		d.Tree.Push(l)
+	}
+	clear(d.leaves)
+	d.leaves = d.leaves[:0]
+	return d.Tree.Root(), nil
+}
+
+// Release returns the tree to the TreePool that created it. It is a no-op
+// for trees created by NewDefaultTree.
+func (d *DefaultTree) Release() {
+	pool := d.pool
+	if pool == nil {
+		return
+	}
+	d.pool = nil
+	d.Tree = merkletree.New(sha256.New())
+	clear(d.leaves)
+	d.leaves = d.leaves[:0]
+	d.root = nil
+	pool.pool.Put(d)
+}
+
+// Releasable is an optional interface implemented by Tree implementations
+// that can be recycled once their root has been read. rsmt2d releases the
+// trees it creates to compute roots, so the returned root must stay valid
+// after Release.
+type Releasable interface {
+	Release()
+}
+
+// ConsumableTree is an optional interface implemented by Tree implementations
+// that can compute their root more cheaply when they are not used afterwards.
+// rsmt2d prefers ConsumeRoot over Root for the trees it creates to compute
+// roots.
+type ConsumableTree interface {
+	Tree
+	// ConsumeRoot returns the root of the tree, possibly destroying its
+	// internal state in the process.
+	ConsumeRoot() ([]byte, error)
+}
+
+// consumeRoot returns the root of tree, which must not be used afterwards.
+func consumeRoot(tree Tree) ([]byte, error) {
+	if c, ok := tree.(ConsumableTree); ok {
+		return c.ConsumeRoot()
+	}
+	return tree.Root()
+}
+
+// releaseTree releases tree if it implements Releasable.
+func releaseTree(tree Tree) {
+	if r, ok := tree.(Releasable); ok {
+		r.Release()
+	}
+}
+
+// Prove returns the root of the tree along with the Merkle proof of inclusion
+// for the leaf at leafIdx, as produced by merkletree.Tree.Prove.
+func (d *DefaultTree) Prove(leafIdx int) ([]byte, [][]byte, error) {
+	if leafIdx < 0 || leafIdx >= len(d.leaves) {
+		return nil, nil, fmt.Errorf("leaf index %d is out of bounds for %d leaves", leafIdx, len(d.leaves))
+	}
+	// the embedded tree may already have been consumed by Root, so the proof
+	// is computed over a fresh one
+	tree := merkletree.New(sha256.New())
+	if err := tree.SetIndex(uint64(leafIdx)); err != nil {
+		return nil, nil, err
+	}
+	for _, l := range d.leaves {
+		tree.Push(l)
+	}
+	root, proof, _, _ := tree.Prove()
+	return root, proof, nil
+}
+
+// VerifyProof returns true if proof, as produced by Prove, proves the
+// inclusion of leaf at leafIdx in a tree of numLeaves leaves with the given
+// root.
+func (d *DefaultTree) VerifyProof(root []byte, leaf []byte, proof [][]byte, leafIdx int, numLeaves int) bool {
+	if len(proof) == 0 || !bytes.Equal(proof[0], leaf) || leafIdx < 0 || leafIdx >= numLeaves {
+		return false
+	}
+	return merkletree.VerifyProof(sha256.New(), root, proof, uint64(leafIdx), uint64(numLeaves))
+}
+
+// ProveLeafRange returns the root of the tree along with a single Merkle
+// range proof of the leaves in [start, end), as produced by
+// merkletree.BuildRangeProof. All leaves must be of equal size.
+func (d *DefaultTree) ProveLeafRange(start, end int) ([]byte, [][]byte, error) {
+	if start < 0 || start >= end || end > len(d.leaves) {
+		return nil, nil, fmt.Errorf("invalid range [%d, %d) for %d leaves", start, end, len(d.leaves))
+	}
+	data, leafSize, err := joinLeaves(d.leaves)
+	if err != nil {
+		return nil, nil, err
+	}
+	proof, err := merkletree.BuildRangeProof(start, end, merkletree.NewReaderSubtreeHasher(bytes.NewReader(data), leafSize, sha256.New()))
+	if err != nil {
+		return nil, nil, err
+	}
+	// the embedded tree may already have been consumed by Root, so the root
+	// is computed over a fresh one
+	tree := merkletree.New(sha256.New())
+	for _, l := range d.leaves {
+		tree.Push(l)
+	}
+	return tree.Root(), proof, nil
+}
+
+// VerifyLeafRange returns true if proof, as produced by ProveLeafRange,
+// proves the inclusion of leaves at [start, end) in the tree with the given
+// root.
+func (d *DefaultTree) VerifyLeafRange(root []byte, leaves [][]byte, proof [][]byte, start, end int) bool {
+	if start < 0 || start >= end || len(leaves) != end-start {
+		return false
+	}
+	data, leafSize, err := joinLeaves(leaves)
+	if err != nil {
+		return false
+	}
+	ok, err := merkletree.VerifyRangeProof(merkletree.NewReaderLeafHasher(bytes.NewReader(data), sha256.New(), leafSize), sha256.New(), start, end, proof, root)
+	return err == nil && ok
+}
+
+// joinLeaves concatenates leaves, which must all be of equal size, and
+// returns the result along with the size of the leaves.
+func joinLeaves(leaves [][]byte) ([]byte, int, error) {
+	leafSize := len(leaves[0])
+	for i, l := range leaves {
+		if len(l) != leafSize {
+			return nil, 0, fmt.Errorf("leaf %d has %d bytes, expected %d", i, len(l), leafSize)
+		}
+	}
+	return bytes.Join(leaves, nil), leafSize, nil
+}
+
+// SubtreeRoots returns the roots of the consecutive subtrees of subtreeWidth
+// leaves each. subtreeWidth must be a power of two that divides the number of
+// leaves, so that every subtree root is a node of the tree.
+func (d *DefaultTree) SubtreeRoots(subtreeWidth int) ([][]byte, error) {
+	if subtreeWidth <= 0 || subtreeWidth&(subtreeWidth-1) != 0 || len(d.leaves)%subtreeWidth != 0 {
+		return nil, fmt.Errorf("subtree width %d must be a power of two that divides the %d leaves", subtreeWidth, len(d.leaves))
+	}
+	roots := make([][]byte, 0, len(d.leaves)/subtreeWidth)
+	for start := 0; start < len(d.leaves); start += subtreeWidth {
+		tree := merkletree.New(sha256.New())
+		for _, l := range d.leaves[start : start+subtreeWidth] {
+			tree.Push(l)
+		}
+		roots = append(roots, tree.Root())
+	}
+	return roots, nil
+}
+
+// ProvingTree is an optional interface implemented by Tree implementations
+// that can generate inclusion proofs for their leaves.
+type ProvingTree interface {
+	Tree
+	// Prove returns the root of the tree and the inclusion proof for the
+	// leaf at leafIdx.
+	Prove(leafIdx int) (root []byte, proof [][]byte, err error)
+}
+
+// VerifyingTree is an optional interface implemented by Tree implementations
+// that can verify the inclusion proofs generated by their ProvingTree
+// implementation. The state of the tree is not used.
+type VerifyingTree interface {
+	Tree
+	// VerifyProof returns true if proof proves the inclusion of leaf at
+	// leafIdx in a tree of numLeaves leaves with the given root.
+	VerifyProof(root []byte, leaf []byte, proof [][]byte, leafIdx int, numLeaves int) bool
+}
+
+// RangeProvingTree is an optional interface implemented by Tree
+// implementations that can generate a single inclusion proof for a range of
+// their leaves.
+type RangeProvingTree interface {
+	Tree
+	// ProveLeafRange returns the root of the tree and the inclusion proof
+	// for the leaves in [start, end).
+	ProveLeafRange(start, end int) (root []byte, proof [][]byte, err error)
+}
+
+// RangeVerifyingTree is an optional interface implemented by Tree
+// implementations that can verify the range proofs generated by their
+// RangeProvingTree implementation. The state of the tree is not used.
+type RangeVerifyingTree interface {
+	Tree
+	// VerifyLeafRange returns true if proof proves the inclusion of leaves
+	// at [start, end) in a tree with the given root.
+	VerifyLeafRange(root []byte, leaves [][]byte, proof [][]byte, start, end int) bool
+}
+
+// SubtreeRootTree is an optional interface implemented by Tree
+// implementations that can return the roots of their subtrees.
+type SubtreeRootTree interface {
+	Tree
+	// SubtreeRoots returns the roots of the consecutive subtrees of
+	// subtreeWidth leaves each, in leaf order.
+	SubtreeRoots(subtreeWidth int) ([][]byte, error)
+}
+
+// NamespaceProof is a proof generated by a namespace-aware Tree. It is passed
+// through unmodified from the underlying implementation (e.g. an nmt.Proof) so
+// that verifiers can check both inclusion and namespace ordering.
+type NamespaceProof interface {
+	// Start returns the index of the first leaf covered by the proof.
+	Start() int
+	// End returns the index following the last leaf covered by the proof.
+	End() int
+	// Nodes returns the nodes required to reconstruct the root.
+	Nodes() [][]byte
+	// IsOfAbsence returns true if the proof proves the absence of a
+	// namespace, in which case the leaves in [Start(), End()) belong to a
+	// different namespace.
+	IsOfAbsence() bool
+}
+
+// NamespaceTree is an optional interface implemented by namespace-aware Tree
+// implementations such as namespaced Merkle trees.
+type NamespaceTree interface {
+	Tree
+	// ProveRange returns a namespace proof for the leaves in [start, end).
+	ProveRange(start, end int) (NamespaceProof, error)
+}
+
+// NamespaceQueryTree is an optional interface implemented by namespace-aware
+// Tree implementations that can look up the leaves of a namespace.
+type NamespaceQueryTree interface {
+	NamespaceTree
+	// ProveNamespace returns the proof of the leaves of namespace nID, which
+	// is a proof of absence if nID is within the namespace range of the tree
+	// but none of the leaves belongs to it. Returns a nil proof if nID is
+	// outside of the namespace range of the tree.
+	ProveNamespace(nID []byte) (NamespaceProof, error)
+}
@@ -0,0 +1,171 @@
+package rsmt2d
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrTreeAlreadyRegistered is returned by RegisterTreeFactory when a
+	// tree factory is already registered under the given name.
+	ErrTreeAlreadyRegistered = errors.New("tree already registered")
+	// ErrTreeNotRegistered is returned when unmarshalling a square whose tree
+	// has no registered factory.
+	ErrTreeNotRegistered = errors.New("tree not registered")
+	// ErrInvalidTreeParams is meant to be wrapped by a TreeFactory when the
+	// tree parameters it is given are missing or invalid.
+	ErrInvalidTreeParams = errors.New("invalid tree parameters")
+)
+
+// DefaultTreeName is the name DefaultTree is registered under.
+const DefaultTreeName = "default"
+
+// TreeFactory creates the tree constructor of a deserialized square from the
+// tree parameters it was serialized with, see WithTree. params is nil if the
+// square was serialized without parameters.
+type TreeFactory func(params json.RawMessage) (TreeConstructorFn, error)
+
+// treeSpec identifies the tree of a serialized square.
+type treeSpec struct {
+	Name   string          `json:"name"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// trees is a global map of the registered tree factories, used for JSON
+// unmarshalling
+var (
+	trees   = make(map[string]TreeFactory)
+	treesMu sync.RWMutex
+)
+
+func init() {
+	MustRegisterTree(DefaultTreeName, func(json.RawMessage) (TreeConstructorFn, error) {
+		return NewDefaultTree, nil
+	})
+}
+
+// RegisterTreeFactory registers factory under name so that squares
+// serialized with WithTree(name, params) are unmarshalled with the tree
+// constructor it creates from params. Unlike comparing constructor functions,
+// this works for closures such as the NMT constructor. Returns an error if a
+// factory is already registered under name.
+func RegisterTreeFactory(name string, factory TreeFactory) error {
+	treesMu.Lock()
+	defer treesMu.Unlock()
+
+	if trees[name] != nil {
+		return fmt.Errorf("%w: %q", ErrTreeAlreadyRegistered, name)
+	}
+	trees[name] = factory
+	return nil
+}
+
+// MustRegisterTree is like RegisterTreeFactory, but panics if a factory is
+// already registered under name. It is meant for registering trees from init
+// functions.
+func MustRegisterTree(name string, factory TreeFactory) {
+	if err := RegisterTreeFactory(name, factory); err != nil {
+		panic(err)
+	}
+}
+
+// ReplaceTree registers factory under name, replacing the factory currently
+// registered under name, if any. It returns a function that restores the
+// replaced factory, or deregisters factory if there was none, so that test
+// suites can temporarily override a tree:
+//
+//	defer rsmt2d.ReplaceTree(name, factory)()
+func ReplaceTree(name string, factory TreeFactory) (restore func()) {
+	treesMu.Lock()
+	defer treesMu.Unlock()
+
+	previous, ok := trees[name]
+	trees[name] = factory
+	return func() {
+		treesMu.Lock()
+		defer treesMu.Unlock()
+
+		if ok {
+			trees[name] = previous
+		} else {
+			delete(trees, name)
+		}
+	}
+}
+
+// DeregisterTree removes the tree factory registered under name. It is a
+// no-op if no factory is registered under name.
+func DeregisterTree(name string) {
+	treesMu.Lock()
+	defer treesMu.Unlock()
+
+	delete(trees, name)
+}
+
+// GetTree returns the tree factory registered under name and true, or nil and
+// false if no factory is registered under name.
+func GetTree(name string) (TreeFactory, bool) {
+	treesMu.RLock()
+	defer treesMu.RUnlock()
+
+	factory, ok := trees[name]
+	return factory, ok
+}
+
+// Trees returns the sorted names of all registered tree factories.
+func Trees() []string {
+	treesMu.RLock()
+	defer treesMu.RUnlock()
+
+	names := make([]string, 0, len(trees))
+	for name := range trees {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithTree records the name of the tree of the square and its parameters,
+// marshalled to JSON, in the output of MarshalJSONWith. When the square is
+// unmarshalled, the tree factory registered under name is called with the
+// parameters to create its tree constructor.
+func WithTree(name string, params any) MarshalOption {
+	return func(cfg *marshalConfig) {
+		cfg.treeName = name
+		cfg.treeParams = params
+	}
+}
+
+// newTreeSpec returns the treeSpec configured by WithTree, or nil if there is
+// none.
+func (cfg marshalConfig) newTreeSpec() (*treeSpec, error) {
+	if cfg.treeName == "" {
+		return nil, nil
+	}
+	spec := &treeSpec{Name: cfg.treeName}
+	if cfg.treeParams != nil {
+		params, err := json.Marshal(cfg.treeParams)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling parameters of tree %q: %w", cfg.treeName, err)
+		}
+		spec.Params = params
+	}
+	return spec, nil
+}
+
+// treeConstructor returns the tree constructor created by the factory
+// registered under the name of spec.
+func (spec *treeSpec) treeConstructor() (TreeConstructorFn, error) {
+	factory, ok := GetTree(spec.Name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrTreeNotRegistered, spec.Name)
+	}
+	treeFn, err := factory(spec.Params)
+	if err != nil {
+		return nil, fmt.Errorf("tree %q: %w", spec.Name, err)
+	}
+	return treeFn, nil
+}
@@ -0,0 +1,64 @@
+package rsmt2d
+
+// ShareArray is a type constraint for fixed-size share types, e.g.
+//
+//	type Share512 [512]byte
+//
+//	func (s *Share512) Bytes() []byte { return s[:] }
+//
+// Using a fixed-size array as the share type guarantees at compile time that
+// every share has the same size, which rules out ErrUnevenChunks and similar
+// runtime length-check errors.
+type ShareArray[T any] interface {
+	*T
+	// Bytes returns the contents of the share. The returned slice must alias
+	// the share and must have the same length for every value of T.
+	Bytes() []byte
+}
+
+// ComputeExtendedDataSquareOf is a typed variant of ComputeExtendedDataSquare
+// that accepts the original data as fixed-size shares.
+func ComputeExtendedDataSquareOf[T any, P ShareArray[T]](
+	data []T,
+	codec Codec,
+	treeCreatorFn TreeConstructorFn,
+) (*ExtendedDataSquare, error) {
+	shares := make([][]byte, len(data))
+	for i := range data {
+		shares[i] = P(&data[i]).Bytes()
+	}
+	return ComputeExtendedDataSquare(shares, codec, treeCreatorFn)
+}
+
+// ImportExtendedDataSquareOf is a typed variant of ImportExtendedDataSquare.
+// Missing shares must be nil.
+func ImportExtendedDataSquareOf[T any, P ShareArray[T]](
+	data []*T,
+	codec Codec,
+	treeCreatorFn TreeConstructorFn,
+) (*ExtendedDataSquare, error) {
+	shares := make([][]byte, len(data))
+	for i, d := range data {
+		if d != nil {
+			shares[i] = P(d).Bytes()
+		}
+	}
+	return ImportExtendedDataSquareNoCopy(shares, codec, treeCreatorFn)
+}
+
+// GetCellOf returns a copy of a specific cell as a fixed-size share. Returns
+// false if the cell is missing or its size does not match the size of T. Like
+// GetCell, it can be called concurrently with SetCell.
+func GetCellOf[T any, P ShareArray[T]](eds *ExtendedDataSquare, rowIdx uint, colIdx uint) (T, bool) {
+	var share T
+	eds.materializeFor(rowIdx, colIdx)
+	eds.dataMutex.RLock()
+	defer eds.dataMutex.RUnlock()
+	cell := eds.squareRow[rowIdx][colIdx]
+	dst := P(&share).Bytes()
+	if cell == nil || len(cell) != len(dst) {
+		return share, false
+	}
+	copy(dst, cell)
+	return share, true
+}
@@ -0,0 +1,63 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testShare [shareSize]byte
+
+func (s *testShare) Bytes() []byte { return s[:] }
+
+type smallTestShare [shareSize / 2]byte
+
+func (s *smallTestShare) Bytes() []byte { return s[:] }
+
+func TestComputeExtendedDataSquareOf(t *testing.T) {
+	data := make([]testShare, 4)
+	for i := range data {
+		copy(data[i][:], bytes.Repeat([]byte{byte(i + 1)}, shareSize))
+	}
+
+	eds, err := ComputeExtendedDataSquareOf(data, NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+
+	want := createExampleEds(t, shareSize)
+	assert.True(t, eds.Equals(want))
+
+	share, ok := GetCellOf[testShare](eds, 1, 1)
+	require.True(t, ok)
+	assert.Equal(t, data[3], share)
+
+	_, ok = GetCellOf[smallTestShare](eds, 1, 1)
+	assert.False(t, ok)
+}
+
+func TestImportExtendedDataSquareOf(t *testing.T) {
+	want := createExampleEds(t, shareSize)
+
+	data := make([]*testShare, want.Width()*want.Width())
+	for i, cell := range want.Flattened() {
+		if i%3 == 0 {
+			continue
+		}
+		data[i] = new(testShare)
+		copy(data[i][:], cell)
+	}
+
+	eds, err := ImportExtendedDataSquareOf(data, NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+
+	_, ok := GetCellOf[testShare](eds, 0, 0)
+	assert.False(t, ok)
+
+	rowRoots, err := want.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := want.ColRoots()
+	require.NoError(t, err)
+	require.NoError(t, eds.Repair(rowRoots, colRoots))
+	assert.True(t, eds.Equals(want))
+}
@@ -1,5 +1,7 @@
 package rsmt2d
 
+import "sync"
+
 func flattenShares(shares [][]byte) []byte {
 	length := 0
 	for _, share := range shares {
@@ -13,3 +15,42 @@ func flattenShares(shares [][]byte) []byte {
 
 	return flattened
 }
+
+// allocShares returns count shares of shareSize bytes each, backed by a single
+// contiguous allocation.
+func allocShares(count int, shareSize int) [][]byte {
+	buf := make([]byte, count*shareSize)
+	shares := make([][]byte, count)
+	for i := range shares {
+		shares[i] = buf[i*shareSize : (i+1)*shareSize : (i+1)*shareSize]
+	}
+	return shares
+}
+
+// scratchShares is a reusable buffer of shares, see scratchSharesPool.
+type scratchShares struct {
+	buf    []byte
+	shares [][]byte
+}
+
+// scratchSharesPool pools scratch shares to avoid allocating temporary shares
+// on hot paths such as VerifyAxisEncoding.
+var scratchSharesPool = sync.Pool{
+	New: func() any { return new(scratchShares) },
+}
+
+// get returns count shares of shareSize bytes each, backed by the buffer of s
+// which is grown if needed. The content of the returned shares is undefined.
+func (s *scratchShares) get(count int, shareSize int) [][]byte {
+	if cap(s.buf) < count*shareSize {
+		s.buf = make([]byte, count*shareSize)
+	}
+	if cap(s.shares) < count {
+		s.shares = make([][]byte, count)
+	}
+	shares := s.shares[:count]
+	for i := range shares {
+		shares[i] = s.buf[i*shareSize : (i+1)*shareSize : (i+1)*shareSize]
+	}
+	return shares
+}